@@ -24,6 +24,8 @@ type acceptRunOutput struct {
 	AcceptResult     v1.AcceptanceResult  `json:"accept_result"`
 	Checks           []checks.CheckResult `json:"checks"`
 	JUnitPath        string               `json:"junit_path,omitempty"`
+	SARIFPath        string               `json:"sarif_path,omitempty"`
+	JSONLPath        string               `json:"jsonl_path,omitempty"`
 	JobpackPath      string               `json:"jobpack_path,omitempty"`
 	SummaryJSONPath  string               `json:"summary_json_path,omitempty"`
 	SummaryMDPath    string               `json:"summary_markdown_path,omitempty"`
@@ -95,7 +97,7 @@ func runAcceptInit(args []string, jsonMode bool, stdout, stderr io.Writer, now f
 func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
 	if len(args) < 1 {
 		return printError(
-			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr accept run <job_id> [--ci] [--junit <path>] [--config <path>] [--out-dir <dir>]", nil),
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr accept run <job_id> [--ci] [--junit <path>] [--sarif <path>] [--jsonl <path>] [--config <path>] [--waivers <path>] [--out-dir <dir>]", nil),
 			jsonMode,
 			stderr,
 			now,
@@ -104,8 +106,11 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 
 	jobID := args[0]
 	configPath := ""
+	waiversPath := ""
 	outDir := ""
 	junitPath := ""
+	sarifPath := ""
+	jsonlPath := ""
 	ciMode := false
 
 	for i := 1; i < len(args); i++ {
@@ -118,6 +123,12 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--config requires value", nil), jsonMode, stderr, now)
 			}
 			configPath = args[i]
+		case "--waivers":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--waivers requires value", nil), jsonMode, stderr, now)
+			}
+			waiversPath = args[i]
 		case "--out-dir":
 			i++
 			if i >= len(args) {
@@ -130,6 +141,18 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--junit requires value", nil), jsonMode, stderr, now)
 			}
 			junitPath = args[i]
+		case "--sarif":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--sarif requires value", nil), jsonMode, stderr, now)
+			}
+			sarifPath = args[i]
+		case "--jsonl":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--jsonl requires value", nil), jsonMode, stderr, now)
+			}
+			jsonlPath = args[i]
 		default:
 			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown accept run flag", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
 		}
@@ -139,6 +162,7 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 		Now:             now,
 		ProducerVersion: version,
 		ConfigPath:      configPath,
+		WaiversPath:     waiversPath,
 		WorkDir:         ".",
 	})
 	if err != nil {
@@ -158,6 +182,18 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 			return printError(err, jsonMode, stderr, now)
 		}
 	}
+	if sarifPath != "" {
+		sarifPath = filepath.Clean(sarifPath)
+		if err := acceptreport.WriteSARIF(sarifPath, version, runResult.CheckResult); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+	}
+	if jsonlPath != "" {
+		jsonlPath = filepath.Clean(jsonlPath)
+		if err := acceptreport.WriteJSONL(jsonlPath, jobID, runResult.CheckResult); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+	}
 
 	output := acceptRunOutput{
 		JobID:            jobID,
@@ -166,6 +202,8 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 		AcceptResult:     runResult.Result,
 		Checks:           runResult.CheckResult,
 		JUnitPath:        junitPath,
+		SARIFPath:        sarifPath,
+		JSONLPath:        jsonlPath,
 	}
 
 	if ciMode {
@@ -206,11 +244,22 @@ func runAcceptRun(args []string, jsonMode bool, stdout, stderr io.Writer, now fu
 	if output.JUnitPath != "" {
 		fmt.Fprintf(stdout, "junit=%s\n", output.JUnitPath)
 	}
+	if output.SARIFPath != "" {
+		fmt.Fprintf(stdout, "sarif=%s\n", output.SARIFPath)
+	}
+	if output.JSONLPath != "" {
+		fmt.Fprintf(stdout, "jsonl=%s\n", output.JSONLPath)
+	}
 	if output.SummaryMDPath != "" {
 		fmt.Fprintf(stdout, "summary=%s\n", output.SummaryMDPath)
 	}
 	for _, failure := range output.AcceptResult.Failures {
 		fmt.Fprintf(stdout, "failure check=%s message=%s\n", failure.Check, boundedSummary(failure.Message))
 	}
+	for _, check := range output.Checks {
+		if check.Waived() {
+			fmt.Fprintf(stdout, "waived check=%s reason=%s\n", check.Name, boundedSummary(check.Waivers[0].Reason))
+		}
+	}
 	return exitCode
 }
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/dispatch"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func runApprovals(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) == 0 {
+		return printError(
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr approvals pending | wrkr approvals expire [--template github|jira] [--out-dir <dir>]", nil),
+			jsonMode,
+			stderr,
+			now,
+		)
+	}
+	switch args[0] {
+	case "pending":
+		return runApprovalsPending(args[1:], jsonMode, stdout, stderr, now)
+	case "expire":
+		return runApprovalsExpire(args[1:], jsonMode, stdout, stderr, now)
+	default:
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown approvals subcommand", map[string]any{"command": args[0]}), jsonMode, stderr, now)
+	}
+}
+
+func runApprovalsPending(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) > 0 {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown approvals pending flag", map[string]any{"flag": args[0]}), jsonMode, stderr, now)
+	}
+	pending, err := dispatch.PendingDecisions(now)
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"pending": pending}); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+
+	if len(pending) == 0 {
+		_, _ = io.WriteString(stdout, "no pending decisions\n")
+		return 0
+	}
+	for _, item := range pending {
+		line := fmt.Sprintf("job_id=%s checkpoint=%s age=%s approvals=%d/%d", item.JobID, item.CheckpointID, time.Duration(item.AgeSeconds)*time.Second, item.Approvals, item.Quorum)
+		if item.Deadline != nil {
+			line += " deadline=" + item.Deadline.Format(time.RFC3339)
+		}
+		if item.Expired {
+			line += " expired"
+		}
+		if item.EscalateTo != "" {
+			line += " escalate_to=" + item.EscalateTo
+		}
+		fmt.Fprintf(stdout, "%s summary=%q\n", line, item.Summary)
+	}
+	return 0
+}
+
+func runApprovalsExpire(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	outDir := ""
+	template := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--template":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--template requires value", nil), jsonMode, stderr, now)
+			}
+			template = args[i]
+		case "--out-dir":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--out-dir requires value", nil), jsonMode, stderr, now)
+			}
+			outDir = args[i]
+		default:
+			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown approvals expire flag", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
+		}
+	}
+
+	expired, err := dispatch.ExpireDecisions(dispatch.ExpireOptions{
+		Now:             now,
+		OutDir:          outDir,
+		Template:        template,
+		ProducerVersion: version,
+	})
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"expired": expired}); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+
+	if len(expired) == 0 {
+		_, _ = io.WriteString(stdout, "no expired decisions\n")
+		return 0
+	}
+	for _, item := range expired {
+		parts := []string{
+			"job_id=" + item.JobID,
+			"checkpoint=" + item.CheckpointID,
+			"status=" + string(item.Status),
+			"reason=" + item.ReasonCode,
+		}
+		if item.EscalationID != "" {
+			parts = append(parts, "escalation="+item.EscalationID)
+		}
+		if item.EscalateTo != "" {
+			parts = append(parts, "escalate_to="+item.EscalateTo)
+		}
+		if item.WorkItemPath != "" {
+			parts = append(parts, "work_item="+item.WorkItemPath)
+		}
+		_, _ = io.WriteString(stdout, strings.Join(parts, " ")+"\n")
+	}
+	return 0
+}
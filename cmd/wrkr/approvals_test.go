@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+func TestApprovalsPendingAndExpireCommands(t *testing.T) {
+	_, now := setupCLIWorkspace(t)
+	clock := func() time.Time { return now }
+	r := setupCLIJob(t, now, "job_cli_expire", queue.StatusRunning)
+	if _, err := r.ChangeStatus("job_cli_expire", queue.StatusBlockedDecision); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{
+		"--json", "checkpoint", "emit", "job_cli_expire",
+		"--type", "decision-needed", "--summary", "approve release",
+		"--required-kind", "release", "--deadline", "1h", "--escalate-to", "release-lead",
+	}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("checkpoint emit failed: %d %s", code, errBuf.String())
+	}
+	var cp v1.Checkpoint
+	if err := json.Unmarshal(out.Bytes(), &cp); err != nil {
+		t.Fatalf("decode checkpoint: %v", err)
+	}
+	if cp.RequiredAction == nil || cp.RequiredAction.Deadline == nil || !cp.RequiredAction.Deadline.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected deadline on checkpoint, got %+v", cp.RequiredAction)
+	}
+
+	now = now.Add(2 * time.Hour)
+	out.Reset()
+	if code := run([]string{"approvals", "pending"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("approvals pending failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "job_id=job_cli_expire checkpoint="+cp.CheckpointID+" age=2h0m0s") || !strings.Contains(out.String(), " expired") {
+		t.Fatalf("unexpected pending output: %s", out.String())
+	}
+	if code := run([]string{"approve", "job_cli_expire", "--checkpoint", cp.CheckpointID, "--reason", "late", "--approved-by", "lead"}, &out, &errBuf, clock); code == 0 {
+		t.Fatal("expected approval after deadline to fail")
+	}
+
+	out.Reset()
+	if code := run([]string{"approvals", "expire"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("approvals expire failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "reason=approval_escalated") || !strings.Contains(out.String(), "escalate_to=release-lead") {
+		t.Fatalf("unexpected expire output: %s", out.String())
+	}
+	out.Reset()
+	if code := run([]string{"approvals", "expire"}, &out, &errBuf, clock); code != 0 || !strings.Contains(out.String(), "no expired decisions") {
+		t.Fatalf("expected second sweep to be a no-op: %d %s", code, out.String())
+	}
+}
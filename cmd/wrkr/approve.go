@@ -2,11 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/davidahmann/wrkr/core/approve"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 )
 
 func runApprove(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
@@ -79,6 +81,14 @@ func runApprove(args []string, jsonMode bool, stdout, stderr io.Writer, now func
 		return 0
 	}
 
-	_, _ = io.WriteString(stdout, "approved checkpoint="+record.CheckpointID+"\n")
+	_, _ = io.WriteString(stdout, "approved checkpoint="+record.CheckpointID+quorumSuffix(record)+"\n")
 	return 0
 }
+
+// quorumSuffix reports progress toward an approval rule quorum.
+func quorumSuffix(record *v1.ApprovalRecord) string {
+	if record.Rule == "" {
+		return ""
+	}
+	return fmt.Sprintf(" rule=%s approvals=%d/%d", record.Rule, record.Approvals, record.Quorum)
+}
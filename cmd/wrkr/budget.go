@@ -2,18 +2,35 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/davidahmann/wrkr/core/approve"
 	"github.com/davidahmann/wrkr/core/budget"
+	"github.com/davidahmann/wrkr/core/budgetpool"
+	"github.com/davidahmann/wrkr/core/dispatch"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 )
 
+const (
+	budgetCheckUsage = "usage: wrkr budget check <job_id> [limits...]"
+	budgetRaiseUsage = "usage: wrkr budget raise <job_id> [limits...] --reason <text> [--approved-by <user>]"
+	budgetPoolsUsage = "usage: wrkr budget pools"
+)
+
 func runBudget(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) > 0 && args[0] == "raise" {
+		return runBudgetRaise(args[1:], jsonMode, stdout, stderr, now)
+	}
+	if len(args) > 0 && args[0] == "pools" {
+		return runBudgetPools(args[1:], jsonMode, stdout, stderr, now)
+	}
 	if len(args) == 0 || args[0] != "check" {
 		return printError(
-			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr budget check <job_id> [limits...]", nil),
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, budgetCheckUsage, nil),
 			jsonMode,
 			stderr,
 			now,
@@ -21,7 +38,7 @@ func runBudget(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	}
 	if len(args) < 2 {
 		return printError(
-			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr budget check <job_id> [limits...]", nil),
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, budgetCheckUsage, nil),
 			jsonMode,
 			stderr,
 			now,
@@ -32,36 +49,11 @@ func runBudget(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	limits := budget.Limits{}
 
 	for i := 2; i < len(args); i++ {
-		switch args[i] {
-		case "--max-wall-time-seconds":
-			i++
-			v, err := parseIntFlag(args, i, "--max-wall-time-seconds")
-			if err != nil {
-				return printError(err, jsonMode, stderr, now)
-			}
-			limits.MaxWallTimeSeconds = v
-		case "--max-retries":
-			i++
-			v, err := parseIntFlag(args, i, "--max-retries")
-			if err != nil {
-				return printError(err, jsonMode, stderr, now)
-			}
-			limits.MaxRetries = v
-		case "--max-step-count":
-			i++
-			v, err := parseIntFlag(args, i, "--max-step-count")
-			if err != nil {
-				return printError(err, jsonMode, stderr, now)
-			}
-			limits.MaxStepCount = v
-		case "--max-tool-calls":
-			i++
-			v, err := parseIntFlag(args, i, "--max-tool-calls")
-			if err != nil {
-				return printError(err, jsonMode, stderr, now)
-			}
-			limits.MaxToolCalls = v
-		default:
+		next, ok, err := parseLimitFlag(args, i, &limits)
+		if err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		if !ok {
 			return printError(
 				wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown budget flag", map[string]any{"flag": args[i]}),
 				jsonMode,
@@ -69,6 +61,7 @@ func runBudget(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 				now,
 			)
 		}
+		i = next
 	}
 
 	r, s, err := openRunner(now)
@@ -107,6 +100,168 @@ func runBudget(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	return 0
 }
 
+func runBudgetRaise(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) < 1 {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, budgetRaiseUsage, nil), jsonMode, stderr, now)
+	}
+	jobID := args[0]
+	limits := budget.Limits{}
+	var reason, approvedBy string
+
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--reason":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--reason requires value", nil), jsonMode, stderr, now)
+			}
+			reason = args[i]
+		case "--approved-by":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--approved-by requires value", nil), jsonMode, stderr, now)
+			}
+			approvedBy = args[i]
+		default:
+			next, ok, err := parseLimitFlag(args, i, &limits)
+			if err != nil {
+				return printError(err, jsonMode, stderr, now)
+			}
+			if !ok {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown flag for budget raise", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
+			}
+			i = next
+		}
+	}
+	if err := approve.ValidateReason(reason); err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	result, err := dispatch.RaiseBudget(jobID, dispatch.RaiseBudgetOptions{
+		Now:        now,
+		Limits:     limits,
+		Reason:     reason,
+		ApprovedBy: approve.ResolveApprovedBy(approvedBy),
+	})
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+	changes := make([]string, 0, len(result.Amendment.Changes))
+	for _, change := range result.Amendment.Changes {
+		changes = append(changes, fmt.Sprintf("%s=%g->%g", change.Dimension, change.From, change.To))
+	}
+	_, _ = fmt.Fprintf(stdout, "budget raised job_id=%s %s status=%s\n", result.JobID, strings.Join(changes, " "), result.Status)
+	return 0
+}
+
+func runBudgetPools(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) > 0 {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, budgetPoolsUsage, nil), jsonMode, stderr, now)
+	}
+	r, s, err := openRunner(now)
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+	cfg, err := budgetpool.Load(s.Root())
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+	headrooms := make([]budgetpool.Headroom, 0, len(cfg.Pools))
+	for _, pool := range cfg.Pools {
+		usage, err := r.PoolUsage(pool)
+		if err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		headrooms = append(headrooms, budgetpool.Evaluate(pool, usage))
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"config": cfg.Path, "pools": headrooms}); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+	if len(headrooms) == 0 {
+		_, _ = fmt.Fprintf(stdout, "no budget pools configured in %s\n", cfg.Path)
+		return 0
+	}
+	for _, h := range headrooms {
+		line := fmt.Sprintf("pool=%s window=%s jobs=%d", h.Pool, h.Window, len(h.Usage.Jobs))
+		if h.Cost != nil {
+			line += fmt.Sprintf(" remaining_estimated_cost=%.4f", *h.Cost)
+		}
+		if h.Tokens != nil {
+			line += fmt.Sprintf(" remaining_tokens=%d", *h.Tokens)
+		}
+		if h.WallTime != nil {
+			line += fmt.Sprintf(" remaining_wall_time_seconds=%d", *h.WallTime)
+		}
+		line += fmt.Sprintf(" exhausted=%t resets_at=%s", h.Exhausted, h.Usage.WindowEnd.Format(time.RFC3339))
+		_, _ = fmt.Fprintln(stdout, line)
+	}
+	return 0
+}
+
+// parseLimitFlag parses the budget limit flag at args[i] into limits. It
+// returns the index of the last consumed argument and false when the flag is
+// not a limit flag.
+func parseLimitFlag(args []string, i int, limits *budget.Limits) (int, bool, error) {
+	flag := args[i]
+	i++
+	switch flag {
+	case "--max-wall-time-seconds":
+		v, err := parseIntFlag(args, i, flag)
+		if err != nil {
+			return i, true, err
+		}
+		limits.MaxWallTimeSeconds = v
+	case "--max-retries":
+		v, err := parseIntFlag(args, i, flag)
+		if err != nil {
+			return i, true, err
+		}
+		limits.MaxRetries = v
+	case "--max-step-count":
+		v, err := parseIntFlag(args, i, flag)
+		if err != nil {
+			return i, true, err
+		}
+		limits.MaxStepCount = v
+	case "--max-tool-calls":
+		v, err := parseIntFlag(args, i, flag)
+		if err != nil {
+			return i, true, err
+		}
+		limits.MaxToolCalls = v
+	case "--max-tokens":
+		v, err := parseIntFlag(args, i, flag)
+		if err != nil {
+			return i, true, err
+		}
+		limits.MaxTokens = &v
+	case "--max-estimated-cost":
+		v, err := parseFloatFlag(args, i, flag)
+		if err != nil {
+			return i, true, err
+		}
+		limits.MaxEstimatedCost = &v
+	default:
+		return i - 1, false, nil
+	}
+	return i, true, nil
+}
+
 func parseIntFlag(args []string, idx int, flag string) (int, error) {
 	if idx >= len(args) {
 		return 0, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, flag+" requires value", nil)
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	"github.com/davidahmann/wrkr/core/budgetpool"
+	"github.com/davidahmann/wrkr/core/dispatch"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+func TestBudgetRaiseUpdatesRuntimeConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 15, 9, 30, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: clock})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob("job_cli_raise"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_cli_raise", queue.StatusRunning); err != nil {
+		t.Fatalf("running: %v", err)
+	}
+	maxCost := 1.0
+	if err := dispatch.SaveRuntimeConfig(s, "job_cli_raise", dispatch.RuntimeConfig{
+		Adapter: "reference",
+		Budgets: budget.Limits{MaxToolCalls: 10, MaxEstimatedCost: &maxCost},
+	}, now); err != nil {
+		t.Fatalf("SaveRuntimeConfig: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"budget", "raise", "job_cli_raise", "--max-tool-calls", "20"}, &out, &errBuf, clock); code != 6 {
+		t.Fatalf("expected missing reason to fail, got %d %s", code, errBuf.String())
+	}
+
+	errBuf.Reset()
+	code := run([]string{"budget", "raise", "job_cli_raise", "--max-tool-calls", "20", "--max-estimated-cost", "2.5", "--reason", "bigger diff", "--approved-by", "lead"}, &out, &errBuf, clock)
+	if code != 0 {
+		t.Fatalf("budget raise failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "tool_call_count=10->20") || !strings.Contains(out.String(), "estimated_cost=1->2.5") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	cfg, err := dispatch.LoadRuntimeConfig(s, "job_cli_raise")
+	if err != nil {
+		t.Fatalf("LoadRuntimeConfig: %v", err)
+	}
+	if cfg.Budgets.MaxToolCalls != 20 || cfg.Budgets.MaxEstimatedCost == nil || *cfg.Budgets.MaxEstimatedCost != 2.5 {
+		t.Fatalf("expected raised limits in runtime config, got %+v", cfg.Budgets)
+	}
+}
+
+func TestBudgetPoolsShowsHeadroom(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 15, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"budget", "pools"}, &out, &errBuf, clock); code != 0 || !strings.Contains(out.String(), "no budget pools configured") {
+		t.Fatalf("expected empty pool listing, got %d %s %s", code, out.String(), errBuf.String())
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Root(), budgetpool.ConfigFile), []byte("pools:\n  - name: team-a\n    window: day\n    max_estimated_cost: 2\n"), 0o600); err != nil {
+		t.Fatalf("write pools: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: clock})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob("job_cli_pool"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := r.RecordBudgetPool("job_cli_pool", "team-a"); err != nil {
+		t.Fatalf("RecordBudgetPool: %v", err)
+	}
+	if _, err := r.RecordUsage("job_cli_pool", runner.Usage{EstimatedCost: 0.5}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+
+	out.Reset()
+	code := run([]string{"budget", "pools"}, &out, &errBuf, clock)
+	if code != 0 {
+		t.Fatalf("budget pools failed: %d %s", code, errBuf.String())
+	}
+	want := "pool=team-a window=day jobs=1 remaining_estimated_cost=1.5000 exhausted=false resets_at=2026-02-16T00:00:00Z"
+	if strings.TrimSpace(out.String()) != want {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -122,6 +124,24 @@ func runCheckpointShow(args []string, jsonMode bool, stdout, stderr io.Writer, n
 	if len(cp.ReasonCodes) > 0 {
 		fmt.Fprintf(stdout, "reason_codes=%s\n", strings.Join(cp.ReasonCodes, ","))
 	}
+	for _, attachment := range cp.Attachments {
+		line := fmt.Sprintf("attachment=%s media_type=%s size=%d sha256=%s", attachment.Name, attachment.MediaType, attachment.Size, attachment.SHA256)
+		if attachment.Redacted {
+			line += " redacted"
+		}
+		_, _ = io.WriteString(stdout, line+"\n")
+		if !runner.IsTextAttachment(attachment) {
+			continue
+		}
+		data, err := r.ReadAttachment(jobID, attachment)
+		if err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		_, _ = stdout.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			_, _ = io.WriteString(stdout, "\n")
+		}
+	}
 	return 0
 }
 
@@ -147,6 +167,11 @@ func runCheckpointEmit(args []string, jsonMode bool, stdout, stderr io.Writer, n
 		requiredKind         string
 		requiredInstructions string
 		reasonCodes          []string
+		options              []v1.DecisionOption
+		deadline             time.Duration
+		escalateTo           string
+		cancelOnExpiry       bool
+		attachPaths          []string
 	)
 
 	for i := 1; i < len(args); i++ {
@@ -187,6 +212,36 @@ func runCheckpointEmit(args []string, jsonMode bool, stdout, stderr io.Writer, n
 				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--reason-code requires a value", nil), jsonMode, stderr, now)
 			}
 			reasonCodes = append(reasonCodes, strings.TrimSpace(args[i]))
+		case "--option":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--option requires a value", nil), jsonMode, stderr, now)
+			}
+			options = append(options, v1.DecisionOption{Name: strings.TrimSpace(args[i])})
+		case "--deadline":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--deadline requires a value", nil), jsonMode, stderr, now)
+			}
+			parsed, err := time.ParseDuration(args[i])
+			if err != nil || parsed <= 0 {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--deadline must be a positive duration", map[string]any{"value": args[i]}), jsonMode, stderr, now)
+			}
+			deadline = parsed
+		case "--escalate-to":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--escalate-to requires a value", nil), jsonMode, stderr, now)
+			}
+			escalateTo = strings.TrimSpace(args[i])
+		case "--cancel-on-expiry":
+			cancelOnExpiry = true
+		case "--attach":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--attach requires a value", nil), jsonMode, stderr, now)
+			}
+			attachPaths = append(attachPaths, args[i])
 		default:
 			return printError(
 				wrkrerrors.New(
@@ -210,11 +265,29 @@ func runCheckpointEmit(args []string, jsonMode bool, stdout, stderr io.Writer, n
 	}
 
 	var required *v1.RequiredAction
-	if strings.TrimSpace(requiredKind) != "" || strings.TrimSpace(requiredInstructions) != "" {
+	if strings.TrimSpace(requiredKind) != "" || strings.TrimSpace(requiredInstructions) != "" || len(options) > 0 ||
+		deadline > 0 || escalateTo != "" || cancelOnExpiry {
 		required = &v1.RequiredAction{
-			Kind:         strings.TrimSpace(requiredKind),
-			Instructions: strings.TrimSpace(requiredInstructions),
+			Kind:           strings.TrimSpace(requiredKind),
+			Instructions:   strings.TrimSpace(requiredInstructions),
+			Options:        options,
+			EscalateTo:     escalateTo,
+			CancelOnExpiry: cancelOnExpiry,
+		}
+		if deadline > 0 {
+			at := now().UTC().Add(deadline)
+			required.Deadline = &at
+		}
+	}
+
+	attachments := make([]runner.AttachmentInput, 0, len(attachPaths))
+	for _, path := range attachPaths {
+		// #nosec G304 -- attachment paths are explicit user CLI input.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "attachment file is unreadable", map[string]any{"path": path, "error": err.Error()}), jsonMode, stderr, now)
 		}
+		attachments = append(attachments, runner.AttachmentInput{Name: filepath.Base(path), Data: data})
 	}
 
 	var status queue.Status
@@ -228,6 +301,7 @@ func runCheckpointEmit(args []string, jsonMode bool, stdout, stderr io.Writer, n
 		Status:         status,
 		RequiredAction: required,
 		ReasonCodes:    reasonCodes,
+		Attachments:    attachments,
 	})
 	if err != nil {
 		return printError(err, jsonMode, stderr, now)
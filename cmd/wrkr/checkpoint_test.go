@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/queue"
+)
+
+func TestCheckpointEmitAttachAndShow(t *testing.T) {
+	_, now := setupCLIWorkspace(t)
+	clock := func() time.Time { return now }
+	setupCLIJob(t, now, "job_cli_attach", queue.StatusRunning)
+	if err := os.WriteFile("fix.diff", []byte("-old\n+new\n"), 0o600); err != nil {
+		t.Fatalf("write diff: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{
+		"checkpoint", "emit", "job_cli_attach", "--type", "progress", "--summary", "applied fix", "--attach", "fix.diff",
+	}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("checkpoint emit failed: %d %s", code, errBuf.String())
+	}
+	cpID := strings.Fields(strings.TrimPrefix(out.String(), "checkpoint="))[0]
+
+	out.Reset()
+	if code := run([]string{"checkpoint", "show", "job_cli_attach", cpID}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("checkpoint show failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "attachment=fix.diff media_type=text/x-diff size=10") || !strings.Contains(out.String(), "-old\n+new\n") {
+		t.Fatalf("unexpected show output: %s", out.String())
+	}
+
+	if code := run([]string{
+		"checkpoint", "emit", "job_cli_attach", "--type", "progress", "--summary", "x", "--attach", "missing.log",
+	}, &out, &errBuf, clock); code == 0 {
+		t.Fatal("expected missing attachment file to fail")
+	}
+}
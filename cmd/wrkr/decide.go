@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/approve"
+	"github.com/davidahmann/wrkr/core/dispatch"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func runDecide(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) < 1 {
+		return printError(
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr decide <job_id> --checkpoint <id> --option <name> --reason <text> [--approved-by <user>]", nil),
+			jsonMode,
+			stderr,
+			now,
+		)
+	}
+	jobID := args[0]
+
+	var checkpointID string
+	var option string
+	var reason string
+	var approvedBy string
+
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--checkpoint":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--checkpoint requires value", nil), jsonMode, stderr, now)
+			}
+			checkpointID = args[i]
+		case "--option":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--option requires value", nil), jsonMode, stderr, now)
+			}
+			option = args[i]
+		case "--reason":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--reason requires value", nil), jsonMode, stderr, now)
+			}
+			reason = args[i]
+		case "--approved-by":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--approved-by requires value", nil), jsonMode, stderr, now)
+			}
+			approvedBy = args[i]
+		default:
+			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown flag for decide", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
+		}
+	}
+
+	if checkpointID == "" {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "checkpoint id is required", nil), jsonMode, stderr, now)
+	}
+	if option == "" {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "option is required", nil), jsonMode, stderr, now)
+	}
+	if err := approve.ValidateReason(reason); err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	r, s, err := openRunner(now)
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+	if err := ensureJobExists(s, jobID); err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	record, err := r.DecideCheckpoint(jobID, checkpointID, option, reason, approve.ResolveApprovedBy(approvedBy))
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(record); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "decided checkpoint=%s option=%s%s\n", record.CheckpointID, record.Option, quorumSuffix(record))
+	return 0
+}
+
+func runReject(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) < 1 {
+		return printError(
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr reject <job_id> --checkpoint <id> --reason <text> [--rejected-by <user>]", nil),
+			jsonMode,
+			stderr,
+			now,
+		)
+	}
+	jobID := args[0]
+
+	var checkpointID string
+	var reason string
+	var rejectedBy string
+
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--checkpoint":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--checkpoint requires value", nil), jsonMode, stderr, now)
+			}
+			checkpointID = args[i]
+		case "--reason":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--reason requires value", nil), jsonMode, stderr, now)
+			}
+			reason = args[i]
+		case "--rejected-by":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--rejected-by requires value", nil), jsonMode, stderr, now)
+			}
+			rejectedBy = args[i]
+		default:
+			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown flag for reject", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
+		}
+	}
+
+	if checkpointID == "" {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "checkpoint id is required", nil), jsonMode, stderr, now)
+	}
+
+	result, err := dispatch.Reject(jobID, dispatch.RejectOptions{
+		Now:          now,
+		CheckpointID: checkpointID,
+		Reason:       reason,
+		RejectedBy:   approve.ResolveApprovedBy(rejectedBy),
+	})
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+
+	line := fmt.Sprintf("rejected checkpoint=%s status=%s", result.Rejection.CheckpointID, result.Status)
+	if result.Rejection.RouteStep != "" {
+		line += " route_step=" + result.Rejection.RouteStep
+	}
+	_, _ = io.WriteString(stdout, line+"\n")
+	return 0
+}
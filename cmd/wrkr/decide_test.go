@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+func TestDecideAndRejectCommands(t *testing.T) {
+	_, now := setupCLIWorkspace(t)
+	clock := func() time.Time { return now }
+	r := setupCLIJob(t, now, "job_cli_decide", queue.StatusRunning)
+	setupCLIJob(t, now, "job_cli_reject", queue.StatusRunning)
+
+	emit := func(jobID string) string {
+		t.Helper()
+		var out, errBuf bytes.Buffer
+		code := run([]string{
+			"--json", "checkpoint", "emit", jobID,
+			"--type", "decision-needed", "--summary", "pick a rollout",
+			"--required-kind", "rollout", "--option", "canary", "--option", "full",
+		}, &out, &errBuf, clock)
+		if code != 0 {
+			t.Fatalf("checkpoint emit failed: %d %s", code, errBuf.String())
+		}
+		var cp v1.Checkpoint
+		if err := json.Unmarshal(out.Bytes(), &cp); err != nil {
+			t.Fatalf("decode checkpoint: %v", err)
+		}
+		return cp.CheckpointID
+	}
+
+	decideID := emit("job_cli_decide")
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"decide", "job_cli_decide", "--checkpoint", decideID, "--reason", "r"}, &out, &errBuf, clock); code != 6 {
+		t.Fatalf("expected missing option to fail, got %d %s", code, errBuf.String())
+	}
+	if code := run([]string{"decide", "job_cli_decide", "--checkpoint", decideID, "--option", "canary", "--reason", "low risk", "--approved-by", "lead"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("decide failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "decided checkpoint="+decideID+" option=canary") {
+		t.Fatalf("unexpected decide output: %s", out.String())
+	}
+	decision, err := r.Decision("job_cli_decide", decideID)
+	if err != nil || decision.Option != "canary" {
+		t.Fatalf("expected recorded option, got %+v err=%v", decision, err)
+	}
+
+	rejectID := emit("job_cli_reject")
+	out.Reset()
+	if code := run([]string{"reject", "job_cli_reject", "--checkpoint", rejectID, "--reason", "too risky", "--rejected-by", "lead"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("reject failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "rejected checkpoint="+rejectID+" status=canceled") {
+		t.Fatalf("unexpected reject output: %s", out.String())
+	}
+}
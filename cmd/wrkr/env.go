@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+const envDiffUsage = "usage: wrkr env diff <job_id>"
+
+func runEnv(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) != 2 || args[0] != "diff" {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, envDiffUsage, nil), jsonMode, stderr, now)
+	}
+	jobID := args[1]
+
+	r, s, err := openRunner(now)
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+	if err := ensureJobExists(s, jobID); err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	diff, err := r.EnvDiff(jobID)
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "job_id=%s match=%t blocking=%t expected_hash=%s actual_hash=%s\n",
+		diff.JobID, diff.Match, diff.Blocking, diff.ExpectedHash, diff.ActualHash)
+	for _, change := range diff.Changes {
+		line := fmt.Sprintf("rule=%s expected=%q actual=%q", change.Rule, change.Expected, change.Actual)
+		if change.Advisory {
+			line += " advisory"
+		}
+		_, _ = io.WriteString(stdout, line+"\n")
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+func TestEnvDiffPreviewsChangedRules(t *testing.T) {
+	_, now := setupCLIWorkspace(t)
+	clock := func() time.Time { return now }
+	r := setupCLIJob(t, now, "job_cli_env", queue.StatusRunning)
+	if _, err := r.ChangeStatus("job_cli_env", queue.StatusPaused); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"env", "diff", "job_cli_env"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("env diff failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "match=true blocking=false") {
+		t.Fatalf("expected matching fingerprint, got %q", out.String())
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	if _, err := s.AppendEvent("job_cli_env", "env_fingerprint_set", map[string]any{
+		"rules":       []string{"os"},
+		"values":      map[string]string{"os": "bogus-os"},
+		"hash":        "deadbeef",
+		"captured_at": now,
+	}, now); err != nil {
+		t.Fatalf("inject fingerprint: %v", err)
+	}
+
+	out.Reset()
+	if code := run([]string{"env", "diff", "job_cli_env"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("env diff failed: %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `rule=os expected="bogus-os" actual="`+runtime.GOOS+`"`) {
+		t.Fatalf("expected os change, got %q", out.String())
+	}
+
+	out.Reset()
+	if code := run([]string{"--json", "env", "diff", "job_cli_env"}, &out, &errBuf, clock); code != 0 {
+		t.Fatalf("env diff --json failed: %d %s", code, errBuf.String())
+	}
+	var diff runner.EnvDiff
+	if err := json.Unmarshal(out.Bytes(), &diff); err != nil {
+		t.Fatalf("decode env diff: %v", err)
+	}
+	if !diff.Blocking || len(diff.Changes) != 1 || diff.Changes[0].Rule != "os" {
+		t.Fatalf("unexpected env diff: %+v", diff)
+	}
+
+	errBuf.Reset()
+	if code := run([]string{"--json", "resume", "job_cli_env"}, &out, &errBuf, clock); code == 0 {
+		t.Fatal("expected resume to block on env mismatch")
+	}
+	if !strings.Contains(errBuf.String(), `"rule": "os"`) {
+		t.Fatalf("expected changed rule in error details, got %s", errBuf.String())
+	}
+
+	if code := run([]string{"env", "show", "job_cli_env"}, &out, &errBuf, clock); code != 6 {
+		t.Fatalf("expected usage error for unknown env command, got %d", code)
+	}
+}
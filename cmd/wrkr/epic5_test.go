@@ -78,12 +78,14 @@ path_rules:
 
 	outDir := filepath.Join(workspace, "out")
 	junitPath := filepath.Join(workspace, "report", "accept.junit.xml")
+	sarifPath := filepath.Join(workspace, "report", "accept.sarif")
+	jsonlPath := filepath.Join(workspace, "report", "accept.jsonl")
 	stepSummary := filepath.Join(workspace, "gh", "step-summary.md")
 	t.Setenv("GITHUB_STEP_SUMMARY", stepSummary)
 
 	var out bytes.Buffer
 	var errBuf bytes.Buffer
-	code := run([]string{"accept", "run", "job_cli_accept", "--config", configPath, "--ci", "--junit", junitPath, "--out-dir", outDir, "--json"}, &out, &errBuf, func() time.Time { return now })
+	code := run([]string{"accept", "run", "job_cli_accept", "--config", configPath, "--ci", "--junit", junitPath, "--sarif", sarifPath, "--jsonl", jsonlPath, "--out-dir", outDir, "--json"}, &out, &errBuf, func() time.Time { return now })
 	if code != 0 {
 		t.Fatalf("accept run failed: %d %s", code, errBuf.String())
 	}
@@ -95,6 +97,12 @@ path_rules:
 	if _, err := os.Stat(junitPath); err != nil {
 		t.Fatalf("expected junit output: %v", err)
 	}
+	if raw, err := os.ReadFile(sarifPath); err != nil || !strings.Contains(string(raw), `"version": "2.1.0"`) {
+		t.Fatalf("expected sarif output: %v %s", err, raw)
+	}
+	if raw, err := os.ReadFile(jsonlPath); err != nil || strings.Count(string(raw), `"job_id":"job_cli_accept"`) != 6 {
+		t.Fatalf("expected one jsonl line per check: %v %s", err, raw)
+	}
 	if _, err := os.Stat(stepSummary); err != nil {
 		t.Fatalf("expected github step summary output: %v", err)
 	}
@@ -152,6 +160,27 @@ path_rules:
 	if !strings.Contains(out.String(), "E_ACCEPT_TEST_FAIL") {
 		t.Fatalf("expected E_ACCEPT_TEST_FAIL in output: %s", out.String())
 	}
+
+	waiversPath := filepath.Join(workspace, "waivers.yaml")
+	if err := os.WriteFile(waiversPath, []byte(`schema_id: wrkr.accept_waivers
+schema_version: v1
+waivers:
+  - check: test_command
+    reason: known flaky suite
+    approver: lead
+    expires: 2026-02-14
+`), 0o600); err != nil {
+		t.Fatalf("write waivers: %v", err)
+	}
+	out.Reset()
+	errBuf.Reset()
+	code = run([]string{"accept", "run", "job_cli_accept_fail", "--config", configPath, "--waivers", waiversPath}, &out, &errBuf, func() time.Time { return now })
+	if code != 0 {
+		t.Fatalf("expected waived failure to pass, got %d (stderr=%s)", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "waived check=test_command reason=known flaky suite") {
+		t.Fatalf("expected waived line in output: %s", out.String())
+	}
 }
 
 func setupEpic5Job(t *testing.T, jobID string, now time.Time) {
@@ -14,8 +14,14 @@ func runHelp(stdout io.Writer) int {
   checkpoint list|show|emit
   pause
   resume
+  env diff
   cancel
   approve
+  decide
+  reject
+  approvals pending|expire
+  budget check|raise|pools
+  usage record
   wrap -- <command...>
   export
   verify
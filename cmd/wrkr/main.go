@@ -93,12 +93,22 @@ func run(args []string, stdout, stderr io.Writer, now func() time.Time) int {
 		return runPause(filtered[1:], jsonMode, stdout, stderr, now)
 	case "approve":
 		return runApprove(filtered[1:], jsonMode, stdout, stderr, now)
+	case "decide":
+		return runDecide(filtered[1:], jsonMode, stdout, stderr, now)
+	case "reject":
+		return runReject(filtered[1:], jsonMode, stdout, stderr, now)
+	case "approvals":
+		return runApprovals(filtered[1:], jsonMode, stdout, stderr, now)
 	case "resume":
 		return runResume(filtered[1:], jsonMode, stdout, stderr, now)
+	case "env":
+		return runEnv(filtered[1:], jsonMode, stdout, stderr, now)
 	case "cancel":
 		return runCancel(filtered[1:], jsonMode, stdout, stderr, now)
 	case "budget":
 		return runBudget(filtered[1:], jsonMode, stdout, stderr, now)
+	case "usage":
+		return runUsage(filtered[1:], jsonMode, stdout, stderr, now)
 	case "wrap":
 		return runWrap(filtered[1:], jsonMode, stdout, stderr, now)
 	case "export":
@@ -201,12 +211,22 @@ func commandIntent(command string) (string, bool) {
 		return "pause a running job without losing durable state", true
 	case "approve":
 		return "record an approval for a decision-needed checkpoint", true
+	case "decide":
+		return "choose one of the options offered by a decision-needed checkpoint", true
+	case "reject":
+		return "reject a decision-needed checkpoint and cancel or reroute the job", true
+	case "approvals":
+		return "list pending decisions by age and escalate or cancel expired ones", true
 	case "resume":
 		return "resume a paused or blocked job from the last durable state", true
+	case "env":
+		return "compare a job's recorded environment fingerprint with the current environment rule by rule", true
 	case "cancel":
 		return "cancel a job and persist terminal status and reason codes", true
 	case "budget":
 		return "inspect or update deterministic budget controls and stop conditions", true
+	case "usage":
+		return "record token and estimated-cost usage reported by an external agent", true
 	case "wrap":
 		return "execute a command under wrkr durability and export job evidence", true
 	case "export":
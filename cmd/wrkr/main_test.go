@@ -161,9 +161,14 @@ func TestCommandIntentCoverage(t *testing.T) {
 		"checkpoint",
 		"pause",
 		"approve",
+		"decide",
+		"reject",
+		"approvals",
 		"resume",
+		"env",
 		"cancel",
 		"budget",
+		"usage",
 		"wrap",
 		"export",
 		"verify",
@@ -29,6 +29,9 @@ func runStatus(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	if err := ensureJobExists(s, jobID); err != nil {
 		return printError(err, jsonMode, stderr, now)
 	}
+	// Flag silent jobs even when no worker holds their lease. The warning is
+	// advisory, so a failed check does not fail the status read.
+	_, _ = r.CheckCheckpointInterval(jobID)
 	state, err := r.Recover(jobID)
 	if err != nil {
 		return printError(err, jsonMode, stderr, now)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/davidahmann/wrkr/core/queue"
 	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
@@ -64,3 +65,37 @@ func TestStatusMissingJobReturnsExit6(t *testing.T) {
 		t.Fatalf("expected invalid input envelope, got %q", errBuf.String())
 	}
 }
+
+func TestStatusFlagsSilentJobWithoutLease(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 10, 0, 0, 0, time.UTC)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob("job_silent"); err != nil {
+		t.Fatalf("init job: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_silent", queue.StatusRunning); err != nil {
+		t.Fatalf("change status: %v", err)
+	}
+	if _, err := r.RecordCheckpointPolicy("job_silent", v1.CheckpointPolicy{MinIntervalSeconds: 30}); err != nil {
+		t.Fatalf("record checkpoint policy: %v", err)
+	}
+
+	later := func() time.Time { return now.Add(time.Minute) }
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := run([]string{"status", "job_silent", "--json"}, &out, &errBuf, later); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), runner.ReasonCheckpointSilence) {
+		t.Fatalf("expected silence reason code in status, got %q", out.String())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/davidahmann/wrkr/core/approve"
 	"github.com/davidahmann/wrkr/core/dispatch"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 )
@@ -13,7 +14,7 @@ import (
 func runSubmit(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
 	if len(args) < 1 {
 		return printError(
-			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr submit <jobspec.yaml|json> [--job-id <id>]", nil),
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr submit <jobspec.yaml|json> [--job-id <id>] [--submitted-by <user>]", nil),
 			jsonMode,
 			stderr,
 			now,
@@ -21,6 +22,7 @@ func runSubmit(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	}
 	specPath := args[0]
 	jobID := ""
+	submittedBy := ""
 	for i := 1; i < len(args); i++ {
 		switch args[i] {
 		case "--job-id":
@@ -29,14 +31,21 @@ func runSubmit(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--job-id requires value", nil), jsonMode, stderr, now)
 			}
 			jobID = args[i]
+		case "--submitted-by":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--submitted-by requires value", nil), jsonMode, stderr, now)
+			}
+			submittedBy = args[i]
 		default:
 			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown submit flag", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
 		}
 	}
 
 	result, err := dispatch.Submit(specPath, dispatch.SubmitOptions{
-		Now:   now,
-		JobID: jobID,
+		Now:         now,
+		JobID:       jobID,
+		SubmittedBy: approve.ResolveSubmittedBy(submittedBy),
 	})
 	if err != nil {
 		return printError(err, jsonMode, stderr, now)
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/dispatch"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/runner"
+)
+
+const usageRecordUsage = "usage: wrkr usage record <job_id> [--tokens-in <n>] [--tokens-out <n>] [--model <name>] [--cost <usd>]"
+
+func runUsage(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
+	if len(args) < 2 || args[0] != "record" {
+		return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, usageRecordUsage, nil), jsonMode, stderr, now)
+	}
+	jobID := args[1]
+	usage := runner.Usage{}
+
+	for i := 2; i < len(args); i++ {
+		switch args[i] {
+		case "--tokens-in":
+			i++
+			v, err := parseIntFlag(args, i, "--tokens-in")
+			if err != nil {
+				return printError(err, jsonMode, stderr, now)
+			}
+			usage.TokensIn = v
+		case "--tokens-out":
+			i++
+			v, err := parseIntFlag(args, i, "--tokens-out")
+			if err != nil {
+				return printError(err, jsonMode, stderr, now)
+			}
+			usage.TokensOut = v
+		case "--model":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--model requires value", nil), jsonMode, stderr, now)
+			}
+			usage.Model = args[i]
+		case "--cost":
+			i++
+			v, err := parseFloatFlag(args, i, "--cost")
+			if err != nil {
+				return printError(err, jsonMode, stderr, now)
+			}
+			usage.EstimatedCost = v
+		default:
+			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown flag for usage record", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
+		}
+	}
+
+	result, err := dispatch.RecordUsage(jobID, usage, dispatch.UsageOptions{Now: now})
+	if err != nil && result.JobID == "" {
+		return printError(err, jsonMode, stderr, now)
+	}
+
+	if jsonMode {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(result); encErr != nil {
+			return printError(encErr, jsonMode, stderr, now)
+		}
+	} else {
+		_, _ = fmt.Fprintf(stdout, "usage recorded job_id=%s tokens_in=%d tokens_out=%d estimated_cost=%.4f status=%s\n",
+			result.JobID, result.TokensIn, result.TokensOut, result.EstimatedCost, result.Status)
+	}
+	if err != nil {
+		return printError(err, jsonMode, stderr, now)
+	}
+	return 0
+}
+
+func parseFloatFlag(args []string, idx int, flag string) (float64, error) {
+	if idx >= len(args) {
+		return 0, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, flag+" requires value", nil)
+	}
+	v, err := strconv.ParseFloat(args[idx], 64)
+	if err != nil {
+		return 0, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid number for "+flag, map[string]any{"value": args[idx]})
+	}
+	return v, nil
+}
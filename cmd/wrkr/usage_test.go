@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	"github.com/davidahmann/wrkr/core/dispatch"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+func TestUsageRecordTripsTokenBudget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 15, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: clock})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob("job_cli_usage"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_cli_usage", queue.StatusRunning); err != nil {
+		t.Fatalf("running: %v", err)
+	}
+	maxTokens := 100
+	if err := dispatch.SaveRuntimeConfig(s, "job_cli_usage", dispatch.RuntimeConfig{
+		Adapter: "reference",
+		Budgets: budget.Limits{MaxTokens: &maxTokens},
+	}, now); err != nil {
+		t.Fatalf("SaveRuntimeConfig: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	code := run([]string{"usage", "record", "job_cli_usage", "--tokens-in", "40", "--tokens-out", "20", "--model", "gpt-test", "--cost", "0.02", "--json"}, &out, &errBuf, clock)
+	if code != 0 {
+		t.Fatalf("usage record failed: %d %s", code, errBuf.String())
+	}
+	var result dispatch.UsageResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode usage result: %v", err)
+	}
+	if result.TokensIn != 40 || result.TokensOut != 20 || result.EstimatedCost != 0.02 || result.Status != queue.StatusRunning {
+		t.Fatalf("unexpected usage result: %+v", result)
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	code = run([]string{"usage", "record", "job_cli_usage", "--tokens-out", "50"}, &out, &errBuf, clock)
+	if code != 1 || !strings.Contains(errBuf.String(), "E_BUDGET_EXCEEDED") {
+		t.Fatalf("expected budget exceeded, got %d %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "tokens_out=70") || !strings.Contains(out.String(), "status=blocked_budget") {
+		t.Fatalf("unexpected text output: %s", out.String())
+	}
+
+	checkpoints, err := r.ListCheckpoints("job_cli_usage")
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	last := checkpoints[len(checkpoints)-1]
+	if last.BudgetState.TokensIn != 40 || last.BudgetState.TokensOut != 70 || last.BudgetState.EstimatedCost != 0.02 {
+		t.Fatalf("expected usage totals in budget_state, got %+v", last.BudgetState)
+	}
+
+	errBuf.Reset()
+	if code := run([]string{"usage", "record", "job_cli_usage", "--tokens-in", "-1"}, &out, &errBuf, clock); code != 6 {
+		t.Fatalf("expected negative usage to be rejected, got %d %s", code, errBuf.String())
+	}
+}
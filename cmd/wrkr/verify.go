@@ -13,7 +13,7 @@ import (
 func runVerify(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
 	if len(args) < 1 {
 		return printError(
-			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr verify <job_id|path> [--out-dir <dir>]", nil),
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr verify <job_id|path> [--out-dir <dir>] [--workspace <dir>]", nil),
 			jsonMode,
 			stderr,
 			now,
@@ -21,6 +21,7 @@ func runVerify(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	}
 	target := args[0]
 	outDir := ""
+	workspace := ""
 	for i := 1; i < len(args); i++ {
 		switch args[i] {
 		case "--out-dir":
@@ -29,6 +30,12 @@ func runVerify(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--out-dir requires value", nil), jsonMode, stderr, now)
 			}
 			outDir = args[i]
+		case "--workspace":
+			i++
+			if i >= len(args) {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--workspace requires value", nil), jsonMode, stderr, now)
+			}
+			workspace = args[i]
 		default:
 			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown verify flag", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
 		}
@@ -38,7 +45,12 @@ func runVerify(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 	if err != nil {
 		return printError(err, jsonMode, stderr, now)
 	}
-	result, err := pack.VerifyJobpack(path)
+	var result pack.VerifyResult
+	if workspace != "" {
+		result, err = pack.VerifyJobpackWorkspace(path, workspace)
+	} else {
+		result, err = pack.VerifyJobpack(path)
+	}
 	if err != nil {
 		return printError(err, jsonMode, stderr, now)
 	}
@@ -52,6 +64,14 @@ func runVerify(args []string, jsonMode bool, stdout, stderr io.Writer, now func(
 		return 0
 	}
 
+	if workspace != "" {
+		fmt.Fprintf(stdout, "verified job_id=%s manifest=sha256:%s files=%d artifacts=%d", result.JobID, result.ManifestSHA256, result.FilesVerified, result.ArtifactsVerified)
+		if result.ArtifactsSkipped > 0 {
+			fmt.Fprintf(stdout, " skipped=%d", result.ArtifactsSkipped)
+		}
+		fmt.Fprintln(stdout)
+		return 0
+	}
 	fmt.Fprintf(stdout, "verified job_id=%s manifest=sha256:%s files=%d\n", result.JobID, result.ManifestSHA256, result.FilesVerified)
 	return 0
 }
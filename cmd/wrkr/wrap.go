@@ -4,18 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"time"
 
 	wrapadapter "github.com/davidahmann/wrkr/core/adapters/wrap"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 	"github.com/davidahmann/wrkr/core/pack"
+	"github.com/davidahmann/wrkr/core/policy"
 	"github.com/davidahmann/wrkr/core/projectconfig"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 )
 
 func runWrap(args []string, jsonMode bool, stdout, stderr io.Writer, now func() time.Time) int {
 	if len(args) == 0 {
 		return printError(
-			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr wrap [--job-id <id>] [--artifact <path>] [--out-dir <dir>] -- <command...>", nil),
+			wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "usage: wrkr wrap [--job-id <id>] [--artifact <path>] [--out-dir <dir>] [--sandbox <profile>] [--policy <file>] -- <command...>", nil),
 			jsonMode,
 			stderr,
 			now,
@@ -26,6 +29,8 @@ func runWrap(args []string, jsonMode bool, stdout, stderr io.Writer, now func()
 	jobID = projectconfig.NormalizeJobID(jobID)
 	artifacts := []string{}
 	outDir := ""
+	sandboxPath := ""
+	policyPath := ""
 
 	split := -1
 	for i, arg := range args {
@@ -63,15 +68,52 @@ func runWrap(args []string, jsonMode bool, stdout, stderr io.Writer, now func()
 				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--out-dir requires value", nil), jsonMode, stderr, now)
 			}
 			outDir = args[i]
+		case "--sandbox":
+			i++
+			if i >= split {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--sandbox requires value", nil), jsonMode, stderr, now)
+			}
+			sandboxPath = args[i]
+		case "--policy":
+			i++
+			if i >= split {
+				return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "--policy requires value", nil), jsonMode, stderr, now)
+			}
+			policyPath = args[i]
 		default:
 			return printError(wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown wrap flag", map[string]any{"flag": args[i]}), jsonMode, stderr, now)
 		}
 	}
 	command := args[split+1:]
 
+	var sandboxSpec *v1.SandboxSpec
+	workspace := ""
+	if sandboxPath != "" {
+		spec, err := projectconfig.LoadSandboxProfile(sandboxPath)
+		if err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		sandboxSpec = spec
+		if workspace, err = os.Getwd(); err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+	}
+
+	var wrapPolicy *policy.Policy
+	if policyPath != "" {
+		loaded, err := policy.Load(policyPath)
+		if err != nil {
+			return printError(err, jsonMode, stderr, now)
+		}
+		wrapPolicy = loaded
+	}
+
 	result, runErr := wrapadapter.Run(jobID, command, wrapadapter.RunOptions{
 		Now:            now,
 		ExpectedOutput: artifacts,
+		Sandbox:        sandboxSpec,
+		Workspace:      workspace,
+		Policy:         wrapPolicy,
 	})
 
 	exported, exportErr := pack.ExportJobpack(jobID, pack.ExportOptions{
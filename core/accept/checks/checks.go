@@ -3,11 +3,13 @@ package checks
 import (
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/schema/validate"
 )
@@ -23,47 +25,82 @@ type Config struct {
 	TestCommand       string
 	LintCommand       string
 	PathRules         PathRules
+	Execution         Execution
+	Checks            []Check
 }
 
 type Input struct {
-	StatusResponse v1.StatusResponse
-	Checkpoints    []v1.Checkpoint
-	Approvals      []v1.ApprovalRecord
-	WorkDir        string
+	StatusResponse   v1.StatusResponse
+	Checkpoints      []v1.Checkpoint
+	Approvals        []v1.ApprovalRecord
+	CheckpointPolicy *v1.CheckpointPolicy
+	WorkDir          string
 }
 
 type CheckResult struct {
 	Name       string          `json:"name"`
+	Type       string          `json:"type,omitempty"`
 	Passed     bool            `json:"passed"`
 	Message    string          `json:"message"`
 	ReasonCode wrkrerrors.Code `json:"reason_code,omitempty"`
 	Artifact   string          `json:"artifact,omitempty"`
+	Locations  []Location      `json:"locations,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+	Waivers    []Waiver        `json:"waivers,omitempty"`
+	Output     *Output         `json:"-"`
 }
 
 func Run(cfg Config, in Input) ([]CheckResult, error) {
-	results := make([]CheckResult, 0, 5)
-
-	schemaResult, err := checkSchemaValidity(in)
-	if err != nil {
+	if err := Validate(cfg); err != nil {
 		return nil, err
 	}
-	results = append(results, schemaResult)
-	results = append(results, checkRequiredArtifacts(cfg, in.Checkpoints))
-	results = append(results, checkPathConstraints(cfg.PathRules, in.Checkpoints))
-
-	testResult, err := runCommandCheck("test_command", strings.TrimSpace(cfg.TestCommand), strings.TrimSpace(in.WorkDir))
+	workDir := strings.TrimSpace(in.WorkDir)
+	e, err := newExecutor(cfg.Execution, workDir)
 	if err != nil {
 		return nil, err
 	}
-	results = append(results, testResult)
+	results := make([]CheckResult, 0, 6+len(cfg.Checks))
+
+	inline := []func() (CheckResult, error){
+		func() (CheckResult, error) { return checkSchemaValidity(in) },
+		func() (CheckResult, error) { return checkRequiredArtifacts(cfg, in.Checkpoints), nil },
+		func() (CheckResult, error) { return checkPathConstraints(cfg.PathRules, in.Checkpoints), nil },
+		func() (CheckResult, error) { return checkRequiredCheckpointTypes(in), nil },
+	}
+	for _, run := range inline {
+		result, err := timed(run)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
 
-	lintResult, err := runCommandCheck("lint_command", strings.TrimSpace(cfg.LintCommand), strings.TrimSpace(in.WorkDir))
+	tasks := []task{
+		{name: "test_command", run: func() (CheckResult, error) {
+			return e.runCommand("test_command", strings.TrimSpace(cfg.TestCommand), 0)
+		}},
+		{name: "lint_command", run: func() (CheckResult, error) {
+			return e.runCommand("lint_command", strings.TrimSpace(cfg.LintCommand), 0)
+		}},
+	}
+	for _, check := range cfg.Checks {
+		tasks = append(tasks, task{name: check.Name, deps: check.DependsOn, run: func() (CheckResult, error) {
+			return runTypedCheck(check, e)
+		}})
+	}
+	taskResults, err := runTasks(tasks, cfg.Execution.MaxParallel)
 	if err != nil {
 		return nil, err
 	}
-	results = append(results, lintResult)
+	return append(results, taskResults...), nil
+}
 
-	return results, nil
+// Validate checks the execution settings and typed checks before anything runs.
+func Validate(cfg Config) error {
+	if err := ValidateExecution(cfg.Execution); err != nil {
+		return err
+	}
+	return ValidateChecks(cfg.Checks)
 }
 
 func checkSchemaValidity(in Input) (CheckResult, error) {
@@ -130,12 +167,52 @@ func checkRequiredArtifacts(cfg Config, checkpoints []v1.Checkpoint) CheckResult
 	}
 
 	sort.Strings(missing)
+	locations := make([]Location, 0, len(missing))
+	for _, path := range missing {
+		locations = append(locations, Location{Path: path})
+	}
 	return CheckResult{
 		Name:       "required_artifacts",
 		Passed:     false,
 		Message:    "missing required artifacts: " + strings.Join(missing, ", "),
 		ReasonCode: wrkrerrors.EAcceptMissingArtifact,
 		Artifact:   missing[0],
+		Locations:  locations,
+	}
+}
+
+// checkRequiredCheckpointTypes enforces checkpoint_policy.required_types on
+// completed jobs. Synthetic silence warnings do not count.
+func checkRequiredCheckpointTypes(in Input) CheckResult {
+	const name = "checkpoint_required_types"
+	if in.CheckpointPolicy == nil || len(in.CheckpointPolicy.RequiredTypes) == 0 {
+		return CheckResult{Name: name, Passed: true, Message: "no required checkpoint types configured"}
+	}
+	if in.StatusResponse.Status != string(queue.StatusCompleted) {
+		return CheckResult{Name: name, Passed: true, Message: "job not completed; required checkpoint types not yet enforced"}
+	}
+
+	seen := map[string]struct{}{}
+	for _, cp := range in.Checkpoints {
+		if slices.Contains(cp.ReasonCodes, runner.ReasonCheckpointSilence) {
+			continue
+		}
+		seen[cp.Type] = struct{}{}
+	}
+	missing := make([]string, 0, len(in.CheckpointPolicy.RequiredTypes))
+	for _, required := range sortedUnique(in.CheckpointPolicy.RequiredTypes) {
+		if _, ok := seen[required]; !ok {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) == 0 {
+		return CheckResult{Name: name, Passed: true, Message: "all required checkpoint types emitted"}
+	}
+	return CheckResult{
+		Name:       name,
+		Passed:     false,
+		Message:    "missing required checkpoint types: " + strings.Join(missing, ", "),
+		ReasonCode: wrkrerrors.EAcceptCheckpointMissing,
 	}
 }
 
@@ -151,19 +228,31 @@ func checkPathConstraints(rules PathRules, checkpoints []v1.Checkpoint) CheckRes
 		}
 	}
 
-	for _, prefix := range sortedUnique(rules.ForbiddenPrefixes) {
-		for _, artifact := range artifacts {
+	// Every forbidden hit is a location; the message names the first.
+	var forbidden []Location
+	message := ""
+	forbiddenPrefixes := sortedUnique(rules.ForbiddenPrefixes)
+	for _, artifact := range artifacts {
+		for _, prefix := range forbiddenPrefixes {
 			if strings.HasPrefix(artifact, prefix) {
-				return CheckResult{
-					Name:       "path_constraints",
-					Passed:     false,
-					Message:    fmt.Sprintf("artifact path %q matches forbidden prefix %q", artifact, prefix),
-					ReasonCode: wrkrerrors.EAcceptMissingArtifact,
-					Artifact:   artifact,
+				if message == "" {
+					message = fmt.Sprintf("artifact path %q matches forbidden prefix %q", artifact, prefix)
 				}
+				forbidden = append(forbidden, Location{Path: artifact})
+				break
 			}
 		}
 	}
+	if len(forbidden) > 0 {
+		return CheckResult{
+			Name:       "path_constraints",
+			Passed:     false,
+			Message:    message,
+			ReasonCode: wrkrerrors.EAcceptMissingArtifact,
+			Artifact:   forbidden[0].Path,
+			Locations:  forbidden,
+		}
+	}
 
 	for _, prefix := range sortedUnique(rules.AllowedPrefixes) {
 		matched := false
@@ -186,38 +275,6 @@ func checkPathConstraints(rules PathRules, checkpoints []v1.Checkpoint) CheckRes
 	return CheckResult{Name: "path_constraints", Passed: true, Message: "path constraints satisfied"}
 }
 
-func runCommandCheck(name, command, workDir string) (CheckResult, error) {
-	if command == "" {
-		return CheckResult{
-			Name:       name,
-			Passed:     false,
-			Message:    name + " is not configured",
-			ReasonCode: wrkrerrors.EAcceptTestFail,
-		}, nil
-	}
-
-	// #nosec G204 -- command is explicitly configured by repository owner in accept.yaml.
-	cmd := exec.Command("sh", "-lc", command)
-	if workDir != "" {
-		cmd.Dir = workDir
-	}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		exitCode := 1
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
-		}
-		return CheckResult{
-			Name:       name,
-			Passed:     false,
-			Message:    fmt.Sprintf("command failed (exit=%d): %s", exitCode, boundedText(output)),
-			ReasonCode: wrkrerrors.EAcceptTestFail,
-		}, nil
-	}
-
-	return CheckResult{Name: name, Passed: true, Message: "command succeeded"}, nil
-}
-
 func collectArtifacts(checkpoints []v1.Checkpoint, includeRemoved bool) map[string]struct{} {
 	out := make(map[string]struct{}, 32)
 	for _, cp := range checkpoints {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/runner"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 )
 
@@ -25,8 +26,8 @@ func TestRunAllChecksPass(t *testing.T) {
 	if err != nil {
 		t.Fatalf("run checks: %v", err)
 	}
-	if len(results) != 5 {
-		t.Fatalf("expected 5 checks, got %d", len(results))
+	if len(results) != 6 {
+		t.Fatalf("expected 6 checks, got %d", len(results))
 	}
 	for _, result := range results {
 		if !result.Passed {
@@ -91,6 +92,37 @@ func TestRunCommandFailureFails(t *testing.T) {
 	}
 }
 
+func TestRunRequiredCheckpointTypes(t *testing.T) {
+	t.Parallel()
+
+	in := testInput("reports/out.md")
+	in.CheckpointPolicy = &v1.CheckpointPolicy{MinIntervalSeconds: 10, RequiredTypes: []string{"progress", "completed"}}
+	silence := in.Checkpoints[0]
+	silence.CheckpointID = "cp_2"
+	silence.Type = "completed"
+	silence.ReasonCodes = []string{runner.ReasonCheckpointSilence}
+	in.Checkpoints = append(in.Checkpoints, silence)
+
+	result := checkRequiredCheckpointTypes(in)
+	if !result.Passed {
+		t.Fatalf("expected running job to skip enforcement, got %+v", result)
+	}
+
+	in.StatusResponse.Status = "completed"
+	result = checkRequiredCheckpointTypes(in)
+	if result.Passed || result.ReasonCode != wrkrerrors.EAcceptCheckpointMissing {
+		t.Fatalf("expected missing completed checkpoint failure, got %+v", result)
+	}
+	if result.Message != "missing required checkpoint types: completed" {
+		t.Fatalf("unexpected message: %s", result.Message)
+	}
+
+	in.Checkpoints[1].ReasonCodes = []string{}
+	if result := checkRequiredCheckpointTypes(in); !result.Passed {
+		t.Fatalf("expected required types satisfied, got %+v", result)
+	}
+}
+
 func testInput(artifact string) Input {
 	now := time.Date(2026, 2, 13, 20, 0, 0, 0, time.UTC)
 	return Input{
@@ -68,9 +68,9 @@ func TestPathConstraintAndCommandCoveragePaths(t *testing.T) {
 		t.Fatalf("expected max artifact path failure: %+v", result)
 	}
 
-	result = checkPathConstraints(PathRules{ForbiddenPrefixes: []string{"reports/"}}, base.Checkpoints)
-	if result.Passed {
-		t.Fatalf("expected forbidden prefix failure: %+v", result)
+	result = checkPathConstraints(PathRules{ForbiddenPrefixes: []string{"reports/", "reports/c"}}, base.Checkpoints)
+	if result.Passed || len(result.Locations) != 2 || result.Locations[0].Path != "reports/changed.md" || result.Artifact != "reports/changed.md" {
+		t.Fatalf("expected forbidden prefix failure locating every hit once: %+v", result)
 	}
 
 	result = checkPathConstraints(PathRules{AllowedPrefixes: []string{"docs/"}}, base.Checkpoints)
@@ -78,17 +78,18 @@ func TestPathConstraintAndCommandCoveragePaths(t *testing.T) {
 		t.Fatalf("expected allowed prefix mismatch failure: %+v", result)
 	}
 
-	cmdResult, err := runCommandCheck("test_command", "", ".")
+	e := &executor{workDir: "."}
+	cmdResult, err := e.runCommand("test_command", "", 0)
 	if err != nil {
-		t.Fatalf("runCommandCheck empty command: %v", err)
+		t.Fatalf("runCommand empty command: %v", err)
 	}
 	if cmdResult.Passed {
 		t.Fatalf("expected empty command failure: %+v", cmdResult)
 	}
 
-	cmdResult, err = runCommandCheck("test_command", "echo "+strings.Repeat("x", 500)+"; false", ".")
+	cmdResult, err = e.runCommand("test_command", "echo "+strings.Repeat("x", 500)+"; false", 0)
 	if err != nil {
-		t.Fatalf("runCommandCheck failing command: %v", err)
+		t.Fatalf("runCommand failing command: %v", err)
 	}
 	if cmdResult.Passed || !strings.Contains(cmdResult.Message, "command failed") {
 		t.Fatalf("expected failing command result, got %+v", cmdResult)
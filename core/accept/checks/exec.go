@@ -0,0 +1,204 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/sandbox"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// commandWaitDelay bounds how long a killed command's pipes may stay open.
+const commandWaitDelay = time.Second
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Execution controls how command checks run. Zero values mean no timeout,
+// one check at a time, and the full parent environment.
+type Execution struct {
+	TimeoutSeconds int
+	MaxParallel    int
+	EnvAllowlist   []string
+}
+
+// Output is the full output of a command check, kept out of JSON so accept
+// can persist it as log files.
+type Output struct {
+	Stdout []byte
+	Stderr []byte
+}
+
+// ValidateExecution rejects negative limits and malformed variable names.
+func ValidateExecution(execution Execution) error {
+	if execution.TimeoutSeconds < 0 || execution.MaxParallel < 0 {
+		return wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"acceptance execution limits must not be negative",
+			map[string]any{"timeout_seconds": execution.TimeoutSeconds, "max_parallel": execution.MaxParallel},
+		)
+	}
+	for _, name := range execution.EnvAllowlist {
+		if !envNamePattern.MatchString(name) {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid acceptance env_allowlist entry", map[string]any{"name": name})
+		}
+	}
+	return nil
+}
+
+// executor runs command checks from the work dir, under an env-only sandbox
+// when an allowlist is set.
+type executor struct {
+	workDir string
+	timeout time.Duration
+	sb      *sandbox.Sandbox
+}
+
+func newExecutor(execution Execution, workDir string) (*executor, error) {
+	e := &executor{workDir: workDir, timeout: seconds(execution.TimeoutSeconds)}
+	if len(execution.EnvAllowlist) > 0 {
+		sb, err := sandbox.Prepare(v1.SandboxSpec{EnvAllowlist: execution.EnvAllowlist}, workDir)
+		if err != nil {
+			return nil, err
+		}
+		e.sb = sb
+	}
+	return e, nil
+}
+
+// runCommand runs command with `sh -lc`. A timeout of zero falls back to the
+// execution default; the whole process group is killed when it expires.
+func (e *executor) runCommand(name, command string, timeout time.Duration) (CheckResult, error) {
+	if command == "" {
+		return CheckResult{
+			Name:       name,
+			Passed:     false,
+			Message:    name + " is not configured",
+			ReasonCode: wrkrerrors.EAcceptTestFail,
+		}, nil
+	}
+	if timeout <= 0 {
+		timeout = e.timeout
+	}
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	// #nosec G204 -- command is explicitly configured by repository owner in accept.yaml.
+	cmd, err := e.sb.CommandContext(ctx, "sh", "-lc", command)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if e.workDir != "" {
+		cmd.Dir = e.workDir
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = commandWaitDelay
+	killProcessGroup(cmd)
+	runErr := cmd.Run()
+	output := &Output{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return CheckResult{
+			Name:       name,
+			Passed:     false,
+			Message:    fmt.Sprintf("command timed out after %s", timeout),
+			ReasonCode: wrkrerrors.EAcceptTestFail,
+			Output:     output,
+		}, nil
+	}
+	if runErr != nil {
+		exitCode := 1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		return CheckResult{
+			Name:       name,
+			Passed:     false,
+			Message:    fmt.Sprintf("command failed (exit=%d): %s", exitCode, boundedText(slices.Concat(output.Stdout, output.Stderr))),
+			ReasonCode: wrkrerrors.EAcceptTestFail,
+			Output:     output,
+		}, nil
+	}
+	return CheckResult{Name: name, Passed: true, Message: "command succeeded", Output: output}, nil
+}
+
+// task is a check run by the scheduler. It starts once the tasks named in
+// deps have finished.
+type task struct {
+	name string
+	deps []string
+	run  func() (CheckResult, error)
+}
+
+// runTasks runs tasks with at most maxParallel at a time and returns their
+// results in task order. With maxParallel <= 1 tasks run serially in order.
+// deps must only name earlier tasks.
+func runTasks(tasks []task, maxParallel int) ([]CheckResult, error) {
+	results := make([]CheckResult, len(tasks))
+	if maxParallel <= 1 {
+		for i, t := range tasks {
+			result, err := timed(t.run)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+
+	done := make(map[string]chan struct{}, len(tasks))
+	for _, t := range tasks {
+		done[t.name] = make(chan struct{})
+	}
+	errs := make([]error, len(tasks))
+	slots := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, t := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[t.name])
+			for _, dep := range t.deps {
+				<-done[dep]
+			}
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i], errs[i] = timed(t.run)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// timed runs one check and records how long it took.
+func timed(run func() (CheckResult, error)) (CheckResult, error) {
+	start := time.Now()
+	result, err := run()
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
@@ -0,0 +1,115 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func TestRunCommandTimeoutAndOutput(t *testing.T) {
+	t.Parallel()
+
+	e := &executor{workDir: t.TempDir(), timeout: 200 * time.Millisecond}
+	start := time.Now()
+	result, err := e.runCommand("slow", "echo started; sleep 5 & wait", 0)
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected timeout to kill the command group, took %s", elapsed)
+	}
+	if result.Passed || !strings.Contains(result.Message, "timed out after 200ms") {
+		t.Fatalf("unexpected timeout result: %+v", result)
+	}
+	if result.Output == nil || string(result.Output.Stdout) != "started\n" {
+		t.Fatalf("expected captured stdout, got %+v", result.Output)
+	}
+
+	long := strings.Repeat("x", 1000)
+	result, err = e.runCommand("noisy", "echo "+long+" >&2; exit 2", time.Minute)
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if result.Passed || !strings.Contains(result.Message, "exit=2") || strings.TrimSpace(string(result.Output.Stderr)) != long {
+		t.Fatalf("expected full stderr beside a bounded message: %+v", result)
+	}
+}
+
+func TestRunCommandEnvAllowlist(t *testing.T) {
+	t.Setenv("WRKR_ACCEPT_VISIBLE", "yes")
+	t.Setenv("WRKR_ACCEPT_HIDDEN", "secret")
+
+	e, err := newExecutor(Execution{EnvAllowlist: []string{"PATH", "WRKR_ACCEPT_VISIBLE"}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("newExecutor: %v", err)
+	}
+	result, err := e.runCommand("env", `echo "visible=$WRKR_ACCEPT_VISIBLE hidden=$WRKR_ACCEPT_HIDDEN"`, 0)
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if !result.Passed || !strings.Contains(string(result.Output.Stdout), "visible=yes hidden=\n") {
+		t.Fatalf("expected only allowlisted variables, got %+v %q", result, result.Output.Stdout)
+	}
+}
+
+func TestRunParallelHonorsDependsOn(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	in := testInput("reports/out.md")
+	in.WorkDir = root
+	start := time.Now()
+	results, err := Run(Config{
+		TestCommand: "sleep 0.5; echo ready > marker",
+		LintCommand: "sleep 0.5",
+		Execution:   Execution{MaxParallel: 3},
+		Checks: []Check{
+			{Name: "after_test", Type: TypeCommand, Command: "test -f marker", DependsOn: []string{"test_command"}},
+			{Name: "independent", Type: TypeCommand, Command: "sleep 0.5"},
+		},
+	}, in)
+	if err != nil {
+		t.Fatalf("run checks: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1200*time.Millisecond {
+		t.Fatalf("expected independent commands to overlap, took %s", elapsed)
+	}
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Name)
+		if result.Name == "after_test" && !result.Passed {
+			t.Fatalf("expected after_test to run after test_command: %+v", result)
+		}
+		if result.Name == "test_command" && result.DurationMS < 500 {
+			t.Fatalf("expected test_command duration recorded, got %d", result.DurationMS)
+		}
+	}
+	if strings.Join(names[4:], ",") != "test_command,lint_command,after_test,independent" {
+		t.Fatalf("expected results in declaration order, got %v", names)
+	}
+}
+
+func TestValidateExecutionAndDependsOn(t *testing.T) {
+	t.Parallel()
+
+	for label, execution := range map[string]Execution{
+		"negative timeout":  {TimeoutSeconds: -1},
+		"negative parallel": {MaxParallel: -1},
+		"bad env name":      {EnvAllowlist: []string{"NOT-VALID"}},
+	} {
+		if err := ValidateExecution(execution); wrkrerrors.ExitCodeFor(codeOf(err)) != 6 {
+			t.Fatalf("%s: expected invalid input error, got %v", label, err)
+		}
+	}
+	for label, typed := range map[string][]Check{
+		"later dependency":   {{Name: "a", Type: TypeCommand, Command: "true", DependsOn: []string{"b"}}, {Name: "b", Type: TypeCommand, Command: "true"}},
+		"unknown dependency": {{Name: "a", Type: TypeCommand, Command: "true", DependsOn: []string{"schema_validity"}}},
+		"timeout on regex":   {{Name: "a", Type: TypeRegex, Paths: []string{"*.go"}, MustMatch: "x", TimeoutSeconds: 5}},
+	} {
+		if err := ValidateChecks(typed); wrkrerrors.ExitCodeFor(codeOf(err)) != 6 {
+			t.Fatalf("%s: expected invalid input error, got %v", label, err)
+		}
+	}
+}
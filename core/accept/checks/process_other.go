@@ -0,0 +1,8 @@
+//go:build !unix
+
+package checks
+
+import "os/exec"
+
+// killProcessGroup leaves the default cancel, which kills only the shell.
+func killProcessGroup(cmd *exec.Cmd) {}
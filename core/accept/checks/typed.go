@@ -0,0 +1,575 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
+	jsonschema "github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Typed check kinds accepted in accept.yaml `checks`.
+const (
+	TypeCommand    = "command"
+	TypeRegex      = "regex"
+	TypeJSONSchema = "json_schema"
+	TypeDiffSize   = "diff_size"
+	TypeNoNewTODOs = "no_new_todos"
+	TypeCoverage   = "coverage"
+)
+
+// DefaultTODOPattern is what no_new_todos looks for without a pattern.
+const DefaultTODOPattern = `\b(TODO|FIXME|XXX)\b`
+
+// maxLocations bounds how many violations a message spells out; Locations
+// keeps them all.
+const maxLocations = 5
+
+var checkNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+var builtinCheckNames = []string{
+	"schema_validity",
+	"required_artifacts",
+	"path_constraints",
+	"checkpoint_required_types",
+	"test_command",
+	"lint_command",
+	WaiversCheckName,
+}
+
+// Check is one typed acceptance check. Which fields apply depends on Type.
+type Check struct {
+	Name           string
+	Type           string
+	Command        string
+	Paths          []string
+	MustMatch      string
+	MustNotMatch   string
+	Schema         string
+	MaxLines       int
+	Base           string
+	Pattern        string
+	Profile        string
+	MinPercent     float64
+	TimeoutSeconds int
+	DependsOn      []string
+}
+
+// Location points a check failure at a file, and a line when known.
+type Location struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+}
+
+// ValidateChecks rejects typed checks that could never run: unknown types,
+// missing fields, bad patterns, and names that are reused.
+func ValidateChecks(typed []Check) error {
+	seen := map[string]struct{}{}
+	for _, name := range builtinCheckNames {
+		seen[name] = struct{}{}
+	}
+	// depends_on may only name checks that run before this one.
+	scheduled := map[string]struct{}{"test_command": {}, "lint_command": {}}
+	for i, check := range typed {
+		invalid := func(msg string) error {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid acceptance check: "+msg, map[string]any{"index": i, "name": check.Name, "type": check.Type})
+		}
+		if !checkNamePattern.MatchString(check.Name) {
+			return invalid("name must match " + checkNamePattern.String())
+		}
+		if _, ok := seen[check.Name]; ok {
+			return invalid("name is already used")
+		}
+		seen[check.Name] = struct{}{}
+
+		switch check.Type {
+		case TypeCommand:
+			if check.Command == "" {
+				return invalid("command is required")
+			}
+		case TypeRegex:
+			if len(check.Paths) == 0 {
+				return invalid("paths are required")
+			}
+			if check.MustMatch == "" && check.MustNotMatch == "" {
+				return invalid("must_match or must_not_match is required")
+			}
+			for _, pattern := range []string{check.MustMatch, check.MustNotMatch} {
+				if _, err := compileOptional(pattern); err != nil {
+					return invalid(err.Error())
+				}
+			}
+		case TypeJSONSchema:
+			if len(check.Paths) == 0 || check.Schema == "" {
+				return invalid("paths and schema are required")
+			}
+		case TypeDiffSize:
+			if check.MaxLines <= 0 {
+				return invalid("max_lines must be positive")
+			}
+		case TypeNoNewTODOs:
+			if _, err := compileOptional(check.Pattern); err != nil {
+				return invalid(err.Error())
+			}
+		case TypeCoverage:
+			if check.Profile == "" {
+				return invalid("profile is required")
+			}
+			if check.MinPercent <= 0 || check.MinPercent > 100 {
+				return invalid("min_percent must be in (0, 100]")
+			}
+		default:
+			return invalid("unknown type")
+		}
+		for _, pattern := range check.Paths {
+			if _, err := fsx.CompileGlob(pattern); err != nil || filepath.IsAbs(pattern) || strings.Contains(pattern, "..") {
+				return invalid(fmt.Sprintf("path %q must be a relative glob", pattern))
+			}
+		}
+		if check.TimeoutSeconds < 0 || (check.TimeoutSeconds > 0 && check.Type != TypeCommand) {
+			return invalid("timeout_seconds must not be negative and only applies to command checks")
+		}
+		for _, dep := range check.DependsOn {
+			if _, ok := scheduled[dep]; !ok {
+				return invalid(fmt.Sprintf("depends_on %q must name test_command, lint_command or an earlier check", dep))
+			}
+		}
+		scheduled[check.Name] = struct{}{}
+	}
+	return nil
+}
+
+func runTypedCheck(check Check, e *executor) (CheckResult, error) {
+	root := e.workDir
+	if root == "" {
+		root = "."
+	}
+	root, err := fsx.NormalizeAbsolutePath(root)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	var result CheckResult
+	switch check.Type {
+	case TypeCommand:
+		result, err = e.runCommand(check.Name, check.Command, seconds(check.TimeoutSeconds))
+		if err != nil {
+			return CheckResult{}, err
+		}
+	case TypeRegex:
+		result = runRegexCheck(check, root)
+	case TypeJSONSchema:
+		result = runJSONSchemaCheck(check, root)
+	case TypeDiffSize:
+		result = runDiffSizeCheck(check, root)
+	case TypeNoNewTODOs:
+		result = runNoNewTODOsCheck(check, root)
+	case TypeCoverage:
+		result = runCoverageCheck(check, root)
+	default:
+		return CheckResult{}, fmt.Errorf("unknown acceptance check type %q", check.Type)
+	}
+	result.Name = check.Name
+	result.Type = check.Type
+	if !result.Passed && result.ReasonCode == "" {
+		result.ReasonCode = wrkrerrors.EAcceptTestFail
+	}
+	if !result.Passed && result.Artifact == "" && len(result.Locations) > 0 {
+		result.Artifact = result.Locations[0].Path
+	}
+	return result, nil
+}
+
+// runRegexCheck requires every matched file to contain must_match and no
+// matched file to contain must_not_match.
+func runRegexCheck(check Check, root string) CheckResult {
+	files, err := globFiles(root, check.Paths)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	mustMatch, _ := compileOptional(check.MustMatch)
+	mustNotMatch, _ := compileOptional(check.MustNotMatch)
+	if len(files) == 0 {
+		if mustMatch != nil {
+			return CheckResult{Message: "no files matched " + strings.Join(check.Paths, ", ")}
+		}
+		return CheckResult{Passed: true, Message: "no files matched"}
+	}
+
+	var missing, forbidden []Location
+	for _, rel := range files {
+		data, err := readWithin(root, rel)
+		if err != nil {
+			return CheckResult{Message: err.Error()}
+		}
+		if mustMatch != nil && !mustMatch.Match(data) {
+			missing = append(missing, Location{Path: rel})
+		}
+		if mustNotMatch != nil {
+			for _, loc := range mustNotMatch.FindAllIndex(data, -1) {
+				forbidden = append(forbidden, Location{Path: rel, Line: bytes.Count(data[:loc[0]], []byte("\n")) + 1})
+			}
+		}
+	}
+
+	switch {
+	case len(forbidden) > 0:
+		return CheckResult{
+			Message:   fmt.Sprintf("must_not_match %q found at %s", check.MustNotMatch, formatLocations(forbidden)),
+			Locations: append(forbidden, missing...),
+		}
+	case len(missing) > 0:
+		return CheckResult{
+			Message:   fmt.Sprintf("must_match %q missing from %s", check.MustMatch, formatLocations(missing)),
+			Locations: missing,
+		}
+	}
+	return CheckResult{Passed: true, Message: fmt.Sprintf("%d file(s) satisfy the patterns", len(files))}
+}
+
+// runJSONSchemaCheck validates JSON and YAML files against a local schema.
+func runJSONSchemaCheck(check Check, root string) CheckResult {
+	schemaPath, err := fsx.ResolveWithinBase(root, check.Schema)
+	if err != nil {
+		return CheckResult{Message: "schema: " + err.Error()}
+	}
+	schema, err := jsonschema.NewCompiler().Compile(schemaPath)
+	if err != nil {
+		return CheckResult{Message: fmt.Sprintf("compile schema %s: %v", check.Schema, err)}
+	}
+	files, err := globFiles(root, check.Paths)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	if len(files) == 0 {
+		return CheckResult{Message: "no files matched " + strings.Join(check.Paths, ", ")}
+	}
+
+	var invalid []Location
+	var first string
+	for _, rel := range files {
+		data, err := readWithin(root, rel)
+		if err != nil {
+			return CheckResult{Message: err.Error()}
+		}
+		value, err := decodeDocument(rel, data)
+		if err == nil {
+			err = schema.Validate(value)
+		}
+		if err != nil {
+			invalid = append(invalid, Location{Path: rel})
+			if first == "" {
+				first = fmt.Sprintf("%s: %v", rel, err)
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return CheckResult{
+			Message:   fmt.Sprintf("%d file(s) invalid against %s; %s", len(invalid), check.Schema, boundedText([]byte(first))),
+			Locations: invalid,
+		}
+	}
+	return CheckResult{Passed: true, Message: fmt.Sprintf("%d file(s) valid against %s", len(files), check.Schema)}
+}
+
+// runDiffSizeCheck bounds added plus deleted lines against base, counting
+// untracked files as added.
+func runDiffSizeCheck(check Check, root string) CheckResult {
+	diff, err := collectDiff(root, check.Base)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	total := diff.added + diff.deleted
+	if total > check.MaxLines {
+		return CheckResult{Message: fmt.Sprintf("diff against %s is %d lines (+%d -%d), exceeds max_lines=%d", diff.base, total, diff.added, diff.deleted, check.MaxLines)}
+	}
+	return CheckResult{Passed: true, Message: fmt.Sprintf("diff against %s is %d lines (+%d -%d)", diff.base, total, diff.added, diff.deleted)}
+}
+
+// runNoNewTODOsCheck fails when a line added since base matches the TODO
+// pattern.
+func runNoNewTODOsCheck(check Check, root string) CheckResult {
+	pattern := check.Pattern
+	if pattern == "" {
+		pattern = DefaultTODOPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	diff, err := collectDiff(root, check.Base)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	var found []Location
+	for _, line := range diff.lines {
+		if re.MatchString(line.text) {
+			found = append(found, Location{Path: line.path, Line: line.line})
+		}
+	}
+	if len(found) > 0 {
+		return CheckResult{
+			Message:   fmt.Sprintf("%d new line(s) match %q since %s: %s", len(found), pattern, diff.base, formatLocations(found)),
+			Locations: found,
+		}
+	}
+	return CheckResult{Passed: true, Message: "no new lines match " + strconv.Quote(pattern)}
+}
+
+// runCoverageCheck reads a Go cover profile and requires the statement
+// coverage to reach min_percent.
+func runCoverageCheck(check Check, root string) CheckResult {
+	data, err := readWithin(root, check.Profile)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	percent, err := coverPercent(data)
+	if err != nil {
+		return CheckResult{Message: fmt.Sprintf("%s: %v", check.Profile, err)}
+	}
+	if percent < check.MinPercent {
+		return CheckResult{Message: fmt.Sprintf("coverage %.1f%% is below min_percent=%.1f", percent, check.MinPercent), Artifact: check.Profile}
+	}
+	return CheckResult{Passed: true, Message: fmt.Sprintf("coverage %.1f%% meets min_percent=%.1f", percent, check.MinPercent)}
+}
+
+// coverPercent computes statement coverage from a cover profile. Blocks
+// repeated across merged profiles count once, covered if any run covered them.
+func coverPercent(profile []byte) (float64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(profile))
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "mode:") {
+		return 0, fmt.Errorf("cover profile has no mode line")
+	}
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("malformed cover profile line %q", line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("malformed cover profile line %q", line)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed cover profile line %q", line)
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	total, covered := 0, 0
+	for _, b := range blocks {
+		total += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("cover profile has no statements")
+	}
+	return float64(covered) * 100 / float64(total), nil
+}
+
+type addedLine struct {
+	path string
+	line int
+	text string
+}
+
+type diffStats struct {
+	base    string
+	added   int
+	deleted int
+	lines   []addedLine
+}
+
+// collectDiff reads the working tree's changes against base with git,
+// including untracked files that are not ignored.
+func collectDiff(root, base string) (diffStats, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	if strings.HasPrefix(base, "-") {
+		return diffStats{}, fmt.Errorf("invalid diff base %q", base)
+	}
+	stats := diffStats{base: base}
+
+	out, err := runGit(root, "diff", "--no-color", "--no-ext-diff", "--no-prefix", "-U0", base, "--")
+	if err != nil {
+		return diffStats{}, err
+	}
+	path := ""
+	next := 0
+	inHunk := false
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			path = strings.TrimPrefix(line, "+++ ")
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+			next = hunkStart(line)
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			stats.added++
+			stats.lines = append(stats.lines, addedLine{path: path, line: next, text: line[1:]})
+			next++
+		case strings.HasPrefix(line, "-"):
+			stats.deleted++
+		}
+	}
+
+	untracked, err := runGit(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return diffStats{}, err
+	}
+	for _, rel := range strings.Split(string(untracked), "\x00") {
+		if rel == "" {
+			continue
+		}
+		data, err := readWithin(root, rel)
+		if err != nil {
+			return diffStats{}, err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+		text := strings.TrimSuffix(string(data), "\n")
+		if text == "" {
+			continue
+		}
+		for i, content := range strings.Split(text, "\n") {
+			stats.added++
+			stats.lines = append(stats.lines, addedLine{path: rel, line: i + 1, text: content})
+		}
+	}
+	return stats, nil
+}
+
+// hunkStart reads the new-file start line from "@@ -a,b +c,d @@".
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+func runGit(root string, args ...string) ([]byte, error) {
+	// #nosec G204 -- fixed git subcommands; the only variable argument is a validated diff base.
+	cmd := exec.Command("git", append([]string{"-c", "core.quotePath=false"}, args...)...)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %v: %s", args[0], err, boundedText(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+func globFiles(root string, patterns []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	for _, pattern := range patterns {
+		matches, err := fsx.Glob(root, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			seen[match] = struct{}{}
+		}
+	}
+	return sortedArtifactPaths(seen), nil
+}
+
+func readWithin(root, rel string) ([]byte, error) {
+	path, err := fsx.ResolveWithinBase(root, rel)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- path is resolved within the acceptance work dir.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", rel, err)
+	}
+	return data, nil
+}
+
+// decodeDocument parses JSON, or YAML for .yaml/.yml files, into values the
+// schema validator understands.
+func decodeDocument(rel string, data []byte) (any, error) {
+	ext := strings.ToLower(filepath.Ext(rel))
+	if ext == ".yaml" || ext == ".yml" {
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decode yaml: %w", err)
+		}
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("convert yaml: %w", err)
+		}
+		data = raw
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return value, nil
+}
+
+func compileOptional(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+func formatLocations(locations []Location) string {
+	parts := make([]string, 0, maxLocations+1)
+	for i, loc := range locations {
+		if i == maxLocations {
+			parts = append(parts, fmt.Sprintf("and %d more", len(locations)-maxLocations))
+			break
+		}
+		if loc.Line > 0 {
+			parts = append(parts, fmt.Sprintf("%s:%d", loc.Path, loc.Line))
+		} else {
+			parts = append(parts, loc.Path)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
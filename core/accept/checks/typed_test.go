@@ -0,0 +1,159 @@
+package checks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func writeTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+}
+
+func typedResults(t *testing.T, root string, typed ...Check) map[string]CheckResult {
+	t.Helper()
+	in := testInput("reports/out.md")
+	in.WorkDir = root
+	results, err := Run(Config{TestCommand: "true", LintCommand: "true", Checks: typed}, in)
+	if err != nil {
+		t.Fatalf("run checks: %v", err)
+	}
+	if len(results) != 6+len(typed) {
+		t.Fatalf("expected %d results, got %d", 6+len(typed), len(results))
+	}
+	out := map[string]CheckResult{}
+	for _, result := range results {
+		out[result.Name] = result
+	}
+	return out
+}
+
+func TestRunTypedContentChecks(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeTestFile(t, root, "src/a.go", "// Copyright\npackage a\n")
+	writeTestFile(t, root, "src/b.go", "package b\n\nfunc f() { println(\"debug\") }\n")
+	writeTestFile(t, root, "config/app.yaml", "name: app\nreplicas: 2\n")
+	writeTestFile(t, root, "config/bad.json", `{"name": "bad", "replicas": "two"}`)
+	writeTestFile(t, root, "schema.json", `{"type":"object","required":["name"],"properties":{"replicas":{"type":"integer"}}}`)
+	writeTestFile(t, root, "cover.out", "mode: set\nx/a.go:1.1,2.2 3 1\nx/a.go:3.1,4.2 1 0\nx/a.go:1.1,2.2 3 0\n")
+
+	results := typedResults(t, root,
+		Check{Name: "no_println", Type: TypeRegex, Paths: []string{"src/**/*.go"}, MustNotMatch: `println\(`},
+		Check{Name: "copyright", Type: TypeRegex, Paths: []string{"src/*.go"}, MustMatch: `Copyright`},
+		Check{Name: "app_config", Type: TypeJSONSchema, Paths: []string{"config/*.yaml"}, Schema: "schema.json"},
+		Check{Name: "all_config", Type: TypeJSONSchema, Paths: []string{"config/*"}, Schema: "schema.json"},
+		Check{Name: "coverage_ok", Type: TypeCoverage, Profile: "cover.out", MinPercent: 75},
+		Check{Name: "coverage_high", Type: TypeCoverage, Profile: "cover.out", MinPercent: 80},
+		Check{Name: "echo", Type: TypeCommand, Command: "exit 3"},
+	)
+
+	if r := results["no_println"]; r.Passed || r.Type != TypeRegex || r.ReasonCode != wrkrerrors.EAcceptTestFail ||
+		len(r.Locations) != 1 || r.Locations[0] != (Location{Path: "src/b.go", Line: 3}) || r.Artifact != "src/b.go" {
+		t.Fatalf("unexpected no_println result: %+v", r)
+	}
+	if r := results["copyright"]; r.Passed || len(r.Locations) != 1 || r.Locations[0].Path != "src/b.go" {
+		t.Fatalf("unexpected copyright result: %+v", r)
+	}
+	if r := results["app_config"]; !r.Passed {
+		t.Fatalf("expected yaml config to validate: %+v", r)
+	}
+	if r := results["all_config"]; r.Passed || len(r.Locations) != 1 || r.Locations[0].Path != "config/bad.json" {
+		t.Fatalf("unexpected all_config result: %+v", r)
+	}
+	if r := results["coverage_ok"]; !r.Passed || !strings.Contains(r.Message, "75.0%") {
+		t.Fatalf("unexpected coverage_ok result: %+v", r)
+	}
+	if r := results["coverage_high"]; r.Passed {
+		t.Fatalf("expected coverage below 80%% to fail: %+v", r)
+	}
+	if r := results["echo"]; r.Passed || !strings.Contains(r.Message, "exit=3") {
+		t.Fatalf("unexpected command result: %+v", r)
+	}
+}
+
+func TestRunTypedDiffChecks(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	writeTestFile(t, root, "main.go", "package main\n// TODO: old\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	writeTestFile(t, root, "main.go", "package main\n// TODO: old\nfunc main() {}\n// FIXME later\n")
+	writeTestFile(t, root, "notes.txt", "one\ntwo\n")
+
+	results := typedResults(t, root,
+		Check{Name: "small_diff", Type: TypeDiffSize, MaxLines: 4},
+		Check{Name: "tiny_diff", Type: TypeDiffSize, MaxLines: 3},
+		Check{Name: "todos", Type: TypeNoNewTODOs},
+		Check{Name: "notes", Type: TypeNoNewTODOs, Pattern: `two`},
+	)
+	if r := results["small_diff"]; !r.Passed || !strings.Contains(r.Message, "+4 -0") {
+		t.Fatalf("unexpected small_diff result: %+v", r)
+	}
+	if r := results["tiny_diff"]; r.Passed {
+		t.Fatalf("expected tiny_diff to fail: %+v", r)
+	}
+	if r := results["todos"]; r.Passed || len(r.Locations) != 1 || r.Locations[0] != (Location{Path: "main.go", Line: 4}) {
+		t.Fatalf("unexpected todos result: %+v", r)
+	}
+	if r := results["notes"]; r.Passed || len(r.Locations) != 1 || r.Locations[0] != (Location{Path: "notes.txt", Line: 2}) {
+		t.Fatalf("unexpected notes result: %+v", r)
+	}
+}
+
+func TestValidateChecksRejectsBadEntries(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]Check{
+		"unknown type":   {Name: "x", Type: "magic"},
+		"builtin name":   {Name: "test_command", Type: TypeCommand, Command: "true"},
+		"bad name":       {Name: "has space", Type: TypeCommand, Command: "true"},
+		"no pattern":     {Name: "x", Type: TypeRegex, Paths: []string{"*.go"}},
+		"bad regex":      {Name: "x", Type: TypeRegex, Paths: []string{"*.go"}, MustMatch: "("},
+		"escaping glob":  {Name: "x", Type: TypeRegex, Paths: []string{"../*.go"}, MustMatch: "a"},
+		"no max lines":   {Name: "x", Type: TypeDiffSize},
+		"coverage range": {Name: "x", Type: TypeCoverage, Profile: "c.out", MinPercent: 120},
+		"no schema":      {Name: "x", Type: TypeJSONSchema, Paths: []string{"*.json"}},
+	}
+	for label, check := range cases {
+		err := ValidateChecks([]Check{check})
+		if wrkrerrors.ExitCodeFor(codeOf(err)) != 6 {
+			t.Fatalf("%s: expected invalid input error, got %v", label, err)
+		}
+	}
+	if err := ValidateChecks([]Check{{Name: "a", Type: TypeCommand, Command: "true"}, {Name: "a", Type: TypeCommand, Command: "true"}}); err == nil {
+		t.Fatal("expected duplicate name error")
+	}
+}
+
+func codeOf(err error) wrkrerrors.Code {
+	if werr, ok := err.(wrkrerrors.WrkrError); ok {
+		return werr.Code
+	}
+	return ""
+}
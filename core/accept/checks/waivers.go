@@ -0,0 +1,148 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
+)
+
+// WaiversCheckName is the check ApplyWaivers appends to report expired waivers.
+const WaiversCheckName = "waivers"
+
+// Waiver accepts a known failure of Check until Expires. With Artifact set it
+// covers only failures at paths matching that glob.
+type Waiver struct {
+	Check    string    `json:"check"`
+	Artifact string    `json:"artifact,omitempty"`
+	Reason   string    `json:"reason"`
+	Approver string    `json:"approver"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Expired reports whether the waiver no longer applies at now.
+func (w Waiver) Expired(now time.Time) bool {
+	return !now.Before(w.Expires)
+}
+
+// Waived reports whether a failed check is covered by waivers.
+func (r CheckResult) Waived() bool {
+	return !r.Passed && len(r.Waivers) > 0
+}
+
+// ValidateWaivers rejects waivers without a check, reason, approver or
+// expiry, and artifact globs that could never match.
+func ValidateWaivers(waivers []Waiver) error {
+	for i, w := range waivers {
+		invalid := func(msg string) error {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid acceptance waiver: "+msg, map[string]any{"index": i, "check": w.Check})
+		}
+		switch {
+		case w.Check == "":
+			return invalid("check is required")
+		case w.Reason == "":
+			return invalid("reason is required")
+		case w.Approver == "":
+			return invalid("approver is required")
+		case w.Expires.IsZero():
+			return invalid("expires is required")
+		}
+		if w.Artifact != "" {
+			if _, err := fsx.CompileGlob(w.Artifact); err != nil || strings.HasPrefix(w.Artifact, "/") || strings.Contains(w.Artifact, "..") {
+				return invalid(fmt.Sprintf("artifact %q must be a relative path or glob", w.Artifact))
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyWaivers attaches unexpired waivers to the failed checks they fully
+// cover and appends a waivers check that fails on any expired waiver. A
+// failure is covered by a waiver without an artifact, or when every path it
+// reports (its locations, else its artifact) matches a waiver's artifact.
+// Expired waivers cover nothing.
+func ApplyWaivers(results []CheckResult, waivers []Waiver, now time.Time) []CheckResult {
+	out := make([]CheckResult, 0, len(results)+1)
+	for _, result := range results {
+		if !result.Passed {
+			result.Waivers = coveringWaivers(result, waivers, now)
+		}
+		out = append(out, result)
+	}
+
+	expired := make([]string, 0)
+	for _, w := range waivers {
+		if w.Expired(now) {
+			expired = append(expired, fmt.Sprintf("%s (approver %s, expired %s)", waiverLabel(w), w.Approver, w.Expires.UTC().Format(time.RFC3339)))
+		}
+	}
+	check := CheckResult{Name: WaiversCheckName, Passed: true, Message: fmt.Sprintf("%d waiver(s) active", len(waivers))}
+	if len(expired) > 0 {
+		check = CheckResult{
+			Name:       WaiversCheckName,
+			Passed:     false,
+			Message:    fmt.Sprintf("%d waiver(s) expired; renew or remove them: %s", len(expired), strings.Join(expired, "; ")),
+			ReasonCode: wrkrerrors.EAcceptWaiverExpired,
+		}
+	}
+	return append(out, check)
+}
+
+func coveringWaivers(result CheckResult, waivers []Waiver, now time.Time) []Waiver {
+	var paths []string
+	for _, loc := range result.Locations {
+		paths = append(paths, loc.Path)
+	}
+	if len(paths) == 0 && result.Artifact != "" {
+		paths = []string{result.Artifact}
+	}
+
+	var used []Waiver
+	covered := make([]bool, len(paths))
+	for _, w := range waivers {
+		if w.Check != result.Name || w.Expired(now) {
+			continue
+		}
+		if w.Artifact == "" {
+			return []Waiver{w}
+		}
+		re, err := fsx.CompileGlob(w.Artifact)
+		if err != nil {
+			continue
+		}
+		if markCovered(re, paths, covered) {
+			used = append(used, w)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	for _, ok := range covered {
+		if !ok {
+			return nil
+		}
+	}
+	return used
+}
+
+// markCovered flags the paths re matches and reports whether it matched any.
+func markCovered(re *regexp.Regexp, paths []string, covered []bool) bool {
+	matched := false
+	for i, path := range paths {
+		if re.MatchString(path) {
+			covered[i] = true
+			matched = true
+		}
+	}
+	return matched
+}
+
+func waiverLabel(w Waiver) string {
+	if w.Artifact == "" {
+		return w.Check
+	}
+	return w.Check + ":" + w.Artifact
+}
@@ -0,0 +1,80 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func TestApplyWaiversCoversFailures(t *testing.T) {
+	now := time.Date(2026, 2, 13, 21, 0, 0, 0, time.UTC)
+	expires := now.Add(24 * time.Hour)
+	results := []CheckResult{
+		{Name: "schema_validity", Passed: true},
+		{Name: "required_artifacts", ReasonCode: wrkrerrors.EAcceptMissingArtifact, Artifact: "reports/a.md",
+			Locations: []Location{{Path: "reports/a.md"}, {Path: "reports/b.md"}}},
+		{Name: "lint_command", ReasonCode: wrkrerrors.EAcceptTestFail},
+		{Name: "no_todos", ReasonCode: wrkrerrors.EAcceptTestFail, Locations: []Location{{Path: "src/a.go", Line: 3}, {Path: "vendor/b.go", Line: 1}}},
+	}
+	waivers := []Waiver{
+		{Check: "required_artifacts", Artifact: "reports/a.md", Reason: "a", Approver: "lead", Expires: expires},
+		{Check: "required_artifacts", Artifact: "reports/b.*", Reason: "b", Approver: "lead", Expires: expires},
+		{Check: "lint_command", Reason: "flaky", Approver: "lead", Expires: expires},
+		{Check: "no_todos", Artifact: "vendor/**", Reason: "vendored", Approver: "lead", Expires: expires},
+	}
+	if err := ValidateWaivers(waivers); err != nil {
+		t.Fatalf("validate waivers: %v", err)
+	}
+
+	out := ApplyWaivers(results, waivers, now)
+	if len(out) != len(results)+1 || out[len(out)-1].Name != WaiversCheckName || !out[len(out)-1].Passed {
+		t.Fatalf("expected passing waivers check appended, got %+v", out)
+	}
+	if out[0].Waived() {
+		t.Fatalf("passed check must not be waived: %+v", out[0])
+	}
+	if !out[1].Waived() || len(out[1].Waivers) != 2 {
+		t.Fatalf("expected required_artifacts covered by both artifact waivers, got %+v", out[1])
+	}
+	if !out[2].Waived() || out[2].Waivers[0].Reason != "flaky" {
+		t.Fatalf("expected lint_command covered by check-wide waiver, got %+v", out[2])
+	}
+	if out[3].Waived() {
+		t.Fatalf("partially covered failure must stay failed, got %+v", out[3])
+	}
+}
+
+func TestApplyWaiversFailsOnExpiry(t *testing.T) {
+	now := time.Date(2026, 2, 13, 21, 0, 0, 0, time.UTC)
+	results := []CheckResult{{Name: "lint_command", ReasonCode: wrkrerrors.EAcceptTestFail}}
+	waivers := []Waiver{{Check: "lint_command", Reason: "flaky", Approver: "lead", Expires: now}}
+
+	out := ApplyWaivers(results, waivers, now)
+	if out[0].Waived() {
+		t.Fatalf("expired waiver must not cover failures: %+v", out[0])
+	}
+	check := out[1]
+	if check.Passed || check.ReasonCode != wrkrerrors.EAcceptWaiverExpired || !strings.Contains(check.Message, "lint_command (approver lead") {
+		t.Fatalf("expected expired waivers check failure, got %+v", check)
+	}
+}
+
+func TestValidateWaiversRejectsIncompleteEntries(t *testing.T) {
+	expires := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cases := []Waiver{
+		{Reason: "r", Approver: "a", Expires: expires},
+		{Check: "lint_command", Approver: "a", Expires: expires},
+		{Check: "lint_command", Reason: "r", Expires: expires},
+		{Check: "lint_command", Reason: "r", Approver: "a"},
+		{Check: "required_artifacts", Artifact: "../outside.md", Reason: "r", Approver: "a", Expires: expires},
+		{Check: "required_artifacts", Artifact: "/abs.md", Reason: "r", Approver: "a", Expires: expires},
+	}
+	for _, waiver := range cases {
+		err := ValidateWaivers([]Waiver{waiver})
+		if codeOf(err) != wrkrerrors.EInvalidInputSchema {
+			t.Fatalf("expected E_INVALID_INPUT_SCHEMA for %+v, got %v", waiver, err)
+		}
+	}
+}
@@ -19,18 +19,45 @@ const (
 )
 
 type Config struct {
-	SchemaID          string    `yaml:"schema_id"`
-	SchemaVersion     string    `yaml:"schema_version"`
-	RequiredArtifacts []string  `yaml:"required_artifacts"`
-	TestCommand       string    `yaml:"test_command"`
-	LintCommand       string    `yaml:"lint_command"`
-	PathRules         PathRules `yaml:"path_rules"`
+	SchemaID          string    `yaml:"schema_id" json:"schema_id"`
+	SchemaVersion     string    `yaml:"schema_version" json:"schema_version"`
+	RequiredArtifacts []string  `yaml:"required_artifacts" json:"required_artifacts"`
+	TestCommand       string    `yaml:"test_command" json:"test_command"`
+	LintCommand       string    `yaml:"lint_command" json:"lint_command"`
+	PathRules         PathRules `yaml:"path_rules" json:"path_rules"`
+	Execution         Execution `yaml:"execution" json:"execution"`
+	Checks            []Check   `yaml:"checks,omitempty" json:"checks,omitempty"`
+}
+
+// Check is one typed entry under `checks`; see docs/contracts/acceptance_contract.md.
+type Check struct {
+	Name           string   `yaml:"name" json:"name"`
+	Type           string   `yaml:"type" json:"type"`
+	Command        string   `yaml:"command,omitempty" json:"command,omitempty"`
+	Paths          []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+	MustMatch      string   `yaml:"must_match,omitempty" json:"must_match,omitempty"`
+	MustNotMatch   string   `yaml:"must_not_match,omitempty" json:"must_not_match,omitempty"`
+	Schema         string   `yaml:"schema,omitempty" json:"schema,omitempty"`
+	MaxLines       int      `yaml:"max_lines,omitempty" json:"max_lines,omitempty"`
+	Base           string   `yaml:"base,omitempty" json:"base,omitempty"`
+	Pattern        string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Profile        string   `yaml:"profile,omitempty" json:"profile,omitempty"`
+	MinPercent     float64  `yaml:"min_percent,omitempty" json:"min_percent,omitempty"`
+	TimeoutSeconds int      `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`
+	DependsOn      []string `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+}
+
+// Execution controls how the test, lint and command checks run.
+type Execution struct {
+	TimeoutSeconds int      `yaml:"timeout_seconds" json:"timeout_seconds"`
+	MaxParallel    int      `yaml:"max_parallel" json:"max_parallel"`
+	EnvAllowlist   []string `yaml:"env_allowlist" json:"env_allowlist"`
 }
 
 type PathRules struct {
-	MaxArtifactPaths  int      `yaml:"max_artifact_paths"`
-	ForbiddenPrefixes []string `yaml:"forbidden_prefixes"`
-	AllowedPrefixes   []string `yaml:"allowed_prefixes"`
+	MaxArtifactPaths  int      `yaml:"max_artifact_paths" json:"max_artifact_paths"`
+	ForbiddenPrefixes []string `yaml:"forbidden_prefixes" json:"forbidden_prefixes"`
+	AllowedPrefixes   []string `yaml:"allowed_prefixes" json:"allowed_prefixes"`
 }
 
 func DefaultConfig() Config {
@@ -45,6 +72,11 @@ func DefaultConfig() Config {
 			ForbiddenPrefixes: []string{},
 			AllowedPrefixes:   []string{},
 		},
+		Execution: Execution{
+			TimeoutSeconds: 0,
+			MaxParallel:    1,
+			EnvAllowlist:   []string{},
+		},
 	}
 	cfg.normalize()
 	return cfg
@@ -92,6 +124,9 @@ func LoadConfig(path string) (Config, error) {
 		return Config{}, fmt.Errorf("decode config yaml: %w", err)
 	}
 	cfg.normalize()
+	if err := checks.Validate(cfg.ToChecksConfig()); err != nil {
+		return Config{}, err
+	}
 	return cfg, nil
 }
 
@@ -120,7 +155,36 @@ func (c Config) ToChecksConfig() checks.Config {
 			ForbiddenPrefixes: append([]string(nil), c.PathRules.ForbiddenPrefixes...),
 			AllowedPrefixes:   append([]string(nil), c.PathRules.AllowedPrefixes...),
 		},
+		Execution: checks.Execution{
+			TimeoutSeconds: c.Execution.TimeoutSeconds,
+			MaxParallel:    c.Execution.MaxParallel,
+			EnvAllowlist:   append([]string(nil), c.Execution.EnvAllowlist...),
+		},
+		Checks: typedChecks(c.Checks),
+	}
+}
+
+func typedChecks(in []Check) []checks.Check {
+	out := make([]checks.Check, 0, len(in))
+	for _, c := range in {
+		out = append(out, checks.Check{
+			Name:           c.Name,
+			Type:           c.Type,
+			Command:        c.Command,
+			Paths:          append([]string(nil), c.Paths...),
+			MustMatch:      c.MustMatch,
+			MustNotMatch:   c.MustNotMatch,
+			Schema:         c.Schema,
+			MaxLines:       c.MaxLines,
+			Base:           c.Base,
+			Pattern:        c.Pattern,
+			Profile:        c.Profile,
+			MinPercent:     c.MinPercent,
+			TimeoutSeconds: c.TimeoutSeconds,
+			DependsOn:      append([]string(nil), c.DependsOn...),
+		})
 	}
+	return out
 }
 
 func resolveConfigPath(path string) (string, error) {
@@ -150,6 +214,18 @@ func (c *Config) normalize() {
 	}
 	c.PathRules.ForbiddenPrefixes = normalizedList(c.PathRules.ForbiddenPrefixes)
 	c.PathRules.AllowedPrefixes = normalizedList(c.PathRules.AllowedPrefixes)
+	c.Execution.EnvAllowlist = normalizedList(c.Execution.EnvAllowlist)
+	for i := range c.Checks {
+		check := &c.Checks[i]
+		check.Name = strings.TrimSpace(check.Name)
+		check.Type = strings.TrimSpace(check.Type)
+		check.Command = strings.TrimSpace(check.Command)
+		check.Paths = normalizedList(check.Paths)
+		check.Schema = strings.TrimSpace(check.Schema)
+		check.Base = strings.TrimSpace(check.Base)
+		check.Profile = strings.TrimSpace(check.Profile)
+		check.DependsOn = normalizedList(check.DependsOn)
+	}
 }
 
 func normalizedList(in []string) []string {
@@ -3,6 +3,7 @@ package accept
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -48,3 +49,37 @@ func TestLoadConfigOrDefaultMissingPath(t *testing.T) {
 		t.Fatalf("expected default schema id, got %q", cfg.SchemaID)
 	}
 }
+
+func TestLoadConfigTypedChecks(t *testing.T) {
+	workspace := t.TempDir()
+	configPath := filepath.Join(workspace, "accept.yaml")
+	raw := `schema_id: wrkr.accept_config
+schema_version: v1
+checks:
+  - name: race
+    type: command
+    command: " go test -race ./... "
+  - name: coverage
+    type: coverage
+    profile: cover.out
+    min_percent: 80
+`
+	if err := os.WriteFile(configPath, []byte(raw), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	typed := cfg.ToChecksConfig().Checks
+	if len(typed) != 2 || typed[0].Command != "go test -race ./..." || typed[1].MinPercent != 80 {
+		t.Fatalf("unexpected typed checks: %+v", typed)
+	}
+
+	if err := os.WriteFile(configPath, []byte(raw+"  - name: bogus\n    type: magic\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Fatalf("expected unknown type error, got %v", err)
+	}
+}
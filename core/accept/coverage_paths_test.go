@@ -57,7 +57,7 @@ func TestAcceptanceHelperCoveragePaths(t *testing.T) {
 		{Name: "required_artifacts", Passed: false, Message: "missing", ReasonCode: wrkrerrors.EAcceptMissingArtifact},
 		{Name: "required_artifacts_dup", Passed: false, Message: "missing", ReasonCode: wrkrerrors.EAcceptMissingArtifact},
 		{Name: "lint_command", Passed: true, Message: "ok"},
-	})
+	}, nil)
 	if result.ChecksRun != 4 || result.ChecksPassed != 1 {
 		t.Fatalf("unexpected checks summary: %+v", result)
 	}
@@ -70,6 +70,10 @@ func TestAcceptanceHelperCoveragePaths(t *testing.T) {
 	if code := FailureCode(result); code != wrkrerrors.EAcceptTestFail {
 		t.Fatalf("expected E_ACCEPT_TEST_FAIL precedence, got %s", code)
 	}
+	result.ReasonCodes = []string{string(wrkrerrors.EAcceptMissingArtifact), string(wrkrerrors.EAcceptCheckpointMissing)}
+	if code := FailureCode(result); code != wrkrerrors.EAcceptCheckpointMissing {
+		t.Fatalf("expected E_ACCEPT_CHECKPOINT_MISSING precedence, got %s", code)
+	}
 
 	if _, err := canonicalizeJSON(make(chan int)); err == nil {
 		t.Fatal("expected canonicalizeJSON marshal failure")
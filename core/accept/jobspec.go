@@ -0,0 +1,234 @@
+package accept
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// Config sources reported in accept_result.json config_sources, in the
+// order they were applied.
+const (
+	SourceDefaults          = "defaults"
+	SourceJobAcceptance     = "jobspec.acceptance"
+	SourceExpectedArtifacts = "jobspec.expected_artifacts"
+)
+
+// jobAcceptance is a jobspec acceptance block. It uses accept.yaml keys;
+// nil scalars leave the repository config alone.
+type jobAcceptance struct {
+	ConfigPath        string   `json:"config_path"`
+	RequiredArtifacts []string `json:"required_artifacts"`
+	TestCommand       *string  `json:"test_command"`
+	LintCommand       *string  `json:"lint_command"`
+	PathRules         *struct {
+		MaxArtifactPaths  *int     `json:"max_artifact_paths"`
+		ForbiddenPrefixes []string `json:"forbidden_prefixes"`
+		AllowedPrefixes   []string `json:"allowed_prefixes"`
+	} `json:"path_rules"`
+	Execution *struct {
+		TimeoutSeconds *int     `json:"timeout_seconds"`
+		MaxParallel    *int     `json:"max_parallel"`
+		EnvAllowlist   []string `json:"env_allowlist"`
+	} `json:"execution"`
+	Checks []Check `json:"checks"`
+}
+
+// ResolvedConfig is the effective acceptance config for one job.
+type ResolvedConfig struct {
+	Config     Config
+	ConfigPath string
+	Sources    []string
+}
+
+// ResolveConfig layers, lowest precedence first: built-in defaults, the
+// repository config (configPath, else the job's acceptance.config_path, else
+// accept.yaml), the job's inline acceptance block, and its expected
+// artifacts. A missing repository config is skipped.
+func ResolveConfig(configPath string, spec *v1.AcceptanceSpec) (ResolvedConfig, error) {
+	block, err := decodeJobAcceptance(spec)
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+	if strings.TrimSpace(configPath) == "" {
+		configPath = block.ConfigPath
+	}
+	resolvedPath, err := resolveConfigPath(configPath)
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	out := ResolvedConfig{ConfigPath: resolvedPath, Sources: []string{SourceDefaults}}
+	cfg, err := LoadConfig(resolvedPath)
+	repo := err == nil
+	switch {
+	case repo:
+		out.Sources = append(out.Sources, resolvedPath)
+	case os.IsNotExist(err):
+		cfg = DefaultConfig()
+	default:
+		return ResolvedConfig{}, err
+	}
+
+	if spec != nil && len(spec.Config) > 0 {
+		if err := block.apply(&cfg, repo); err != nil {
+			return ResolvedConfig{}, err
+		}
+		out.Sources = append(out.Sources, SourceJobAcceptance)
+	}
+	if spec != nil && len(normalizedList(spec.ExpectedArtifacts)) > 0 {
+		cfg.RequiredArtifacts = append(cfg.RequiredArtifacts, spec.ExpectedArtifacts...)
+		out.Sources = append(out.Sources, SourceExpectedArtifacts)
+	}
+	cfg.normalize()
+	if err := checks.Validate(cfg.ToChecksConfig()); err != nil {
+		return ResolvedConfig{}, err
+	}
+	out.Config = cfg
+	return out, nil
+}
+
+// ValidateJobAcceptance rejects an inline acceptance block that could not be
+// merged, so a bad jobspec fails at submit rather than at accept.
+func ValidateJobAcceptance(spec v1.AcceptanceSpec) error {
+	block, err := decodeJobAcceptance(&spec)
+	if err != nil {
+		return err
+	}
+	cfg := Config{}
+	if err := block.apply(&cfg, false); err != nil {
+		return err
+	}
+	cfg.normalize()
+	return checks.Validate(cfg.ToChecksConfig())
+}
+
+func decodeJobAcceptance(spec *v1.AcceptanceSpec) (jobAcceptance, error) {
+	var block jobAcceptance
+	if spec == nil || len(spec.Config) == 0 {
+		return block, nil
+	}
+	raw, err := json.Marshal(spec.Config)
+	if err != nil {
+		return block, fmt.Errorf("marshal jobspec acceptance: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&block); err != nil {
+		return block, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "jobspec acceptance is invalid", map[string]any{"error": err.Error()})
+	}
+	block.ConfigPath = strings.TrimSpace(block.ConfigPath)
+	return block, nil
+}
+
+// apply layers the block over cfg. It may only add or tighten: lists of
+// required artifacts and forbidden prefixes are unioned, limits may only be
+// lowered, and allowed prefixes and env_allowlist may only narrow. Over a
+// repository config (repo) the repository keeps its commands, max_parallel
+// and named checks; over the defaults the block sets them.
+func (b jobAcceptance) apply(cfg *Config, repo bool) error {
+	cfg.RequiredArtifacts = append(cfg.RequiredArtifacts, b.RequiredArtifacts...)
+	if b.TestCommand != nil && !repo {
+		cfg.TestCommand = *b.TestCommand
+	}
+	if b.LintCommand != nil && !repo {
+		cfg.LintCommand = *b.LintCommand
+	}
+	if b.PathRules != nil {
+		if b.PathRules.MaxArtifactPaths != nil {
+			cfg.PathRules.MaxArtifactPaths = tighterLimit(cfg.PathRules.MaxArtifactPaths, *b.PathRules.MaxArtifactPaths)
+		}
+		cfg.PathRules.ForbiddenPrefixes = append(cfg.PathRules.ForbiddenPrefixes, b.PathRules.ForbiddenPrefixes...)
+		if len(normalizedList(cfg.PathRules.AllowedPrefixes)) == 0 {
+			cfg.PathRules.AllowedPrefixes = append(cfg.PathRules.AllowedPrefixes, b.PathRules.AllowedPrefixes...)
+		} else if len(normalizedList(b.PathRules.AllowedPrefixes)) > 0 {
+			narrowed := withinPrefixes(b.PathRules.AllowedPrefixes, cfg.PathRules.AllowedPrefixes)
+			if len(narrowed) == 0 {
+				return wrkrerrors.New(
+					wrkrerrors.EInvalidInputSchema,
+					"jobspec acceptance allowed_prefixes fall outside the repository allowed_prefixes",
+					map[string]any{"allowed_prefixes": b.PathRules.AllowedPrefixes, "repository": cfg.PathRules.AllowedPrefixes},
+				)
+			}
+			cfg.PathRules.AllowedPrefixes = narrowed
+		}
+	}
+	if b.Execution != nil {
+		if b.Execution.TimeoutSeconds != nil {
+			cfg.Execution.TimeoutSeconds = tighterLimit(cfg.Execution.TimeoutSeconds, *b.Execution.TimeoutSeconds)
+		}
+		if b.Execution.MaxParallel != nil && (!repo || *b.Execution.MaxParallel < cfg.Execution.MaxParallel) {
+			cfg.Execution.MaxParallel = *b.Execution.MaxParallel
+		}
+		if len(normalizedList(cfg.Execution.EnvAllowlist)) == 0 {
+			cfg.Execution.EnvAllowlist = append(cfg.Execution.EnvAllowlist, b.Execution.EnvAllowlist...)
+		} else if len(normalizedList(b.Execution.EnvAllowlist)) > 0 {
+			shared := slices.DeleteFunc(normalizedList(b.Execution.EnvAllowlist), func(name string) bool {
+				return !slices.Contains(normalizedList(cfg.Execution.EnvAllowlist), name)
+			})
+			// An empty allowlist means the full environment, so a disjoint
+			// list cannot narrow it.
+			if len(shared) == 0 {
+				return wrkrerrors.New(
+					wrkrerrors.EInvalidInputSchema,
+					"jobspec acceptance env_allowlist shares no variable with the repository env_allowlist",
+					map[string]any{"env_allowlist": b.Execution.EnvAllowlist, "repository": cfg.Execution.EnvAllowlist},
+				)
+			}
+			cfg.Execution.EnvAllowlist = shared
+		}
+	}
+	for _, check := range b.Checks {
+		if !slices.ContainsFunc(cfg.Checks, func(existing Check) bool {
+			return strings.TrimSpace(existing.Name) == strings.TrimSpace(check.Name)
+		}) {
+			cfg.Checks = append(cfg.Checks, check)
+		}
+	}
+	return nil
+}
+
+// tighterLimit returns the lower of two limits where 0 means unlimited.
+func tighterLimit(current, requested int) int {
+	if requested <= 0 {
+		return current
+	}
+	if current <= 0 || requested < current {
+		return requested
+	}
+	return current
+}
+
+// withinPrefixes keeps the requested prefixes that sit inside one of the
+// allowed prefixes.
+func withinPrefixes(requested, allowed []string) []string {
+	out := []string{}
+	for _, prefix := range normalizedList(requested) {
+		if slices.ContainsFunc(normalizedList(allowed), func(outer string) bool {
+			return strings.HasPrefix(prefix, outer)
+		}) {
+			out = append(out, prefix)
+		}
+	}
+	return out
+}
+
+// configMap renders the effective config for accept_result.json.
+func configMap(cfg Config) (map[string]any, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal acceptance config: %w", err)
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decode acceptance config: %w", err)
+	}
+	return out, nil
+}
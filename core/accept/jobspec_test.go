@@ -0,0 +1,169 @@
+package accept
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+func TestResolveConfigMergesJobAcceptance(t *testing.T) {
+	workspace := t.TempDir()
+	repoPath := filepath.Join(workspace, "repo_accept.yaml")
+	if err := os.WriteFile(repoPath, []byte(`required_artifacts: [reports/a.md]
+test_command: "make test"
+lint_command: "make lint"
+path_rules:
+  max_artifact_paths: 5
+  forbidden_prefixes: [secrets/]
+  allowed_prefixes: [reports/, src/]
+execution:
+  max_parallel: 2
+  env_allowlist: [HOME, PATH]
+checks:
+  - name: race
+    type: command
+    command: "go test -race ./..."
+`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	resolved, err := ResolveConfig("", &v1.AcceptanceSpec{
+		ExpectedArtifacts: []string{"reports/b.md"},
+		Config: map[string]any{
+			"config_path":        repoPath,
+			"required_artifacts": []any{"reports/c.md"},
+			"test_command":       "true",
+			"path_rules": map[string]any{
+				"max_artifact_paths": 10,
+				"forbidden_prefixes": []any{"tmp/"},
+				"allowed_prefixes":   []any{"reports/", "src/gen/", "vendor/"},
+			},
+			"execution": map[string]any{"max_parallel": 4, "timeout_seconds": 60, "env_allowlist": []any{"PATH", "GOFLAGS"}},
+			"checks": []any{
+				map[string]any{"name": "race", "type": "command", "command": "true"},
+				map[string]any{"name": "todos", "type": "no_new_todos"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ResolveConfig: %v", err)
+	}
+	cfg := resolved.Config
+	if !slices.Equal(cfg.RequiredArtifacts, []string{"reports/a.md", "reports/b.md", "reports/c.md"}) {
+		t.Fatalf("unexpected required artifacts: %v", cfg.RequiredArtifacts)
+	}
+	if cfg.TestCommand != "make test" || cfg.LintCommand != "make lint" || cfg.PathRules.MaxArtifactPaths != 5 {
+		t.Fatalf("expected repository scalars to win, got %+v", cfg)
+	}
+	if !slices.Equal(cfg.PathRules.ForbiddenPrefixes, []string{"secrets/", "tmp/"}) {
+		t.Fatalf("unexpected forbidden prefixes: %v", cfg.PathRules.ForbiddenPrefixes)
+	}
+	if !slices.Equal(cfg.PathRules.AllowedPrefixes, []string{"reports/", "src/gen/"}) {
+		t.Fatalf("expected allowed prefixes narrowed, got %v", cfg.PathRules.AllowedPrefixes)
+	}
+	if cfg.Execution.MaxParallel != 2 || cfg.Execution.TimeoutSeconds != 60 || !slices.Equal(cfg.Execution.EnvAllowlist, []string{"PATH"}) {
+		t.Fatalf("expected execution only tightened, got %+v", cfg.Execution)
+	}
+	if len(cfg.Checks) != 2 || cfg.Checks[0].Command != "go test -race ./..." || cfg.Checks[1].Name != "todos" {
+		t.Fatalf("unexpected checks: %+v", cfg.Checks)
+	}
+	want := []string{SourceDefaults, repoPath, SourceJobAcceptance, SourceExpectedArtifacts}
+	if resolved.ConfigPath != repoPath || !slices.Equal(resolved.Sources, want) {
+		t.Fatalf("unexpected sources: %s %v", resolved.ConfigPath, resolved.Sources)
+	}
+
+	for _, config := range []map[string]any{
+		{"config_path": repoPath, "execution": map[string]any{"env_allowlist": []any{"AWS_SECRET_ACCESS_KEY"}}},
+		{"config_path": repoPath, "path_rules": map[string]any{"allowed_prefixes": []any{"/"}}},
+	} {
+		if _, err := ResolveConfig("", &v1.AcceptanceSpec{Config: config}); err == nil {
+			t.Fatalf("expected widening block %v to be rejected", config)
+		}
+	}
+
+	t.Chdir(t.TempDir())
+	resolved, err = ResolveConfig("", &v1.AcceptanceSpec{Config: map[string]any{
+		"test_command": "true",
+		"path_rules":   map[string]any{"max_artifact_paths": 3},
+		"execution":    map[string]any{"max_parallel": 4, "env_allowlist": []any{"PATH"}},
+	}})
+	if err != nil {
+		t.Fatalf("ResolveConfig without repository config: %v", err)
+	}
+	cfg = resolved.Config
+	if cfg.TestCommand != "true" || cfg.PathRules.MaxArtifactPaths != 3 || cfg.Execution.MaxParallel != 4 ||
+		!slices.Equal(cfg.Execution.EnvAllowlist, []string{"PATH"}) {
+		t.Fatalf("expected job block over defaults, got %+v", cfg)
+	}
+
+	if _, err := ResolveConfig("", &v1.AcceptanceSpec{Config: map[string]any{"test_cmd": "true"}}); err == nil ||
+		!strings.Contains(err.Error(), "jobspec acceptance is invalid") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+	if err := ValidateJobAcceptance(v1.AcceptanceSpec{Config: map[string]any{
+		"checks": []any{map[string]any{"name": "x", "type": "magic"}},
+	}}); err == nil {
+		t.Fatal("expected invalid check error")
+	}
+}
+
+func TestRunUsesJobSpecAcceptance(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workspace := t.TempDir()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(workspace); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(orig)
+	})
+	now := time.Date(2026, 2, 13, 21, 0, 0, 0, time.UTC)
+
+	setupAcceptJob(t, "job_accept_spec", now)
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.RecordAcceptance("job_accept_spec", v1.AcceptanceSpec{
+		ExpectedArtifacts: []string{"reports/out.md", "reports/missing.md"},
+		Config:            map[string]any{"test_command": "true", "lint_command": "true"},
+	}); err != nil {
+		t.Fatalf("RecordAcceptance: %v", err)
+	}
+
+	result, err := Run("job_accept_spec", RunOptions{Now: func() time.Time { return now }, ProducerVersion: "test", WorkDir: workspace})
+	if err != nil {
+		t.Fatalf("run accept: %v", err)
+	}
+	if !Failed(result.Result) || result.Result.Failures[0].Artifact != "reports/missing.md" {
+		t.Fatalf("expected missing expected artifact failure, got %+v", result.Result.Failures)
+	}
+	if !slices.Equal(result.Result.Sources, []string{SourceDefaults, SourceJobAcceptance, SourceExpectedArtifacts}) {
+		t.Fatalf("unexpected config sources: %v", result.Result.Sources)
+	}
+	if result.Result.Config["test_command"] != "true" {
+		t.Fatalf("expected effective config recorded, got %v", result.Result.Config)
+	}
+	raw, err := os.ReadFile(result.ResultPath)
+	if err != nil {
+		t.Fatalf("read accept_result: %v", err)
+	}
+	if !strings.Contains(string(raw), `"reports/missing.md"`) || !strings.Contains(string(raw), `"config_sources"`) {
+		t.Fatalf("expected merged config in accept_result.json: %s", raw)
+	}
+}
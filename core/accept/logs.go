@@ -0,0 +1,42 @@
+package accept
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+	"github.com/davidahmann/wrkr/core/fsx"
+	"github.com/davidahmann/wrkr/core/redact"
+)
+
+// LogDir holds command check logs, both under the job dir and in the jobpack.
+const LogDir = "accept"
+
+// LogPaths are the jobpack-relative stdout and stderr logs of a check.
+func LogPaths(name string) []string {
+	return []string{LogDir + "/" + name + ".stdout.log", LogDir + "/" + name + ".stderr.log"}
+}
+
+// writeCheckLogs replaces the job's acceptance logs with the full, redacted
+// output of every command check and returns the log paths by check name.
+func writeCheckLogs(jobDir string, results []checks.CheckResult, rules *redact.Ruleset) (map[string][]string, error) {
+	if err := os.RemoveAll(filepath.Join(jobDir, LogDir)); err != nil {
+		return nil, fmt.Errorf("clear acceptance logs: %w", err)
+	}
+	out := map[string][]string{}
+	for _, result := range results {
+		if result.Output == nil {
+			continue
+		}
+		paths := LogPaths(result.Name)
+		for i, data := range [][]byte{result.Output.Stdout, result.Output.Stderr} {
+			masked, _ := rules.String(string(data))
+			if err := fsx.AtomicWriteFile(filepath.Join(jobDir, filepath.FromSlash(paths[i])), []byte(masked), 0o600); err != nil {
+				return nil, fmt.Errorf("write acceptance log: %w", err)
+			}
+		}
+		out[result.Name] = paths
+	}
+	return out, nil
+}
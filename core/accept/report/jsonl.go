@@ -0,0 +1,42 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+)
+
+// jsonlRecord is one line of the acceptance JSON lines output: the check
+// result tagged with its job.
+type jsonlRecord struct {
+	JobID string `json:"job_id"`
+	checks.CheckResult
+}
+
+// EncodeJSONL renders one JSON object per check, in check order.
+func EncodeJSONL(jobID string, checkResults []checks.CheckResult) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, check := range checkResults {
+		raw, err := json.Marshal(jsonlRecord{JobID: jobID, CheckResult: check})
+		if err != nil {
+			return nil, fmt.Errorf("marshal jsonl record: %w", err)
+		}
+		buf.Write(raw)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteJSONL writes EncodeJSONL output to path.
+func WriteJSONL(path, jobID string, checkResults []checks.CheckResult) error {
+	if path == "" {
+		return fmt.Errorf("empty jsonl path")
+	}
+	content, err := EncodeJSONL(jobID, checkResults)
+	if err != nil {
+		return err
+	}
+	return writeReport("jsonl", path, content)
+}
@@ -0,0 +1,43 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func TestEncodeJSONLIsStable(t *testing.T) {
+	t.Parallel()
+
+	results := []checks.CheckResult{
+		{Name: "test_command", Passed: true, Message: "command succeeded", DurationMS: 12, Output: &checks.Output{Stdout: []byte("ok")}},
+		{Name: "no_println", Type: checks.TypeRegex, Passed: false, Message: "found", ReasonCode: wrkrerrors.EAcceptTestFail,
+			Artifact: "src/b.go", Locations: []checks.Location{{Path: "src/b.go", Line: 3}}},
+	}
+	first, err := EncodeJSONL("job_1", results)
+	if err != nil {
+		t.Fatalf("encode jsonl: %v", err)
+	}
+	second, err := EncodeJSONL("job_1", results)
+	if err != nil {
+		t.Fatalf("encode jsonl: %v", err)
+	}
+	if string(first) != string(second) {
+		t.Fatalf("expected stable output")
+	}
+	lines := strings.Split(strings.TrimSuffix(string(first), "\n"), "\n")
+	want := []string{
+		`{"job_id":"job_1","name":"test_command","passed":true,"message":"command succeeded","duration_ms":12}`,
+		`{"job_id":"job_1","name":"no_println","type":"regex","passed":false,"message":"found","reason_code":"E_ACCEPT_TEST_FAIL","artifact":"src/b.go","locations":[{"path":"src/b.go","line":3}],"duration_ms":0}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %q", len(want), first)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d:\n got %s\nwant %s", i+1, lines[i], want[i])
+		}
+	}
+}
@@ -3,10 +3,9 @@ package report
 import (
 	"encoding/xml"
 	"fmt"
-	"path/filepath"
+	"strings"
 
 	"github.com/davidahmann/wrkr/core/accept/checks"
-	"github.com/davidahmann/wrkr/core/fsx"
 )
 
 type junitTestSuite struct {
@@ -14,13 +13,17 @@ type junitTestSuite struct {
 	Name     string          `xml:"name,attr"`
 	Tests    int             `xml:"tests,attr"`
 	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr,omitempty"`
+	Time     string          `xml:"time,attr"`
 	Cases    []junitTestCase `xml:"testcase"`
 }
 
 type junitTestCase struct {
 	ClassName string        `xml:"classname,attr"`
 	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
 	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
 }
 
 type junitFailure struct {
@@ -28,20 +31,40 @@ type junitFailure struct {
 	Body    string `xml:",chardata"`
 }
 
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
 func WriteJUnit(path string, checkResults []checks.CheckResult) error {
 	if path == "" {
 		return fmt.Errorf("empty junit path")
 	}
 
 	cases := make([]junitTestCase, 0, len(checkResults))
-	failures := 0
+	failures, skipped := 0, 0
+	var totalMS int64
 	for _, check := range checkResults {
-		tc := junitTestCase{ClassName: "wrkr.accept", Name: check.Name}
-		if !check.Passed {
+		totalMS += check.DurationMS
+		tc := junitTestCase{ClassName: "wrkr.accept", Name: check.Name, Time: junitSeconds(check.DurationMS)}
+		if check.Type != "" {
+			tc.ClassName += "." + check.Type
+		}
+		if check.Waived() {
+			skipped++
+			tc.Skipped = &junitSkipped{Message: "waived: " + waiverReasons(check.Waivers)}
+		} else if !check.Passed {
 			failures++
+			body := string(check.ReasonCode)
+			for _, loc := range check.Locations {
+				if loc.Line > 0 {
+					body += fmt.Sprintf("\n%s:%d", loc.Path, loc.Line)
+				} else {
+					body += "\n" + loc.Path
+				}
+			}
 			tc.Failure = &junitFailure{
 				Message: check.Message,
-				Body:    string(check.ReasonCode),
+				Body:    body,
 			}
 		}
 		cases = append(cases, tc)
@@ -51,6 +74,8 @@ func WriteJUnit(path string, checkResults []checks.CheckResult) error {
 		Name:     "wrkr-accept",
 		Tests:    len(checkResults),
 		Failures: failures,
+		Skipped:  skipped,
+		Time:     junitSeconds(totalMS),
 		Cases:    cases,
 	}
 
@@ -61,12 +86,19 @@ func WriteJUnit(path string, checkResults []checks.CheckResult) error {
 	content := append([]byte(xml.Header), raw...)
 	content = append(content, '\n')
 
-	resolved, err := fsx.NormalizeAbsolutePath(filepath.Clean(path))
-	if err != nil {
-		return fmt.Errorf("resolve junit path: %w", err)
-	}
-	if err := fsx.AtomicWriteFile(resolved, content, 0o600); err != nil {
-		return fmt.Errorf("write junit: %w", err)
+	return writeReport("junit", path, content)
+}
+
+// waiverReasons joins the reasons of the waivers covering a check.
+func waiverReasons(waivers []checks.Waiver) string {
+	reasons := make([]string, 0, len(waivers))
+	for _, w := range waivers {
+		reasons = append(reasons, w.Reason)
 	}
-	return nil
+	return strings.Join(reasons, "; ")
+}
+
+// junitSeconds renders a duration as the seconds JUnit expects in time attributes.
+func junitSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
 }
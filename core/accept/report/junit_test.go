@@ -26,7 +26,9 @@ func TestWriteJUnit(t *testing.T) {
 	path := filepath.Join(wd, "accept.junit.xml")
 	err = WriteJUnit("accept.junit.xml", []checks.CheckResult{
 		{Name: "schema_validity", Passed: true, Message: "ok"},
-		{Name: "test_command", Passed: false, Message: "failed", ReasonCode: wrkrerrors.EAcceptTestFail},
+		{Name: "test_command", Passed: false, Message: "failed", ReasonCode: wrkrerrors.EAcceptTestFail, DurationMS: 1250},
+		{Name: "no_println", Type: checks.TypeRegex, Passed: false, Message: "found", ReasonCode: wrkrerrors.EAcceptTestFail,
+			Locations: []checks.Location{{Path: "src/b.go", Line: 3}}},
 	})
 	if err != nil {
 		t.Fatalf("write junit: %v", err)
@@ -37,10 +39,33 @@ func TestWriteJUnit(t *testing.T) {
 		t.Fatalf("read junit: %v", err)
 	}
 	text := string(raw)
-	if !strings.Contains(text, `<testsuite name="wrkr-accept" tests="2" failures="1">`) {
+	if !strings.Contains(text, `<testsuite name="wrkr-accept" tests="3" failures="2" time="1.250">`) {
 		t.Fatalf("unexpected testsuite header: %s", text)
 	}
-	if !strings.Contains(text, `<testcase classname="wrkr.accept" name="test_command">`) {
+	if !strings.Contains(text, `<testcase classname="wrkr.accept" name="test_command" time="1.250">`) {
 		t.Fatalf("missing testcase: %s", text)
 	}
+	if !strings.Contains(text, `<testcase classname="wrkr.accept.regex" name="no_println" time="0.000">`) || !strings.Contains(text, "src/b.go:3") {
+		t.Fatalf("missing typed testcase: %s", text)
+	}
+}
+
+func TestWriteJUnitReportsWaivedChecksAsSkipped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accept.junit.xml")
+	err := WriteJUnit(path, []checks.CheckResult{
+		{Name: "lint_command", Passed: false, Message: "failed", ReasonCode: wrkrerrors.EAcceptTestFail,
+			Waivers: []checks.Waiver{{Check: "lint_command", Reason: "flaky rule", Approver: "lead"}}},
+		{Name: "waivers", Passed: true, Message: "1 waiver(s) active"},
+	})
+	if err != nil {
+		t.Fatalf("write junit: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read junit: %v", err)
+	}
+	text := string(raw)
+	if !strings.Contains(text, `tests="2" failures="0" skipped="1"`) || !strings.Contains(text, `<skipped message="waived: flaky rule">`) || strings.Contains(text, "<failure") {
+		t.Fatalf("expected waived check reported as skipped: %s", text)
+	}
 }
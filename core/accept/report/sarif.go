@@ -0,0 +1,164 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+	"github.com/davidahmann/wrkr/core/fsx"
+)
+
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "wrkr-accept"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	Properties   map[string]any     `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// buildSARIF maps failed checks that report file locations or an artifact to
+// SARIF results, one per location. Waived failures carry an accepted external
+// suppression. Other checks have nothing for code scanning to point at and
+// are left to JUnit.
+func buildSARIF(toolVersion string, checkResults []checks.CheckResult) sarifLog {
+	driver := sarifDriver{Name: sarifToolName, Version: toolVersion, Rules: []sarifRule{}}
+	results := []sarifResult{}
+	for _, check := range checkResults {
+		locations := sarifLocations(check)
+		if check.Passed || len(locations) == 0 {
+			continue
+		}
+		ruleIndex := len(driver.Rules)
+		description := "wrkr acceptance check " + check.Name
+		if check.Type != "" {
+			description += " (" + check.Type + ")"
+		}
+		driver.Rules = append(driver.Rules, sarifRule{ID: check.Name, ShortDescription: sarifMessage{Text: description}})
+
+		base := sarifResult{
+			RuleID:    check.Name,
+			RuleIndex: ruleIndex,
+			Level:     "error",
+			Message:   sarifMessage{Text: check.Message},
+			Properties: map[string]any{
+				"reason_code": string(check.ReasonCode),
+			},
+		}
+		if check.Waived() {
+			base.Suppressions = []sarifSuppression{{Kind: "external", Status: "accepted", Justification: waiverReasons(check.Waivers)}}
+		}
+		for _, loc := range locations {
+			result := base
+			physical := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: loc.Path}}
+			if loc.Line > 0 {
+				physical.Region = &sarifRegion{StartLine: loc.Line}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: physical}}
+			results = append(results, result)
+		}
+	}
+	return sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// sarifLocations returns the paths a failure reports: its locations, else its
+// artifact. Code scanning rejects results without a location, so checks with
+// neither are left out.
+func sarifLocations(check checks.CheckResult) []checks.Location {
+	if len(check.Locations) > 0 {
+		return check.Locations
+	}
+	if check.Artifact != "" {
+		return []checks.Location{{Path: check.Artifact}}
+	}
+	return nil
+}
+
+// WriteSARIF writes the SARIF 2.1.0 log for checkResults to path.
+func WriteSARIF(path, toolVersion string, checkResults []checks.CheckResult) error {
+	if path == "" {
+		return fmt.Errorf("empty sarif path")
+	}
+	raw, err := json.MarshalIndent(buildSARIF(toolVersion, checkResults), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal sarif: %w", err)
+	}
+	return writeReport("sarif", path, append(raw, '\n'))
+}
+
+// writeReport writes a report file at the caller-supplied path.
+func writeReport(kind, path string, content []byte) error {
+	resolved, err := fsx.NormalizeAbsolutePath(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("resolve %s path: %w", kind, err)
+	}
+	if err := fsx.AtomicWriteFile(resolved, content, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", kind, err)
+	}
+	return nil
+}
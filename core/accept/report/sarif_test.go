@@ -0,0 +1,95 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+)
+
+func TestWriteSARIFMapsLocatedFailures(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "accept.sarif")
+	err := WriteSARIF(path, "test", []checks.CheckResult{
+		{Name: "schema_validity", Passed: true, Message: "ok"},
+		{Name: "path_constraints", Passed: false, Message: "forbidden", ReasonCode: wrkrerrors.EAcceptMissingArtifact,
+			Locations: []checks.Location{{Path: "secrets/a.txt"}, {Path: "secrets/b.txt"}}},
+		{Name: "test_command", Passed: false, Message: "failed", ReasonCode: wrkrerrors.EAcceptTestFail},
+		{Name: "path_constraints", Passed: false, Message: "artifact path count 9 exceeds max_artifact_paths=5", ReasonCode: wrkrerrors.EAcceptMissingArtifact},
+		{Name: "no_println", Type: checks.TypeRegex, Passed: false, Message: "found", ReasonCode: wrkrerrors.EAcceptTestFail,
+			Locations: []checks.Location{{Path: "src/b.go", Line: 3}}},
+	})
+	if err != nil {
+		t.Fatalf("write sarif: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sarif: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(raw, &log); err != nil {
+		t.Fatalf("decode sarif: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected sarif envelope: %s", raw)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[1].ID != "no_println" {
+		t.Fatalf("expected rules for located failures only, got %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected one result per location, got %+v", run.Results)
+	}
+	last := run.Results[2]
+	if last.RuleID != "no_println" || last.RuleIndex != 1 ||
+		last.Locations[0].PhysicalLocation.ArtifactLocation.URI != "src/b.go" || last.Locations[0].PhysicalLocation.Region.StartLine != 3 {
+		t.Fatalf("unexpected regex result: %+v", last)
+	}
+	if run.Results[0].Locations[0].PhysicalLocation.Region != nil {
+		t.Fatalf("expected no region for path-only location: %+v", run.Results[0])
+	}
+	for _, result := range run.Results {
+		if len(result.Locations) == 0 {
+			t.Fatalf("expected every result to carry a location: %+v", result)
+		}
+	}
+}
+
+func TestBuildSARIFLocatesArtifactFailures(t *testing.T) {
+	t.Parallel()
+
+	log := buildSARIF("test", []checks.CheckResult{
+		{Name: "required_artifacts", Passed: false, Message: "missing", ReasonCode: wrkrerrors.EAcceptMissingArtifact, Artifact: "reports/a.md"},
+	})
+	results := log.Runs[0].Results
+	if len(results) != 1 || results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "reports/a.md" {
+		t.Fatalf("expected artifact as location, got %+v", results)
+	}
+}
+
+func TestBuildSARIFSuppressesWaivedFailures(t *testing.T) {
+	t.Parallel()
+
+	log := buildSARIF("test", []checks.CheckResult{
+		{Name: "no_println", Type: checks.TypeRegex, Passed: false, Message: "found", ReasonCode: wrkrerrors.EAcceptTestFail,
+			Locations: []checks.Location{{Path: "vendor/a.go", Line: 1}},
+			Waivers:   []checks.Waiver{{Check: "no_println", Artifact: "vendor/**", Reason: "vendored code", Approver: "lead"}}},
+		{Name: "no_todos", Type: checks.TypeNoNewTODOs, Passed: false, Message: "found", ReasonCode: wrkrerrors.EAcceptTestFail,
+			Locations: []checks.Location{{Path: "src/b.go", Line: 2}}},
+	})
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("expected waived and failed results, got %+v", results)
+	}
+	suppressions := results[0].Suppressions
+	if len(suppressions) != 1 || suppressions[0].Kind != "external" || suppressions[0].Status != "accepted" || suppressions[0].Justification != "vendored code" {
+		t.Fatalf("expected accepted suppression on waived result, got %+v", results[0])
+	}
+	if len(results[1].Suppressions) != 0 {
+		t.Fatalf("unexpected suppression on failed result: %+v", results[1])
+	}
+}
@@ -21,6 +21,7 @@ type RunOptions struct {
 	Now             func() time.Time
 	ProducerVersion string
 	ConfigPath      string
+	WaiversPath     string
 	WorkDir         string
 }
 
@@ -70,22 +71,50 @@ func Run(jobID string, opts RunOptions) (RunResult, error) {
 		return RunResult{}, err
 	}
 
-	cfg, configPath, err := LoadConfigOrDefault(opts.ConfigPath)
+	resolved, err := ResolveConfig(opts.ConfigPath, state.Acceptance)
+	if err != nil {
+		return RunResult{}, err
+	}
+	effective, err := configMap(resolved.Config)
+	if err != nil {
+		return RunResult{}, err
+	}
+	waivers, waiversPath, err := LoadWaivers(opts.WaiversPath)
 	if err != nil {
 		return RunResult{}, err
 	}
 
-	checkResults, err := checks.Run(cfg.ToChecksConfig(), checks.Input{
-		StatusResponse: statusview.FromRunnerState(state, producerVersion, now()),
-		Checkpoints:    checkpoints,
-		Approvals:      approvals,
-		WorkDir:        opts.WorkDir,
+	checkResults, err := checks.Run(resolved.Config.ToChecksConfig(), checks.Input{
+		StatusResponse:   statusview.FromRunnerState(state, producerVersion, now()),
+		Checkpoints:      checkpoints,
+		Approvals:        approvals,
+		CheckpointPolicy: state.CheckpointPolicy,
+		WorkDir:          opts.WorkDir,
 	})
 	if err != nil {
 		return RunResult{}, err
 	}
+	evaluatedAt := now()
+	if waiversPath != "" {
+		checkResults = checks.ApplyWaivers(checkResults, waivers, evaluatedAt)
+	}
+
+	rules, err := s.Redaction(jobID)
+	if err != nil {
+		return RunResult{}, err
+	}
+	logs, err := writeCheckLogs(s.JobDir(jobID), checkResults, rules)
+	if err != nil {
+		return RunResult{}, err
+	}
 
-	acceptResult := buildAcceptanceResult(jobID, producerVersion, now().UTC(), checkResults)
+	acceptResult := buildAcceptanceResult(jobID, producerVersion, now().UTC(), checkResults, logs)
+	acceptResult.Config = effective
+	acceptResult.Sources = resolved.Sources
+	if waiversPath != "" {
+		acceptResult.WaiversPath = waiversPath
+		acceptResult.Waivers = waiverRecords(waivers, evaluatedAt)
+	}
 	raw, err := json.Marshal(acceptResult)
 	if err != nil {
 		return RunResult{}, fmt.Errorf("marshal acceptance result: %w", err)
@@ -105,7 +134,7 @@ func Run(jobID string, opts RunOptions) (RunResult, error) {
 	}
 
 	return RunResult{
-		ConfigPath:  configPath,
+		ConfigPath:  resolved.ConfigPath,
 		ResultPath:  resultPath,
 		Result:      acceptResult,
 		CheckResult: checkResults,
@@ -117,31 +146,60 @@ func Failed(result v1.AcceptanceResult) bool {
 }
 
 func FailureCode(result v1.AcceptanceResult) wrkrerrors.Code {
+	checkpointMissing, expired := false, false
 	for _, code := range result.ReasonCodes {
-		if wrkrerrors.Code(code) == wrkrerrors.EAcceptTestFail {
+		switch wrkrerrors.Code(code) {
+		case wrkrerrors.EAcceptTestFail:
 			return wrkrerrors.EAcceptTestFail
+		case wrkrerrors.EAcceptCheckpointMissing:
+			checkpointMissing = true
+		case wrkrerrors.EAcceptWaiverExpired:
+			expired = true
 		}
 	}
+	if checkpointMissing {
+		return wrkrerrors.EAcceptCheckpointMissing
+	}
+	if expired {
+		return wrkrerrors.EAcceptWaiverExpired
+	}
 	return wrkrerrors.EAcceptMissingArtifact
 }
 
-func buildAcceptanceResult(jobID, producerVersion string, createdAt time.Time, checkResults []checks.CheckResult) v1.AcceptanceResult {
+func buildAcceptanceResult(jobID, producerVersion string, createdAt time.Time, checkResults []checks.CheckResult, logs map[string][]string) v1.AcceptanceResult {
+	checksOut := make([]v1.AcceptanceCheck, 0, len(checkResults))
 	failures := make([]v1.AcceptanceFailure, 0, len(checkResults))
+	var waived []v1.AcceptanceFailure
 	reasonCodes := make([]string, 0, len(checkResults))
 	seenReason := map[string]struct{}{}
 	passed := 0
 
 	for _, check := range checkResults {
+		checksOut = append(checksOut, v1.AcceptanceCheck{
+			Name:       check.Name,
+			Type:       check.Type,
+			Passed:     check.Passed,
+			Message:    check.Message,
+			ReasonCode: string(check.ReasonCode),
+			DurationMS: check.DurationMS,
+			Logs:       logs[check.Name],
+			Waived:     check.Waived(),
+		})
 		if check.Passed {
 			passed++
 			continue
 		}
 
-		failures = append(failures, v1.AcceptanceFailure{
+		failure := v1.AcceptanceFailure{
 			Check:    check.Name,
 			Message:  check.Message,
 			Artifact: check.Artifact,
-		})
+		}
+		if check.Waived() {
+			waived = append(waived, failure)
+			continue
+		}
+		failures = append(failures, failure)
 
 		if check.ReasonCode != "" {
 			code := string(check.ReasonCode)
@@ -169,11 +227,28 @@ func buildAcceptanceResult(jobID, producerVersion string, createdAt time.Time, c
 		JobID:        jobID,
 		ChecksRun:    len(checkResults),
 		ChecksPassed: passed,
+		Checks:       checksOut,
 		Failures:     failures,
+		Waived:       waived,
 		ReasonCodes:  reasonCodes,
 	}
 }
 
+func waiverRecords(waivers []checks.Waiver, now time.Time) []v1.AcceptanceWaiver {
+	out := make([]v1.AcceptanceWaiver, 0, len(waivers))
+	for _, w := range waivers {
+		out = append(out, v1.AcceptanceWaiver{
+			Check:    w.Check,
+			Artifact: w.Artifact,
+			Reason:   w.Reason,
+			Approver: w.Approver,
+			Expires:  w.Expires.UTC(),
+			Expired:  w.Expired(now),
+		})
+	}
+	return out
+}
+
 func canonicalizeJSON(v any) ([]byte, error) {
 	raw, err := json.Marshal(v)
 	if err != nil {
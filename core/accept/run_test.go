@@ -38,13 +38,21 @@ func TestRunWritesAcceptanceResult(t *testing.T) {
 schema_version: v1
 required_artifacts:
   - reports/out.md
-test_command: "true"
+test_command: "echo full-test-output"
 lint_command: "true"
+execution:
+  timeout_seconds: 60
+  max_parallel: 2
 path_rules:
   max_artifact_paths: 10
   forbidden_prefixes: []
   allowed_prefixes:
     - reports/
+checks:
+  - name: no_secrets
+    type: regex
+    paths: ["**/*.md"]
+    must_not_match: "BEGIN PRIVATE KEY"
 `), 0o600); err != nil {
 		t.Fatalf("write config: %v", err)
 	}
@@ -72,6 +80,21 @@ path_rules:
 	if decoded["job_id"] != "job_accept_run" {
 		t.Fatalf("expected job_accept_run in accept_result, got %v", decoded["job_id"])
 	}
+	recorded := result.Result.Checks
+	if len(recorded) != result.Result.ChecksRun || recorded[len(recorded)-1].Name != "no_secrets" || recorded[len(recorded)-1].Type != "regex" {
+		t.Fatalf("expected every check recorded in accept_result, got %+v", recorded)
+	}
+	testCheck := recorded[4]
+	if testCheck.Name != "test_command" || len(testCheck.Logs) != 2 || testCheck.Logs[0] != "accept/test_command.stdout.log" {
+		t.Fatalf("expected test_command logs recorded, got %+v", testCheck)
+	}
+	logRaw, err := os.ReadFile(filepath.Join(filepath.Dir(result.ResultPath), filepath.FromSlash(testCheck.Logs[0])))
+	if err != nil {
+		t.Fatalf("read test_command log: %v", err)
+	}
+	if string(logRaw) != "full-test-output\n" {
+		t.Fatalf("unexpected test_command log: %q", logRaw)
+	}
 }
 
 func TestRunFailureCode(t *testing.T) {
@@ -119,6 +142,83 @@ path_rules:
 	}
 }
 
+func TestRunAppliesWaivers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workspace := t.TempDir()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(workspace); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(orig)
+	})
+	now := time.Date(2026, 2, 13, 21, 0, 0, 0, time.UTC)
+
+	setupAcceptJob(t, "job_accept_waived", now)
+
+	if err := os.WriteFile(filepath.Join(workspace, "accept.yaml"), []byte(`schema_id: wrkr.accept_config
+schema_version: v1
+required_artifacts:
+  - reports/missing.md
+test_command: "true"
+lint_command: "true"
+path_rules:
+  max_artifact_paths: 10
+  forbidden_prefixes: []
+  allowed_prefixes: []
+`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, DefaultWaiversPath), []byte(`schema_id: wrkr.accept_waivers
+schema_version: v1
+waivers:
+  - check: required_artifacts
+    path: reports/missing.md
+    reason: report excluded for this release
+    approver: release-lead
+    expires: 2026-02-13
+`), 0o600); err != nil {
+		t.Fatalf("write waivers: %v", err)
+	}
+
+	result, err := Run("job_accept_waived", RunOptions{Now: func() time.Time { return now }, ProducerVersion: "test", WorkDir: workspace})
+	if err != nil {
+		t.Fatalf("run accept: %v", err)
+	}
+	if Failed(result.Result) {
+		t.Fatalf("expected waived failure not to fail acceptance: %+v", result.Result)
+	}
+	if len(result.Result.Waived) != 1 || result.Result.Waived[0].Check != "required_artifacts" || len(result.Result.ReasonCodes) != 0 {
+		t.Fatalf("expected required_artifacts reported as waived, got %+v", result.Result)
+	}
+	if len(result.Result.Waivers) != 1 || result.Result.Waivers[0].Expired || result.Result.WaiversPath == "" {
+		t.Fatalf("expected waivers recorded in accept_result, got %+v", result.Result.Waivers)
+	}
+	raw, err := os.ReadFile(result.ResultPath)
+	if err != nil {
+		t.Fatalf("read accept_result: %v", err)
+	}
+	if err := validate.ValidateBytes(validate.AcceptResultSchemaRel, raw); err != nil {
+		t.Fatalf("accept_result schema invalid: %v", err)
+	}
+
+	expired := now.Add(3 * time.Hour)
+	result, err = Run("job_accept_waived", RunOptions{Now: func() time.Time { return expired }, ProducerVersion: "test", WorkDir: workspace})
+	if err != nil {
+		t.Fatalf("run accept after expiry: %v", err)
+	}
+	if !Failed(result.Result) || len(result.Result.Waived) != 0 || len(result.Result.Failures) != 2 {
+		t.Fatalf("expected expired waiver to fail acceptance, got %+v", result.Result)
+	}
+	if got := FailureCode(result.Result); got != wrkrerrors.EAcceptWaiverExpired {
+		t.Fatalf("expected E_ACCEPT_WAIVER_EXPIRED, got %s", got)
+	}
+}
+
 func setupAcceptJob(t *testing.T, jobID string, now time.Time) {
 	t.Helper()
 
@@ -0,0 +1,118 @@
+package accept
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/accept/checks"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultWaiversPath = "accept_waivers.yaml"
+	waiversSchemaID    = "wrkr.accept_waivers"
+)
+
+type waiversFile struct {
+	SchemaID      string        `yaml:"schema_id"`
+	SchemaVersion string        `yaml:"schema_version"`
+	Waivers       []waiverEntry `yaml:"waivers"`
+}
+
+// waiverEntry is one waiver as written. artifact and path are aliases;
+// expires is a date (valid through that UTC day) or an RFC 3339 time.
+type waiverEntry struct {
+	Check    string `yaml:"check"`
+	Artifact string `yaml:"artifact"`
+	Path     string `yaml:"path"`
+	Reason   string `yaml:"reason"`
+	Approver string `yaml:"approver"`
+	Expires  string `yaml:"expires"`
+}
+
+// LoadWaivers reads the waivers file at path. An empty path reads
+// accept_waivers.yaml when it exists; with no file the returned path is empty.
+func LoadWaivers(path string) ([]checks.Waiver, string, error) {
+	explicit := strings.TrimSpace(path) != ""
+	if !explicit {
+		path = DefaultWaiversPath
+	}
+	resolved, err := resolveConfigPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	root, err := os.OpenRoot(filepath.Dir(resolved))
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	defer func() { _ = root.Close() }()
+	raw, err := root.ReadFile(filepath.Base(resolved))
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	var file waiversFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, "", fmt.Errorf("decode waivers yaml: %w", err)
+	}
+	if file.SchemaID != "" && file.SchemaID != waiversSchemaID {
+		return nil, "", wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "waivers file has unexpected schema_id", map[string]any{"schema_id": file.SchemaID, "path": resolved})
+	}
+	waivers := make([]checks.Waiver, 0, len(file.Waivers))
+	for i, entry := range file.Waivers {
+		waiver, err := entry.toWaiver()
+		if err != nil {
+			return nil, "", wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid acceptance waiver: "+err.Error(), map[string]any{"index": i, "check": entry.Check, "path": resolved})
+		}
+		waivers = append(waivers, waiver)
+	}
+	if err := checks.ValidateWaivers(waivers); err != nil {
+		return nil, "", err
+	}
+	return waivers, resolved, nil
+}
+
+func (e waiverEntry) toWaiver() (checks.Waiver, error) {
+	artifact, path := strings.TrimSpace(e.Artifact), strings.TrimSpace(e.Path)
+	if artifact != "" && path != "" {
+		return checks.Waiver{}, fmt.Errorf("set artifact or path, not both")
+	}
+	if artifact == "" {
+		artifact = path
+	}
+	expires, err := parseExpiry(strings.TrimSpace(e.Expires))
+	if err != nil {
+		return checks.Waiver{}, err
+	}
+	return checks.Waiver{
+		Check:    strings.TrimSpace(e.Check),
+		Artifact: artifact,
+		Reason:   strings.TrimSpace(e.Reason),
+		Approver: strings.TrimSpace(e.Approver),
+		Expires:  expires,
+	}, nil
+}
+
+func parseExpiry(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day.Add(24 * time.Hour), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expires %q must be YYYY-MM-DD or RFC 3339", value)
+	}
+	return at.UTC(), nil
+}
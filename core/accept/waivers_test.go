@@ -0,0 +1,76 @@
+package accept
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadWaivers(t *testing.T) {
+	workspace := t.TempDir()
+	path := filepath.Join(workspace, "waivers.yaml")
+	if err := os.WriteFile(path, []byte(`schema_id: wrkr.accept_waivers
+schema_version: v1
+waivers:
+  - check: lint_command
+    reason: flaky rule
+    approver: lead
+    expires: 2026-03-01
+  - check: required_artifacts
+    artifact: reports/*.md
+    reason: excluded
+    approver: lead
+    expires: 2026-03-01T12:00:00+02:00
+`), 0o600); err != nil {
+		t.Fatalf("write waivers: %v", err)
+	}
+
+	waivers, loaded, err := LoadWaivers(path)
+	if err != nil {
+		t.Fatalf("LoadWaivers: %v", err)
+	}
+	if loaded != path || len(waivers) != 2 {
+		t.Fatalf("unexpected waivers from %q: %+v", loaded, waivers)
+	}
+	if !waivers[0].Expires.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected date expiry to cover the whole day, got %s", waivers[0].Expires)
+	}
+	if !waivers[1].Expires.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) || waivers[1].Artifact != "reports/*.md" {
+		t.Fatalf("unexpected artifact waiver: %+v", waivers[1])
+	}
+}
+
+func TestLoadWaiversMissingAndInvalid(t *testing.T) {
+	workspace := t.TempDir()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(workspace); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(orig)
+	})
+
+	if waivers, loaded, err := LoadWaivers(""); err != nil || loaded != "" || len(waivers) != 0 {
+		t.Fatalf("expected no waivers without a default file, got %v %q %v", waivers, loaded, err)
+	}
+	if _, _, err := LoadWaivers("missing.yaml"); err == nil {
+		t.Fatal("expected error for missing explicit waivers file")
+	}
+
+	for name, body := range map[string]string{
+		"both.yaml": "waivers:\n  - check: required_artifacts\n    artifact: a.md\n    path: b.md\n    reason: r\n    approver: a\n    expires: 2026-03-01\n",
+		"date.yaml": "waivers:\n  - check: lint_command\n    reason: r\n    approver: a\n    expires: next week\n",
+		"none.yaml": "waivers:\n  - check: lint_command\n    reason: r\n    approver: a\n",
+	} {
+		if err := os.WriteFile(filepath.Join(workspace, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, _, err := LoadWaivers(name); err == nil {
+			t.Fatalf("expected %s to be rejected", name)
+		}
+	}
+}
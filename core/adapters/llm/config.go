@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxTurns = 32
+	defaultTimeout  = 60 * time.Second
+)
+
+// Tool is a function the model may call. The command runs with the call
+// arguments as JSON on stdin and in WRKR_TOOL_ARGS; stdout is the result.
+type Tool struct {
+	Name             string
+	Description      string
+	Command          string
+	Parameters       map[string]any
+	RequiresApproval bool
+}
+
+// Config is read from adapter.config in the jobspec.
+type Config struct {
+	BaseURL         string
+	Model           string
+	APIKeyEnv       string
+	SystemPrompt    string
+	Prompt          string
+	MaxTurns        int
+	Timeout         time.Duration
+	Tools           []Tool
+	InputCostPer1K  float64
+	OutputCostPer1K float64
+}
+
+func ConfigFromAdapter(config map[string]any, inputs map[string]any) (Config, error) {
+	cfg := Config{
+		BaseURL:      strings.TrimRight(strings.TrimSpace(stringField(config, "base_url")), "/"),
+		Model:        strings.TrimSpace(stringField(config, "model")),
+		APIKeyEnv:    strings.TrimSpace(stringField(config, "api_key_env")),
+		SystemPrompt: stringField(config, "system_prompt"),
+		Prompt:       stringField(config, "prompt"),
+		MaxTurns:     defaultMaxTurns,
+		Timeout:      defaultTimeout,
+	}
+	if prompt := stringField(inputs, "prompt"); strings.TrimSpace(prompt) != "" {
+		cfg.Prompt = prompt
+	}
+	if cfg.BaseURL == "" {
+		return Config{}, fmt.Errorf("llm adapter requires adapter.config.base_url")
+	}
+	if cfg.Model == "" {
+		return Config{}, fmt.Errorf("llm adapter requires adapter.config.model")
+	}
+
+	if raw, ok := config["max_turns"]; ok {
+		value, ok := intValue(raw)
+		if !ok || value <= 0 {
+			return Config{}, fmt.Errorf("adapter.config.max_turns must be a positive integer")
+		}
+		cfg.MaxTurns = value
+	}
+	if raw, ok := config["timeout_seconds"]; ok {
+		value, ok := intValue(raw)
+		if !ok || value <= 0 {
+			return Config{}, fmt.Errorf("adapter.config.timeout_seconds must be a positive integer")
+		}
+		cfg.Timeout = time.Duration(value) * time.Second
+	}
+
+	if raw, ok := config["pricing"]; ok {
+		pricing, ok := raw.(map[string]any)
+		if !ok {
+			return Config{}, fmt.Errorf("adapter.config.pricing must be an object")
+		}
+		var err error
+		if cfg.InputCostPer1K, err = costField(pricing, "input_per_1k_tokens"); err != nil {
+			return Config{}, err
+		}
+		if cfg.OutputCostPer1K, err = costField(pricing, "output_per_1k_tokens"); err != nil {
+			return Config{}, err
+		}
+	}
+
+	tools, err := toolsField(config["tools"])
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Tools = tools
+	return cfg, nil
+}
+
+func toolsField(raw any) ([]Tool, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("adapter.config.tools must be a list")
+	}
+	seen := map[string]struct{}{approvalToolName: {}}
+	tools := make([]Tool, 0, len(list))
+	for i, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("adapter.config.tools[%d] must be an object", i)
+		}
+		tool := Tool{
+			Name:        strings.TrimSpace(stringField(obj, "name")),
+			Description: stringField(obj, "description"),
+			Command:     stringField(obj, "command"),
+		}
+		if tool.Name == "" || strings.TrimSpace(tool.Command) == "" {
+			return nil, fmt.Errorf("adapter.config.tools[%d] requires name and command", i)
+		}
+		if _, exists := seen[tool.Name]; exists {
+			return nil, fmt.Errorf("adapter.config.tools[%d] duplicate or reserved name %q", i, tool.Name)
+		}
+		seen[tool.Name] = struct{}{}
+		if params, ok := obj["parameters"].(map[string]any); ok {
+			tool.Parameters = params
+		}
+		if approval, ok := obj["requires_approval"].(bool); ok {
+			tool.RequiresApproval = approval
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+func costField(obj map[string]any, key string) (float64, error) {
+	raw, ok := obj[key]
+	if !ok {
+		return 0, nil
+	}
+	var value float64
+	switch v := raw.(type) {
+	case float64:
+		value = v
+	case int:
+		value = float64(v)
+	case int64:
+		value = float64(v)
+	default:
+		return 0, fmt.Errorf("adapter.config.pricing.%s must be a number", key)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("adapter.config.pricing.%s must be non-negative", key)
+	}
+	return value, nil
+}
+
+func stringField(obj map[string]any, key string) string {
+	value, _ := obj[key].(string)
+	return value
+}
+
+func intValue(raw any) (int, bool) {
+	switch v := raw.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
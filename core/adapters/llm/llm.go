@@ -0,0 +1,613 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/pricing"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/sandbox"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+	"github.com/davidahmann/wrkr/core/textx"
+)
+
+const (
+	approvalToolName   = "request_approval"
+	maxToolOutputBytes = 16 << 10
+	maxSummaryLength   = 2000
+)
+
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// State is the durable conversation of an llm run. A resume replays nothing:
+// it answers the tool calls still open on the last assistant message and then
+// asks the model for the next turn.
+type State struct {
+	Messages            []Message `json:"messages"`
+	Turns               int       `json:"turns"`
+	PendingToolCallID   string    `json:"pending_tool_call_id,omitempty"`
+	PendingCheckpointID string    `json:"pending_checkpoint_id,omitempty"`
+}
+
+type RunOptions struct {
+	Now          func() time.Time
+	State        State
+	Workspace    string
+	BudgetLimits budget.Limits
+	HTTPClient   *http.Client
+	OnState      func(state State) error
+	Sandbox      *sandbox.Sandbox
+	// PriceTable, when set, prices usage instead of adapter.config.pricing.
+	PriceTable *pricing.Table
+}
+
+type RunResult struct {
+	Status       queue.Status `json:"status"`
+	Turns        int          `json:"turns"`
+	FinalMessage string       `json:"final_message,omitempty"`
+	DecisionTool string       `json:"decision_tool,omitempty"`
+}
+
+type agent struct {
+	jobID  string
+	cfg    Config
+	opts   RunOptions
+	now    func() time.Time
+	store  *store.LocalStore
+	runner *runner.Runner
+	client *http.Client
+	state  State
+	tools  map[string]Tool
+}
+
+func Run(jobID string, cfg Config, opts RunOptions) (RunResult, error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	if cfg.MaxTurns <= 0 {
+		cfg.MaxTurns = defaultMaxTurns
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if strings.TrimSpace(cfg.BaseURL) == "" || strings.TrimSpace(cfg.Model) == "" {
+		return RunResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "llm adapter requires base_url and model", nil)
+	}
+	if opts.PriceTable != nil {
+		if _, err := opts.PriceTable.Cost(cfg.Model, 0, 0); err != nil {
+			return RunResult{}, err
+		}
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		return RunResult{}, err
+	}
+	r, err := runner.New(s, runner.Options{Now: now})
+	if err != nil {
+		return RunResult{}, err
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	ag := &agent{
+		jobID:  jobID,
+		cfg:    cfg,
+		opts:   opts,
+		now:    now,
+		store:  s,
+		runner: r,
+		client: client,
+		state:  opts.State,
+		tools:  make(map[string]Tool, len(cfg.Tools)),
+	}
+	for _, tool := range cfg.Tools {
+		ag.tools[tool.Name] = tool
+	}
+
+	if len(ag.state.Messages) == 0 {
+		if strings.TrimSpace(cfg.Prompt) == "" {
+			return RunResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "llm adapter requires inputs.prompt or adapter.config.prompt", nil)
+		}
+		if strings.TrimSpace(cfg.SystemPrompt) != "" {
+			ag.state.Messages = append(ag.state.Messages, Message{Role: "system", Content: cfg.SystemPrompt})
+		}
+		ag.state.Messages = append(ag.state.Messages, Message{Role: "user", Content: cfg.Prompt})
+		if err := ag.save(); err != nil {
+			return RunResult{}, err
+		}
+	}
+
+	for {
+		if _, err := r.CheckBudget(jobID, opts.BudgetLimits); err != nil {
+			return ag.result(runner.BudgetStopStatus(err)), err
+		}
+
+		if pending := ag.openToolCalls(); len(pending) > 0 {
+			blocked, err := ag.answerToolCalls(pending)
+			if err != nil {
+				return ag.result(queue.StatusBlockedError), err
+			}
+			if blocked != nil {
+				return *blocked, nil
+			}
+			continue
+		}
+
+		last := ag.state.Messages[len(ag.state.Messages)-1]
+		if last.Role == "assistant" {
+			return ag.complete(last.Content)
+		}
+		if ag.state.Turns >= cfg.MaxTurns {
+			return ag.result(queue.StatusBlockedError), ag.block(
+				fmt.Sprintf("llm adapter reached max_turns=%d without a final answer", cfg.MaxTurns),
+				wrkrerrors.New(wrkrerrors.EAdapterFail, "llm adapter exceeded max_turns", map[string]any{"job_id": jobID, "max_turns": cfg.MaxTurns}),
+			)
+		}
+		if err := ag.turn(); err != nil {
+			return ag.result(queue.StatusBlockedError), err
+		}
+	}
+}
+
+func (ag *agent) result(status queue.Status) RunResult {
+	return RunResult{Status: status, Turns: ag.state.Turns}
+}
+
+func (ag *agent) save() error {
+	if ag.opts.OnState == nil {
+		return nil
+	}
+	return ag.opts.OnState(ag.state)
+}
+
+func (ag *agent) complete(content string) (RunResult, error) {
+	summary := "llm adapter completed"
+	if trimmed := strings.TrimSpace(content); trimmed != "" {
+		summary = textx.Truncate(summary+": "+trimmed, maxSummaryLength)
+	}
+	_, _ = ag.runner.EmitCheckpoint(ag.jobID, runner.CheckpointInput{
+		Type:    "completed",
+		Summary: summary,
+		Status:  queue.StatusCompleted,
+	})
+	if _, err := ag.runner.ChangeStatus(ag.jobID, queue.StatusCompleted); err != nil {
+		return RunResult{}, err
+	}
+	result := ag.result(queue.StatusCompleted)
+	result.FinalMessage = content
+	return result, nil
+}
+
+func (ag *agent) block(summary string, cause error) error {
+	_, _ = ag.runner.EmitCheckpoint(ag.jobID, runner.CheckpointInput{
+		Type:        "blocked",
+		Summary:     textx.Truncate(summary, maxSummaryLength),
+		Status:      queue.StatusBlockedError,
+		ReasonCodes: []string{string(wrkrerrors.EAdapterFail)},
+	})
+	_, _ = ag.runner.ChangeStatus(ag.jobID, queue.StatusBlockedError)
+	return cause
+}
+
+// turn asks the model for one completion, meters its usage and appends the
+// assistant message to the conversation.
+func (ag *agent) turn() error {
+	resp, err := ag.chat()
+	if err != nil {
+		return ag.block("llm request failed: "+err.Error(), err)
+	}
+	if len(resp.Choices) == 0 {
+		cause := wrkrerrors.New(wrkrerrors.EAdapterFail, "llm response has no choices", map[string]any{"job_id": ag.jobID})
+		return ag.block("llm response has no choices", cause)
+	}
+	choice := resp.Choices[0]
+	message := choice.Message
+	message.Role = "assistant"
+	ag.state.Turns++
+
+	cost := float64(resp.Usage.PromptTokens)/1000*ag.cfg.InputCostPer1K +
+		float64(resp.Usage.CompletionTokens)/1000*ag.cfg.OutputCostPer1K
+	if ag.opts.PriceTable != nil {
+		if cost, err = ag.opts.PriceTable.Cost(ag.cfg.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens); err != nil {
+			return err
+		}
+	}
+	if _, err := ag.runner.RecordUsage(ag.jobID, runner.Usage{
+		Model:         ag.cfg.Model,
+		TokensIn:      resp.Usage.PromptTokens,
+		TokensOut:     resp.Usage.CompletionTokens,
+		EstimatedCost: cost,
+	}); err != nil {
+		return err
+	}
+
+	toolNames := make([]string, 0, len(message.ToolCalls))
+	for _, call := range message.ToolCalls {
+		toolNames = append(toolNames, call.Function.Name)
+	}
+	if _, err := ag.store.AppendEvent(ag.jobID, "adapter_step", map[string]any{
+		"adapter":       "llm",
+		"kind":          "model_turn",
+		"turn":          ag.state.Turns,
+		"model":         ag.cfg.Model,
+		"finish_reason": choice.FinishReason,
+		"tool_calls":    toolNames,
+		"tokens_in":     resp.Usage.PromptTokens,
+		"tokens_out":    resp.Usage.CompletionTokens,
+	}, ag.now()); err != nil {
+		return err
+	}
+	if err := ag.count(1, 0); err != nil {
+		return err
+	}
+
+	ag.state.Messages = append(ag.state.Messages, message)
+	if err := ag.save(); err != nil {
+		return err
+	}
+	if len(message.ToolCalls) > 0 {
+		_, _ = ag.runner.EmitCheckpoint(ag.jobID, runner.CheckpointInput{
+			Type:    "progress",
+			Summary: fmt.Sprintf("llm turn %d requested %d tool call(s): %s", ag.state.Turns, len(toolNames), strings.Join(toolNames, ", ")),
+			Status:  queue.StatusRunning,
+		})
+	}
+	return nil
+}
+
+// openToolCalls returns tool calls on the last assistant message that have no
+// tool response yet.
+func (ag *agent) openToolCalls() []ToolCall {
+	lastAssistant := -1
+	for i := len(ag.state.Messages) - 1; i >= 0; i-- {
+		if ag.state.Messages[i].Role == "assistant" {
+			lastAssistant = i
+			break
+		}
+	}
+	if lastAssistant < 0 {
+		return nil
+	}
+	answered := map[string]struct{}{}
+	for _, msg := range ag.state.Messages[lastAssistant+1:] {
+		if msg.Role == "tool" {
+			answered[msg.ToolCallID] = struct{}{}
+		}
+	}
+	open := make([]ToolCall, 0, len(ag.state.Messages[lastAssistant].ToolCalls))
+	for _, call := range ag.state.Messages[lastAssistant].ToolCalls {
+		if _, ok := answered[call.ID]; !ok {
+			open = append(open, call)
+		}
+	}
+	return open
+}
+
+// answerToolCalls runs open tool calls in order. A call that needs approval
+// stops the run with a decision-needed checkpoint; once the job is resumed the
+// approval itself becomes the tool result.
+func (ag *agent) answerToolCalls(calls []ToolCall) (*RunResult, error) {
+	for _, call := range calls {
+		if call.ID == ag.state.PendingToolCallID {
+			content, err := ag.approvalResult()
+			if err != nil {
+				return nil, err
+			}
+			if call.Function.Name == approvalToolName {
+				if err := ag.recordToolCall(call, 0); err != nil {
+					return nil, err
+				}
+			} else {
+				output, err := ag.runTool(call, ag.tools[call.Function.Name])
+				if err != nil {
+					return nil, err
+				}
+				content = content + "\n" + output
+			}
+			ag.state.PendingToolCallID = ""
+			ag.state.PendingCheckpointID = ""
+			if err := ag.answer(call, content); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		tool, known := ag.tools[call.Function.Name]
+		if call.Function.Name == approvalToolName || (known && tool.RequiresApproval) {
+			return ag.requestApproval(call)
+		}
+		if !known {
+			if err := ag.recordToolCall(call, -1); err != nil {
+				return nil, err
+			}
+			if err := ag.answer(call, fmt.Sprintf("error: unknown tool %q", call.Function.Name)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		output, err := ag.runTool(call, tool)
+		if err != nil {
+			return nil, err
+		}
+		if err := ag.answer(call, output); err != nil {
+			return nil, err
+		}
+		if _, err := ag.runner.CheckBudget(ag.jobID, ag.opts.BudgetLimits); err != nil {
+			result := ag.result(queue.StatusBlockedBudget)
+			return &result, err
+		}
+	}
+	return nil, nil
+}
+
+func (ag *agent) answer(call ToolCall, content string) error {
+	ag.state.Messages = append(ag.state.Messages, Message{Role: "tool", ToolCallID: call.ID, Content: content})
+	return ag.save()
+}
+
+func (ag *agent) requestApproval(call ToolCall) (*RunResult, error) {
+	summary := fmt.Sprintf("llm requested approval to call %s", call.Function.Name)
+	instructions := "review the requested tool call and approve to continue"
+	if call.Function.Name == approvalToolName {
+		var args struct {
+			Summary string `json:"summary"`
+			Action  string `json:"action"`
+		}
+		_ = json.Unmarshal([]byte(call.Function.Arguments), &args)
+		if strings.TrimSpace(args.Summary) != "" {
+			summary = "llm requested approval: " + strings.TrimSpace(args.Summary)
+		}
+		if strings.TrimSpace(args.Action) != "" {
+			instructions = strings.TrimSpace(args.Action)
+		}
+	}
+
+	cp, err := ag.runner.EmitCheckpoint(ag.jobID, runner.CheckpointInput{
+		Type:    "decision-needed",
+		Summary: textx.Truncate(summary, maxSummaryLength),
+		Status:  queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{
+			Kind:         "approval",
+			Instructions: textx.Truncate(instructions, maxSummaryLength),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ag.state.PendingToolCallID = call.ID
+	ag.state.PendingCheckpointID = cp.CheckpointID
+	if err := ag.save(); err != nil {
+		return nil, err
+	}
+	if _, err := ag.runner.ChangeStatus(ag.jobID, queue.StatusBlockedDecision); err != nil {
+		return nil, err
+	}
+	result := ag.result(queue.StatusBlockedDecision)
+	result.DecisionTool = call.Function.Name
+	return &result, nil
+}
+
+// approvalResult describes the latest approval of the pending checkpoint once
+// it met its quorum and was not rejected.
+func (ag *agent) approvalResult() (string, error) {
+	notApproved := wrkrerrors.New(
+		wrkrerrors.ECheckpointApprovalRequired,
+		"pending llm checkpoint is not approved",
+		map[string]any{"job_id": ag.jobID, "checkpoint_id": ag.state.PendingCheckpointID},
+	)
+	d, err := ag.runner.Decision(ag.jobID, ag.state.PendingCheckpointID)
+	if err != nil {
+		return "", err
+	}
+	if !d.Approved || d.Rejection != nil {
+		return "", notApproved
+	}
+	approvals, err := ag.runner.ListApprovals(ag.jobID)
+	if err != nil {
+		return "", err
+	}
+	for i := len(approvals) - 1; i >= 0; i-- {
+		rec := approvals[i]
+		if rec.CheckpointID != ag.state.PendingCheckpointID {
+			continue
+		}
+		content := "approved by " + rec.ApprovedBy
+		if strings.TrimSpace(rec.Reason) != "" {
+			content += ": " + rec.Reason
+		}
+		return content, nil
+	}
+	return "", notApproved
+}
+
+func (ag *agent) runTool(call ToolCall, tool Tool) (string, error) {
+	args := call.Function.Arguments
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+	cmd, err := ag.opts.Sandbox.Command("sh", "-lc", tool.Command)
+	if err != nil {
+		return "", err
+	}
+	cmd.Dir = ag.opts.Workspace
+	cmd.Stdin = strings.NewReader(args)
+	cmd.Env = ag.opts.Sandbox.Environ([]string{"WRKR_TOOL_NAME=" + tool.Name, "WRKR_TOOL_ARGS=" + args})
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	code := 0
+	if runErr := cmd.Run(); runErr != nil {
+		code = 1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+	}
+	if err := ag.recordToolCall(call, code); err != nil {
+		return "", err
+	}
+
+	output := textx.Truncate(stdout.String(), maxToolOutputBytes)
+	if code != 0 {
+		output = fmt.Sprintf("error: exit=%d\n%s%s", code, output, textx.Truncate(stderr.String(), maxToolOutputBytes))
+	}
+	return output, nil
+}
+
+func (ag *agent) recordToolCall(call ToolCall, exitCode int) error {
+	sum := sha256.Sum256([]byte(call.Function.Arguments))
+	if _, err := ag.store.AppendEvent(ag.jobID, "adapter_step", map[string]any{
+		"adapter":        "llm",
+		"kind":           "tool_call",
+		"turn":           ag.state.Turns,
+		"tool":           call.Function.Name,
+		"call_id":        call.ID,
+		"arguments_hash": hex.EncodeToString(sum[:]),
+		"exit_code":      exitCode,
+	}, ag.now()); err != nil {
+		return err
+	}
+	return ag.count(0, 1)
+}
+
+func (ag *agent) count(steps, toolCalls int) error {
+	_, err := ag.runner.IncrementCounters(ag.jobID, 0, steps, toolCalls)
+	return err
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []Message     `json:"messages"`
+	Tools    []chatToolDef `json:"tools,omitempty"`
+}
+
+type chatToolDef struct {
+	Type     string          `json:"type"`
+	Function chatFunctionDef `json:"function"`
+}
+
+type chatFunctionDef struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (ag *agent) toolDefs() []chatToolDef {
+	defs := make([]chatToolDef, 0, len(ag.cfg.Tools)+1)
+	for _, tool := range ag.cfg.Tools {
+		params := tool.Parameters
+		if params == nil {
+			params = map[string]any{"type": "object"}
+		}
+		defs = append(defs, chatToolDef{
+			Type:     "function",
+			Function: chatFunctionDef{Name: tool.Name, Description: tool.Description, Parameters: params},
+		})
+	}
+	defs = append(defs, chatToolDef{
+		Type: "function",
+		Function: chatFunctionDef{
+			Name:        approvalToolName,
+			Description: "Ask a human operator to approve before continuing.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"summary": map[string]any{"type": "string"},
+					"action":  map[string]any{"type": "string"},
+				},
+				"required": []string{"summary"},
+			},
+		},
+	})
+	return defs
+}
+
+func (ag *agent) chat() (*chatResponse, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:    ag.cfg.Model,
+		Messages: ag.state.Messages,
+		Tools:    ag.toolDefs(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ag.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ag.cfg.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EAdapterFail, "build llm request", map[string]any{"error": err.Error()})
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ag.cfg.APIKeyEnv != "" {
+		if key := os.Getenv(ag.cfg.APIKeyEnv); key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+
+	resp, err := ag.client.Do(req)
+	if err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EAdapterFail, "llm request failed", map[string]any{"job_id": ag.jobID, "error": err.Error()})
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EAdapterFail, "read llm response", map[string]any{"job_id": ag.jobID, "error": err.Error()})
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EAdapterFail,
+			fmt.Sprintf("llm endpoint returned status %d", resp.StatusCode),
+			map[string]any{"job_id": ag.jobID, "status_code": resp.StatusCode},
+		)
+	}
+	var out chatResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EAdapterFail, "decode llm response", map[string]any{"job_id": ag.jobID, "error": err.Error()})
+	}
+	return &out, nil
+}
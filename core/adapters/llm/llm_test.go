@@ -0,0 +1,349 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
+	"github.com/davidahmann/wrkr/core/pricing"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+type mockModel struct {
+	mu        sync.Mutex
+	responses []string
+	requests  []chatRequest
+	auth      []string
+}
+
+func (m *mockModel) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, req)
+			return
+		}
+		var body chatRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.requests = append(m.requests, body)
+		m.auth = append(m.auth, req.Header.Get("Authorization"))
+		if len(m.responses) == 0 {
+			http.Error(w, "no scripted response", http.StatusInternalServerError)
+			return
+		}
+		next := m.responses[0]
+		m.responses = m.responses[1:]
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(next))
+	}
+}
+
+func toolCallResponse(id, name, args string, tokensIn, tokensOut int) string {
+	raw, _ := json.Marshal(map[string]any{
+		"choices": []any{map[string]any{
+			"finish_reason": "tool_calls",
+			"message": map[string]any{
+				"role":    "assistant",
+				"content": nil,
+				"tool_calls": []any{map[string]any{
+					"id":       id,
+					"type":     "function",
+					"function": map[string]any{"name": name, "arguments": args},
+				}},
+			},
+		}},
+		"usage": map[string]any{"prompt_tokens": tokensIn, "completion_tokens": tokensOut},
+	})
+	return string(raw)
+}
+
+func finalResponse(content string, tokensIn, tokensOut int) string {
+	raw, _ := json.Marshal(map[string]any{
+		"choices": []any{map[string]any{
+			"finish_reason": "stop",
+			"message":       map[string]any{"role": "assistant", "content": content},
+		}},
+		"usage": map[string]any{"prompt_tokens": tokensIn, "completion_tokens": tokensOut},
+	})
+	return string(raw)
+}
+
+func setupLLMJob(t *testing.T, jobID string, now time.Time) *runner.Runner {
+	t.Helper()
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	return r
+}
+
+func TestRunToolLoopWithApprovalAndUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("WRKR_TEST_LLM_KEY", "secret-key")
+	now := time.Date(2026, 2, 14, 17, 0, 0, 0, time.UTC)
+	r := setupLLMJob(t, "job_llm_loop", now)
+
+	model := &mockModel{responses: []string{
+		toolCallResponse("call_1", "echo", `{"text":"hi"}`, 100, 20),
+		toolCallResponse("call_2", "request_approval", `{"summary":"ship the change"}`, 120, 10),
+		finalResponse("all done", 150, 5),
+	}}
+	server := httptest.NewServer(model.handler(t))
+	defer server.Close()
+
+	cfg, err := ConfigFromAdapter(map[string]any{
+		"base_url":    server.URL + "/v1",
+		"model":       "test-model",
+		"api_key_env": "WRKR_TEST_LLM_KEY",
+		"tools": []any{
+			map[string]any{"name": "echo", "command": "cat"},
+		},
+		"pricing": map[string]any{"input_per_1k_tokens": 1.0, "output_per_1k_tokens": 2.0},
+	}, map[string]any{"prompt": "say hi"})
+	if err != nil {
+		t.Fatalf("ConfigFromAdapter: %v", err)
+	}
+
+	var saved State
+	opts := RunOptions{
+		Now:       func() time.Time { return now },
+		Workspace: t.TempDir(),
+		OnState: func(state State) error {
+			saved = state
+			return nil
+		},
+	}
+	result, err := Run("job_llm_loop", cfg, opts)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != queue.StatusBlockedDecision || result.DecisionTool != approvalToolName {
+		t.Fatalf("expected approval stop, got %+v", result)
+	}
+	if saved.PendingToolCallID != "call_2" || saved.PendingCheckpointID == "" {
+		t.Fatalf("expected pending approval in state, got %+v", saved)
+	}
+	cp, err := r.GetCheckpoint("job_llm_loop", saved.PendingCheckpointID)
+	if err != nil {
+		t.Fatalf("GetCheckpoint: %v", err)
+	}
+	if cp.Type != "decision-needed" || !strings.Contains(cp.Summary, "ship the change") {
+		t.Fatalf("unexpected decision checkpoint: %+v", cp)
+	}
+
+	if _, err := r.ApproveCheckpoint("job_llm_loop", cp.CheckpointID, "looks good", "alice"); err != nil {
+		t.Fatalf("ApproveCheckpoint: %v", err)
+	}
+	if _, err := r.Resume("job_llm_loop", runner.ResumeInput{}); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	opts.State = saved
+	result, err = Run("job_llm_loop", cfg, opts)
+	if err != nil {
+		t.Fatalf("Run after approval: %v", err)
+	}
+	if result.Status != queue.StatusCompleted || result.FinalMessage != "all done" || result.Turns != 3 {
+		t.Fatalf("unexpected final result: %+v", result)
+	}
+
+	if len(model.requests) != 3 || model.auth[0] != "Bearer secret-key" {
+		t.Fatalf("unexpected requests: %d auth=%v", len(model.requests), model.auth)
+	}
+	second := model.requests[1].Messages
+	if toolMsg := second[len(second)-1]; toolMsg.Role != "tool" || !strings.Contains(toolMsg.Content, `{"text":"hi"}`) {
+		t.Fatalf("expected echo tool output in second request, got %+v", toolMsg)
+	}
+	third := model.requests[2].Messages
+	if toolMsg := third[len(third)-1]; toolMsg.ToolCallID != "call_2" || !strings.Contains(toolMsg.Content, "approved by alice") {
+		t.Fatalf("expected approval tool output in third request, got %+v", toolMsg)
+	}
+
+	state, err := r.Recover("job_llm_loop")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.TokensIn != 370 || state.TokensOut != 35 {
+		t.Fatalf("unexpected token totals: in=%d out=%d", state.TokensIn, state.TokensOut)
+	}
+	if state.EstimatedCost < 0.439 || state.EstimatedCost > 0.441 {
+		t.Fatalf("unexpected estimated cost: %f", state.EstimatedCost)
+	}
+	if state.StepCount != 3 || state.ToolCallCount != 2 {
+		t.Fatalf("unexpected counters: steps=%d tools=%d", state.StepCount, state.ToolCallCount)
+	}
+}
+
+func TestApprovalResultRequiresQuorum(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 17, 0, 0, 0, time.UTC)
+	r := setupLLMJob(t, "job_llm_unapproved", now)
+	if _, err := r.RecordApprovalRules("job_llm_unapproved", []policy.ApprovalRule{{Name: "pair", Kinds: []string{"approval"}, Quorum: 2}}); err != nil {
+		t.Fatalf("RecordApprovalRules: %v", err)
+	}
+	cp, err := r.EmitCheckpoint("job_llm_unapproved", runner.CheckpointInput{
+		Type:           "decision-needed",
+		Summary:        "llm requested approval",
+		Status:         queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{Kind: "approval", Instructions: "approve"},
+	})
+	if err != nil {
+		t.Fatalf("EmitCheckpoint: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_llm_unapproved", queue.StatusBlockedDecision); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+
+	ag := &agent{jobID: "job_llm_unapproved", runner: r, state: State{PendingCheckpointID: cp.CheckpointID}}
+	for _, approver := range []string{"", "bob"} {
+		if approver != "" {
+			if _, err := r.ApproveCheckpoint("job_llm_unapproved", cp.CheckpointID, "ok", approver); err != nil {
+				t.Fatalf("ApproveCheckpoint: %v", err)
+			}
+		}
+		_, err := ag.approvalResult()
+		var werr wrkrerrors.WrkrError
+		if !errors.As(err, &werr) || werr.Code != wrkrerrors.ECheckpointApprovalRequired {
+			t.Fatalf("expected E_CHECKPOINT_APPROVAL_REQUIRED below quorum, got %v", err)
+		}
+	}
+	if _, err := r.ApproveCheckpoint("job_llm_unapproved", cp.CheckpointID, "ship it", "carol"); err != nil {
+		t.Fatalf("ApproveCheckpoint: %v", err)
+	}
+	if content, err := ag.approvalResult(); err != nil || content != "approved by carol: ship it" {
+		t.Fatalf("expected approval content at quorum, got %q err=%v", content, err)
+	}
+}
+
+func TestRunStopsWhenTokenBudgetExceeded(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 17, 5, 0, 0, time.UTC)
+	r := setupLLMJob(t, "job_llm_budget", now)
+
+	model := &mockModel{responses: []string{
+		toolCallResponse("call_1", "echo", `{}`, 400, 200),
+		finalResponse("unreachable", 1, 1),
+	}}
+	server := httptest.NewServer(model.handler(t))
+	defer server.Close()
+
+	maxTokens := 500
+	_, err := Run("job_llm_budget", Config{
+		BaseURL: server.URL + "/v1",
+		Model:   "test-model",
+		Prompt:  "go",
+		Tools:   []Tool{{Name: "echo", Command: "cat"}},
+	}, RunOptions{
+		Now:          func() time.Time { return now },
+		BudgetLimits: budget.Limits{MaxTokens: &maxTokens},
+	})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EBudgetExceeded {
+		t.Fatalf("expected budget exceeded, got %v", err)
+	}
+	state, err := r.Recover("job_llm_budget")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusBlockedBudget {
+		t.Fatalf("expected blocked_budget, got %s", state.Status)
+	}
+	if len(model.requests) != 1 {
+		t.Fatalf("expected loop to stop after first turn, got %d requests", len(model.requests))
+	}
+}
+
+func TestRunPricesUsageFromPriceTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 17, 10, 0, 0, time.UTC)
+	r := setupLLMJob(t, "job_llm_priced", now)
+
+	model := &mockModel{responses: []string{finalResponse("done", 1000, 500)}}
+	server := httptest.NewServer(model.handler(t))
+	defer server.Close()
+
+	table, err := pricing.Parse([]byte("version: v1\nmodels:\n  test-model: {input_per_1k_tokens: 0.2, output_per_1k_tokens: 0.4}\n"), "prices.yaml")
+	if err != nil {
+		t.Fatalf("pricing.Parse: %v", err)
+	}
+	cfg := Config{
+		BaseURL:         server.URL + "/v1",
+		Model:           "test-model",
+		Prompt:          "go",
+		InputCostPer1K:  9,
+		OutputCostPer1K: 9,
+	}
+	if _, err := Run("job_llm_priced", cfg, RunOptions{Now: func() time.Time { return now }, PriceTable: table}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	state, err := r.Recover("job_llm_priced")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.EstimatedCost != 0.4 {
+		t.Fatalf("expected price table cost 0.4, got %v", state.EstimatedCost)
+	}
+
+	cfg.Model = "unpriced-model"
+	if _, err := Run("job_llm_priced", cfg, RunOptions{Now: func() time.Time { return now }, PriceTable: table}); err == nil {
+		t.Fatal("expected unpriced model to fail before calling the model")
+	}
+	if len(model.requests) != 1 {
+		t.Fatalf("expected a single model request, got %d", len(model.requests))
+	}
+}
+
+func TestConfigFromAdapterValidation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ConfigFromAdapter(map[string]any{"model": "m"}, nil); err == nil {
+		t.Fatal("expected missing base_url error")
+	}
+	if _, err := ConfigFromAdapter(map[string]any{"base_url": "http://x", "model": "m", "max_turns": 0}, nil); err == nil {
+		t.Fatal("expected invalid max_turns error")
+	}
+	if _, err := ConfigFromAdapter(map[string]any{
+		"base_url": "http://x",
+		"model":    "m",
+		"tools":    []any{map[string]any{"name": approvalToolName, "command": "true"}},
+	}, nil); err == nil {
+		t.Fatal("expected reserved tool name error")
+	}
+	cfg, err := ConfigFromAdapter(map[string]any{
+		"base_url":        "http://x/v1/",
+		"model":           "m",
+		"prompt":          "from config",
+		"timeout_seconds": float64(5),
+	}, map[string]any{"prompt": "from inputs"})
+	if err != nil {
+		t.Fatalf("ConfigFromAdapter: %v", err)
+	}
+	if cfg.BaseURL != "http://x/v1" || cfg.Prompt != "from inputs" || cfg.Timeout != 5*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
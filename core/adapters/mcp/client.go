@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/sandbox"
+)
+
+const protocolVersion = "2024-11-05"
+
+// client speaks newline-delimited JSON-RPC 2.0 to one stdio MCP server.
+type client struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	lines   chan []byte
+	nextID  int64
+	timeout time.Duration
+}
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type toolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text,omitempty"`
+	} `json:"content"`
+	StructuredContent map[string]any `json:"structuredContent,omitempty"`
+	IsError           bool           `json:"isError,omitempty"`
+}
+
+func startClient(server Server, sb *sandbox.Sandbox, workspace string, timeout time.Duration, version string) (*client, error) {
+	cmd, err := sb.Command(server.Command, server.Args...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = workspace
+	keys := make([]string, 0, len(server.Env))
+	for key := range server.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	extra := make([]string, 0, len(keys))
+	for _, key := range keys {
+		extra = append(extra, key+"="+server.Env[key])
+	}
+	cmd.Env = sb.Environ(extra)
+	cmd.Stderr = io.Discard
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start mcp server %s: %w", server.Name, err)
+	}
+
+	c := &client{
+		name:    server.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		lines:   make(chan []byte, 16),
+		timeout: timeout,
+	}
+	go func() {
+		defer close(c.lines)
+		reader := bufio.NewReader(stdout)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(strings.TrimSpace(string(line))) > 0 {
+				c.lines <- line
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	if _, err := c.request("initialize", map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "wrkr", "version": version},
+	}); err != nil {
+		c.close()
+		return nil, err
+	}
+	if err := c.send(rpcMessage{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *client) send(msg rpcMessage) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := c.stdin.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("write to mcp server %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// request sends one call and waits for the response with the same id.
+// Notifications are ignored and server-initiated requests are refused.
+func (c *client) request(method string, params any) (json.RawMessage, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.send(rpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return nil, fmt.Errorf("mcp server %s exited during %s", c.name, method)
+			}
+			var msg rpcMessage
+			if err := json.Unmarshal(line, &msg); err != nil {
+				return nil, fmt.Errorf("mcp server %s sent invalid json: %w", c.name, err)
+			}
+			if msg.Method != "" {
+				if msg.ID != nil {
+					_ = c.send(rpcMessage{JSONRPC: "2.0", ID: msg.ID, Error: &rpcError{Code: -32601, Message: "method not supported by wrkr"}})
+				}
+				continue
+			}
+			if msg.ID == nil || *msg.ID != id {
+				continue
+			}
+			if msg.Error != nil {
+				return nil, fmt.Errorf("mcp server %s %s failed: %s (code %d)", c.name, method, msg.Error.Message, msg.Error.Code)
+			}
+			return msg.Result, nil
+		case <-timer.C:
+			return nil, fmt.Errorf("mcp server %s timed out after %s waiting for %s", c.name, c.timeout, method)
+		}
+	}
+}
+
+func (c *client) callTool(name string, args map[string]any) (json.RawMessage, toolResult, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	raw, err := c.request("tools/call", map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		return nil, toolResult{}, err
+	}
+	var result toolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return raw, toolResult{}, fmt.Errorf("decode mcp tool result: %w", err)
+	}
+	return raw, result, nil
+}
+
+func (c *client) close() {
+	_ = c.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		_ = c.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		_ = c.cmd.Process.Kill()
+		<-done
+	}
+}
@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Server is a stdio MCP server from adapter.config.servers. Sensitive lists
+// tool names that need an approved decision-needed checkpoint before running.
+type Server struct {
+	Name      string
+	Command   string
+	Args      []string
+	Env       map[string]string
+	Sensitive []string
+}
+
+// Call is one tool invocation from jobspec.inputs.calls.
+type Call struct {
+	ID        string
+	Server    string
+	Tool      string
+	Arguments map[string]any
+	Summary   string
+	Sensitive bool
+}
+
+type Config struct {
+	Servers    []Server
+	Calls      []Call
+	Timeout    time.Duration
+	RedactKeys []string
+}
+
+func (c Config) server(name string) (Server, bool) {
+	for _, server := range c.Servers {
+		if server.Name == name {
+			return server, true
+		}
+	}
+	return Server{}, false
+}
+
+func (c Config) sensitive(call Call) bool {
+	if call.Sensitive {
+		return true
+	}
+	server, ok := c.server(call.Server)
+	if !ok {
+		return false
+	}
+	for _, tool := range server.Sensitive {
+		if tool == call.Tool || tool == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func ConfigFromAdapter(config map[string]any, inputs map[string]any) (Config, error) {
+	cfg := Config{Timeout: defaultTimeout}
+
+	rawServers, ok := config["servers"].([]any)
+	if !ok || len(rawServers) == 0 {
+		return Config{}, fmt.Errorf("mcp adapter requires adapter.config.servers")
+	}
+	for i, item := range rawServers {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return Config{}, fmt.Errorf("adapter.config.servers[%d] must be an object", i)
+		}
+		server := Server{
+			Name:    strings.TrimSpace(stringField(obj, "name")),
+			Command: strings.TrimSpace(stringField(obj, "command")),
+		}
+		if server.Name == "" || server.Command == "" {
+			return Config{}, fmt.Errorf("adapter.config.servers[%d] requires name and command", i)
+		}
+		if _, exists := cfg.server(server.Name); exists {
+			return Config{}, fmt.Errorf("adapter.config.servers[%d] duplicate name %q", i, server.Name)
+		}
+		var err error
+		if server.Args, err = stringList(obj["args"], fmt.Sprintf("adapter.config.servers[%d].args", i)); err != nil {
+			return Config{}, err
+		}
+		if server.Sensitive, err = stringList(obj["sensitive_tools"], fmt.Sprintf("adapter.config.servers[%d].sensitive_tools", i)); err != nil {
+			return Config{}, err
+		}
+		if rawEnv, ok := obj["env"]; ok {
+			env, ok := rawEnv.(map[string]any)
+			if !ok {
+				return Config{}, fmt.Errorf("adapter.config.servers[%d].env must be an object", i)
+			}
+			server.Env = make(map[string]string, len(env))
+			for key, value := range env {
+				text, ok := value.(string)
+				if !ok {
+					return Config{}, fmt.Errorf("adapter.config.servers[%d].env.%s must be a string", i, key)
+				}
+				server.Env[key] = text
+			}
+		}
+		cfg.Servers = append(cfg.Servers, server)
+	}
+
+	if raw, ok := config["timeout_seconds"]; ok {
+		value, ok := intValue(raw)
+		if !ok || value <= 0 {
+			return Config{}, fmt.Errorf("adapter.config.timeout_seconds must be a positive integer")
+		}
+		cfg.Timeout = time.Duration(value) * time.Second
+	}
+	redactKeys, err := stringList(config["redact_keys"], "adapter.config.redact_keys")
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.RedactKeys = redactKeys
+
+	rawCalls, ok := inputs["calls"].([]any)
+	if !ok || len(rawCalls) == 0 {
+		return Config{}, fmt.Errorf("mcp adapter requires jobspec.inputs.calls")
+	}
+	seen := map[string]struct{}{}
+	for i, item := range rawCalls {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return Config{}, fmt.Errorf("jobspec.inputs.calls[%d] must be an object", i)
+		}
+		call := Call{
+			ID:      strings.TrimSpace(stringField(obj, "id")),
+			Server:  strings.TrimSpace(stringField(obj, "server")),
+			Tool:    strings.TrimSpace(stringField(obj, "tool")),
+			Summary: stringField(obj, "summary"),
+		}
+		if call.ID == "" {
+			call.ID = fmt.Sprintf("call_%d", i+1)
+		}
+		if _, exists := seen[call.ID]; exists {
+			return Config{}, fmt.Errorf("jobspec.inputs.calls[%d] duplicate id %q", i, call.ID)
+		}
+		seen[call.ID] = struct{}{}
+		if call.Tool == "" {
+			return Config{}, fmt.Errorf("jobspec.inputs.calls[%d] requires tool", i)
+		}
+		if call.Server == "" && len(cfg.Servers) == 1 {
+			call.Server = cfg.Servers[0].Name
+		}
+		if _, ok := cfg.server(call.Server); !ok {
+			return Config{}, fmt.Errorf("jobspec.inputs.calls[%d] references unknown server %q", i, call.Server)
+		}
+		if rawArgs, ok := obj["arguments"]; ok {
+			args, ok := rawArgs.(map[string]any)
+			if !ok {
+				return Config{}, fmt.Errorf("jobspec.inputs.calls[%d].arguments must be an object", i)
+			}
+			call.Arguments = args
+		}
+		if sensitive, ok := obj["sensitive"].(bool); ok {
+			call.Sensitive = sensitive
+		}
+		cfg.Calls = append(cfg.Calls, call)
+	}
+	return cfg, nil
+}
+
+func stringField(obj map[string]any, key string) string {
+	value, _ := obj[key].(string)
+	return value
+}
+
+func stringList(raw any, field string) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of strings", field)
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		text, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of strings", field)
+		}
+		out = append(out, text)
+	}
+	return out, nil
+}
+
+func intValue(raw any) (int, bool) {
+	switch v := raw.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,320 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/redact"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/sandbox"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+	"github.com/davidahmann/wrkr/core/textx"
+)
+
+const maxPreviewLength = 512
+
+// Cursor is the durable resume position of an mcp run. PendingCheckpointID
+// is the decision-needed checkpoint gating the sensitive call at
+// NextCallIndex.
+type Cursor struct {
+	NextCallIndex       int    `json:"next_call_index"`
+	PendingCheckpointID string `json:"pending_checkpoint_id,omitempty"`
+}
+
+type RunOptions struct {
+	Now             func() time.Time
+	Cursor          Cursor
+	Workspace       string
+	BudgetLimits    budget.Limits
+	ProducerVersion string
+	OnCursor        func(cursor Cursor) error
+	Sandbox         *sandbox.Sandbox
+}
+
+type RunResult struct {
+	Status         queue.Status `json:"status"`
+	NextCallIndex  int          `json:"next_call_index"`
+	DecisionCallID string       `json:"decision_call_id,omitempty"`
+}
+
+type session struct {
+	jobID   string
+	cfg     Config
+	opts    RunOptions
+	now     func() time.Time
+	store   *store.LocalStore
+	runner  *runner.Runner
+	rules   *redact.Ruleset
+	cursor  Cursor
+	clients map[string]*client
+}
+
+func Run(jobID string, cfg Config, opts RunOptions) (RunResult, error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	if len(cfg.Calls) == 0 {
+		return RunResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "mcp adapter requires at least one call", nil)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		return RunResult{}, err
+	}
+	r, err := runner.New(s, runner.Options{Now: now})
+	if err != nil {
+		return RunResult{}, err
+	}
+	rules, err := s.Redaction(jobID)
+	if err != nil {
+		return RunResult{}, err
+	}
+	ss := &session{
+		jobID:   jobID,
+		cfg:     cfg,
+		opts:    opts,
+		now:     now,
+		store:   s,
+		runner:  r,
+		rules:   rules.WithKeys(cfg.RedactKeys),
+		cursor:  opts.Cursor,
+		clients: map[string]*client{},
+	}
+	defer ss.closeAll()
+
+	for idx := ss.cursor.NextCallIndex; idx < len(cfg.Calls); idx++ {
+		if _, err := r.CheckBudget(jobID, opts.BudgetLimits); err != nil {
+			return ss.result(runner.BudgetStopStatus(err)), err
+		}
+
+		call := cfg.Calls[idx]
+		if cfg.sensitive(call) {
+			if ss.cursor.PendingCheckpointID == "" {
+				return ss.requestApproval(call)
+			}
+			approved, err := ss.approved(ss.cursor.PendingCheckpointID)
+			if err != nil {
+				return RunResult{}, err
+			}
+			if !approved {
+				return ss.result(queue.StatusBlockedDecision), wrkrerrors.New(
+					wrkrerrors.ECheckpointApprovalRequired,
+					"sensitive mcp call requires approval",
+					map[string]any{"job_id": jobID, "call_id": call.ID, "checkpoint_id": ss.cursor.PendingCheckpointID},
+				)
+			}
+		}
+
+		if err := ss.invoke(call, idx); err != nil {
+			return ss.result(queue.StatusBlockedError), err
+		}
+		if err := ss.advance(idx + 1); err != nil {
+			return RunResult{}, err
+		}
+		if _, err := r.CheckBudget(jobID, opts.BudgetLimits); err != nil {
+			return ss.result(runner.BudgetStopStatus(err)), err
+		}
+		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
+			Type:    "progress",
+			Summary: fmt.Sprintf("mcp call %s completed (%s/%s)", call.ID, call.Server, call.Tool),
+			Status:  queue.StatusRunning,
+		})
+	}
+
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return RunResult{}, err
+	}
+	if state.Status != queue.StatusCompleted {
+		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
+			Type:    "completed",
+			Summary: "mcp adapter completed",
+			Status:  queue.StatusCompleted,
+		})
+		if _, err := r.ChangeStatus(jobID, queue.StatusCompleted); err != nil {
+			return RunResult{}, err
+		}
+	}
+	return ss.result(queue.StatusCompleted), nil
+}
+
+// redactValue copies v with secrets and values under sensitive keys masked.
+func (ss *session) redactValue(v any) any {
+	masked, _ := ss.rules.Value(v)
+	return masked
+}
+
+func (ss *session) redactText(text string) string {
+	masked, _ := ss.rules.String(text)
+	return masked
+}
+
+func (ss *session) result(status queue.Status) RunResult {
+	return RunResult{Status: status, NextCallIndex: ss.cursor.NextCallIndex}
+}
+
+func (ss *session) saveCursor() error {
+	if ss.opts.OnCursor == nil {
+		return nil
+	}
+	return ss.opts.OnCursor(ss.cursor)
+}
+
+func (ss *session) advance(next int) error {
+	ss.cursor.NextCallIndex = next
+	ss.cursor.PendingCheckpointID = ""
+	return ss.saveCursor()
+}
+
+func (ss *session) requestApproval(call Call) (RunResult, error) {
+	summary := call.Summary
+	if strings.TrimSpace(summary) == "" {
+		summary = fmt.Sprintf("mcp call %s uses sensitive tool %s/%s", call.ID, call.Server, call.Tool)
+	}
+	cp, err := ss.runner.EmitCheckpoint(ss.jobID, runner.CheckpointInput{
+		Type:    "decision-needed",
+		Summary: summary,
+		Status:  queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{
+			Kind:         "approval",
+			Instructions: fmt.Sprintf("approve sensitive mcp tool %s/%s before call %s runs", call.Server, call.Tool, call.ID),
+		},
+	})
+	if err != nil {
+		return RunResult{}, err
+	}
+	ss.cursor.PendingCheckpointID = cp.CheckpointID
+	if err := ss.saveCursor(); err != nil {
+		return RunResult{}, err
+	}
+	if _, err := ss.runner.ChangeStatus(ss.jobID, queue.StatusBlockedDecision); err != nil {
+		return RunResult{}, err
+	}
+	result := ss.result(queue.StatusBlockedDecision)
+	result.DecisionCallID = call.ID
+	return result, nil
+}
+
+// approved reports whether checkpointID met its approval quorum and was not
+// rejected.
+func (ss *session) approved(checkpointID string) (bool, error) {
+	d, err := ss.runner.Decision(ss.jobID, checkpointID)
+	if err != nil {
+		return false, err
+	}
+	return d.Approved && d.Rejection == nil, nil
+}
+
+func (ss *session) client(name string) (*client, error) {
+	if c, ok := ss.clients[name]; ok {
+		return c, nil
+	}
+	server, ok := ss.cfg.server(name)
+	if !ok {
+		return nil, fmt.Errorf("mcp server %q is not declared", name)
+	}
+	c, err := startClient(server, ss.opts.Sandbox, ss.opts.Workspace, ss.cfg.Timeout, ss.opts.ProducerVersion)
+	if err != nil {
+		return nil, err
+	}
+	ss.clients[name] = c
+	return c, nil
+}
+
+func (ss *session) closeAll() {
+	for _, c := range ss.clients {
+		c.close()
+	}
+}
+
+// invoke runs one tool call and records it as an adapter_step. Arguments and
+// results are stored as hashes plus redacted copies so secrets never reach
+// the event log.
+func (ss *session) invoke(call Call, idx int) error {
+	argsRaw, err := json.Marshal(call.Arguments)
+	if err != nil {
+		return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "mcp call arguments are not json", map[string]any{"call_id": call.ID})
+	}
+	payload := map[string]any{
+		"adapter":          "mcp",
+		"kind":             "tool_call",
+		"call_id":          call.ID,
+		"call_index":       idx,
+		"server":           call.Server,
+		"tool":             call.Tool,
+		"arguments":        ss.redactValue(call.Arguments),
+		"arguments_sha256": digest(argsRaw),
+	}
+
+	var (
+		result  toolResult
+		callErr error
+	)
+	c, callErr := ss.client(call.Server)
+	if callErr == nil {
+		var raw json.RawMessage
+		raw, result, callErr = c.callTool(call.Tool, call.Arguments)
+		payload["result_sha256"] = digest(raw)
+		payload["result_bytes"] = len(raw)
+		payload["is_error"] = result.IsError
+		payload["result_preview"] = textx.Truncate(ss.redactText(resultText(result)), maxPreviewLength)
+		if result.StructuredContent != nil {
+			payload["structured_content"] = ss.redactValue(result.StructuredContent)
+		}
+	}
+	if callErr != nil {
+		payload["error"] = ss.redactText(callErr.Error())
+	}
+	if _, err := ss.store.AppendEvent(ss.jobID, "adapter_step", payload, ss.now()); err != nil {
+		return err
+	}
+
+	if _, err := ss.runner.IncrementCounters(ss.jobID, 0, 1, 1); err != nil {
+		return err
+	}
+
+	if callErr == nil && !result.IsError {
+		return nil
+	}
+	message := "mcp tool call returned an error result"
+	details := map[string]any{"job_id": ss.jobID, "call_id": call.ID, "server": call.Server, "tool": call.Tool}
+	if callErr != nil {
+		message = "mcp tool call failed"
+		details["error"] = ss.redactText(callErr.Error())
+	}
+	_, _ = ss.runner.EmitCheckpoint(ss.jobID, runner.CheckpointInput{
+		Type:        "blocked",
+		Summary:     fmt.Sprintf("mcp call %s failed (%s/%s)", call.ID, call.Server, call.Tool),
+		Status:      queue.StatusBlockedError,
+		ReasonCodes: []string{string(wrkrerrors.EAdapterFail)},
+	})
+	_, _ = ss.runner.ChangeStatus(ss.jobID, queue.StatusBlockedError)
+	return wrkrerrors.New(wrkrerrors.EAdapterFail, message, details)
+}
+
+func resultText(result toolResult) string {
+	parts := make([]string, 0, len(result.Content))
+	for _, item := range result.Content {
+		if item.Type == "text" {
+			parts = append(parts, item.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func digest(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/redact"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+// TestMain doubles as a stub stdio MCP server when WRKR_MCP_STUB is set.
+func TestMain(m *testing.M) {
+	if os.Getenv("WRKR_MCP_STUB") == "1" {
+		runStubServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runStubServer() {
+	scanner := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Name      string         `json:"name"`
+				Arguments map[string]any `json:"arguments"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+		resp := map[string]any{"jsonrpc": "2.0", "id": *req.ID}
+		switch req.Method {
+		case "initialize":
+			resp["result"] = map[string]any{"protocolVersion": protocolVersion, "capabilities": map[string]any{"tools": map[string]any{}}}
+		case "tools/call":
+			if path := os.Getenv("WRKR_MCP_STUB_LOG"); path != "" {
+				if f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err == nil {
+					_, _ = fmt.Fprintln(f, req.Params.Name)
+					_ = f.Close()
+				}
+			}
+			switch req.Params.Name {
+			case "echo", "deploy":
+				text, _ := req.Params.Arguments["text"].(string)
+				resp["result"] = map[string]any{"content": []any{map[string]any{"type": "text", "text": text}}}
+			case "fail":
+				resp["result"] = map[string]any{"content": []any{map[string]any{"type": "text", "text": "boom"}}, "isError": true}
+			default:
+				resp["error"] = map[string]any{"code": -32602, "message": "unknown tool"}
+			}
+		default:
+			resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		}
+		_ = out.Encode(resp)
+	}
+}
+
+func stubConfig(t *testing.T, calls []any) Config {
+	t.Helper()
+	cfg, err := ConfigFromAdapter(map[string]any{
+		"servers": []any{map[string]any{
+			"name":            "stub",
+			"command":         os.Args[0],
+			"env":             map[string]any{"WRKR_MCP_STUB": "1"},
+			"sensitive_tools": []any{"deploy"},
+		}},
+		"timeout_seconds": 10,
+	}, map[string]any{"calls": calls})
+	if err != nil {
+		t.Fatalf("ConfigFromAdapter: %v", err)
+	}
+	return cfg
+}
+
+func setupMCPJob(t *testing.T, jobID string, now time.Time) *runner.Runner {
+	t.Helper()
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	return r
+}
+
+func TestRunGatesSensitiveToolsAndRedactsEvents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	logPath := filepath.Join(t.TempDir(), "calls.log")
+	t.Setenv("WRKR_MCP_STUB_LOG", logPath)
+	now := time.Date(2026, 2, 14, 18, 0, 0, 0, time.UTC)
+	r := setupMCPJob(t, "job_mcp", now)
+
+	cfg := stubConfig(t, []any{
+		map[string]any{"id": "greet", "tool": "echo", "arguments": map[string]any{"text": "hello", "api_key": "sk-live-123"}},
+		map[string]any{"id": "ship", "tool": "deploy", "arguments": map[string]any{"text": "token=abc123"}},
+	})
+	var cursor Cursor
+	opts := RunOptions{
+		Now:       func() time.Time { return now },
+		Workspace: t.TempDir(),
+		OnCursor: func(c Cursor) error {
+			cursor = c
+			return nil
+		},
+	}
+	result, err := Run("job_mcp", cfg, opts)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != queue.StatusBlockedDecision || result.DecisionCallID != "ship" {
+		t.Fatalf("expected decision stop before deploy, got %+v", result)
+	}
+	if raw, _ := os.ReadFile(logPath); strings.Contains(string(raw), "deploy") {
+		t.Fatalf("sensitive tool ran before approval: %q", string(raw))
+	}
+	if cursor.NextCallIndex != 1 || cursor.PendingCheckpointID == "" {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+
+	opts.Cursor = cursor
+	if _, err := Run("job_mcp", cfg, opts); err == nil {
+		t.Fatal("expected approval required error")
+	} else {
+		var werr wrkrerrors.WrkrError
+		if !errors.As(err, &werr) || werr.Code != wrkrerrors.ECheckpointApprovalRequired {
+			t.Fatalf("expected approval required, got %v", err)
+		}
+	}
+
+	if _, err := r.ApproveCheckpoint("job_mcp", cursor.PendingCheckpointID, "ok", "alice"); err != nil {
+		t.Fatalf("ApproveCheckpoint: %v", err)
+	}
+	if _, err := r.Resume("job_mcp", runner.ResumeInput{}); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	result, err = Run("job_mcp", cfg, opts)
+	if err != nil {
+		t.Fatalf("Run after approval: %v", err)
+	}
+	if result.Status != queue.StatusCompleted || cursor.NextCallIndex != 2 {
+		t.Fatalf("expected completion, got %+v cursor=%+v", result, cursor)
+	}
+
+	state, err := r.Recover("job_mcp")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.ToolCallCount != 2 {
+		t.Fatalf("expected two tool calls, got %d", state.ToolCallCount)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(s.JobDir("job_mcp"), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	events := string(raw)
+	if strings.Contains(events, "sk-live-123") || strings.Contains(events, "abc123") {
+		t.Fatalf("expected secrets redacted from events: %s", events)
+	}
+	if !strings.Contains(events, `"arguments_sha256"`) || !strings.Contains(events, `"result_sha256"`) {
+		t.Fatalf("expected hashed call records: %s", events)
+	}
+}
+
+func TestRunCountsCallsAgainstToolBudgetAndBlocksOnErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 18, 5, 0, 0, time.UTC)
+
+	r := setupMCPJob(t, "job_mcp_budget", now)
+	cfg := stubConfig(t, []any{
+		map[string]any{"tool": "echo"},
+		map[string]any{"tool": "echo"},
+		map[string]any{"tool": "echo"},
+	})
+	result, err := Run("job_mcp_budget", cfg, RunOptions{
+		Now:          func() time.Time { return now },
+		BudgetLimits: budget.Limits{MaxToolCalls: 1},
+	})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EBudgetExceeded {
+		t.Fatalf("expected budget exceeded, got %v", err)
+	}
+	if result.Status != queue.StatusBlockedBudget || result.NextCallIndex != 2 {
+		t.Fatalf("unexpected budget result: %+v", result)
+	}
+	if state, _ := r.Recover("job_mcp_budget"); state.ToolCallCount != 2 {
+		t.Fatalf("expected two counted calls, got %+v", state)
+	}
+
+	r = setupMCPJob(t, "job_mcp_fail", now)
+	cfg = stubConfig(t, []any{map[string]any{"tool": "fail"}})
+	if _, err := Run("job_mcp_fail", cfg, RunOptions{Now: func() time.Time { return now }}); err == nil {
+		t.Fatal("expected tool error result to block")
+	}
+	if state, _ := r.Recover("job_mcp_fail"); state.Status != queue.StatusBlockedError {
+		t.Fatalf("expected blocked_error, got %s", state.Status)
+	}
+}
+
+func TestRunPreviewKeepsMultibyteRunesWhole(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 18, 10, 0, 0, time.UTC)
+	setupMCPJob(t, "job_mcp_preview", now)
+
+	// One ASCII byte puts the preview limit inside a two-byte rune.
+	text := "a" + strings.Repeat("é", maxPreviewLength)
+	cfg := stubConfig(t, []any{map[string]any{"tool": "echo", "arguments": map[string]any{"text": text}}})
+	if _, err := Run("job_mcp_preview", cfg, RunOptions{Now: func() time.Time { return now }}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	events, err := s.LoadEvents("job_mcp_preview")
+	if err != nil {
+		t.Fatalf("LoadEvents: %v", err)
+	}
+	found := false
+	for _, event := range events {
+		var payload struct {
+			Preview *string `json:"result_preview"`
+		}
+		if event.Type != "adapter_step" || json.Unmarshal(event.Payload, &payload) != nil || payload.Preview == nil {
+			continue
+		}
+		found = true
+		if preview := *payload.Preview; preview != text[:maxPreviewLength-1] || strings.ContainsRune(preview, utf8.RuneError) {
+			t.Fatalf("expected preview cut before the split rune, got %d bytes", len(preview))
+		}
+	}
+	if !found {
+		t.Fatal("expected an adapter_step with a result preview")
+	}
+}
+
+func TestConfigFromAdapterValidation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ConfigFromAdapter(map[string]any{}, map[string]any{"calls": []any{}}); err == nil {
+		t.Fatal("expected missing servers error")
+	}
+	servers := []any{map[string]any{"name": "a", "command": "srv"}}
+	if _, err := ConfigFromAdapter(map[string]any{"servers": servers}, map[string]any{}); err == nil {
+		t.Fatal("expected missing calls error")
+	}
+	if _, err := ConfigFromAdapter(map[string]any{"servers": servers}, map[string]any{
+		"calls": []any{map[string]any{"server": "b", "tool": "x"}},
+	}); err == nil {
+		t.Fatal("expected unknown server error")
+	}
+
+	ss := &session{rules: redact.Default().WithKeys([]string{"custom"})}
+	redactedArgs := ss.redactValue(map[string]any{
+		"password": "p",
+		"nested":   map[string]any{"note": "Bearer abc.def"},
+		"custom":   "c",
+	}).(map[string]any)
+	if redactedArgs["password"] != redact.Marker || redactedArgs["custom"] != redact.Marker {
+		t.Fatalf("expected key redaction, got %+v", redactedArgs)
+	}
+	if note := redactedArgs["nested"].(map[string]any)["note"]; note != "Bearer "+redact.Marker {
+		t.Fatalf("expected text redaction, got %v", note)
+	}
+}
@@ -0,0 +1,103 @@
+package reference
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/davidahmann/wrkr/core/fsx"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/sign"
+)
+
+const (
+	CaptureModeReferenceOnly = "reference-only"
+	CaptureModeCapture       = "capture"
+)
+
+// CaptureOptions controls whether declared step artifacts are hashed on disk.
+// Artifact paths resolve within Workspace; StoreBlobs also copies file contents
+// into the job's content-addressed blob store.
+type CaptureOptions struct {
+	Mode       string
+	Workspace  string
+	StoreBlobs bool
+}
+
+// CaptureFromConfig reads the optional adapter.config.capture block.
+func CaptureFromConfig(config map[string]any, workspace string) (CaptureOptions, error) {
+	opts := CaptureOptions{Mode: CaptureModeReferenceOnly, Workspace: workspace}
+	raw, ok := config["capture"]
+	if !ok || raw == nil {
+		return opts, nil
+	}
+	asMap, ok := raw.(map[string]any)
+	if !ok {
+		return CaptureOptions{}, fmt.Errorf("adapter.config.capture must be an object")
+	}
+	if mode := strings.TrimSpace(stringField(asMap, "mode")); mode != "" {
+		opts.Mode = mode
+	}
+	switch opts.Mode {
+	case CaptureModeReferenceOnly, CaptureModeCapture:
+	default:
+		return CaptureOptions{}, fmt.Errorf("adapter.config.capture.mode must be reference-only or capture")
+	}
+	opts.StoreBlobs = boolField(asMap, "store_blobs")
+	return opts, nil
+}
+
+func (ex *execution) captureArtifacts(step Step) ([]string, error) {
+	capture := ex.opts.Capture
+	if capture.Mode != CaptureModeCapture || len(step.Artifacts) == 0 {
+		return nil, nil
+	}
+	workspace := capture.Workspace
+	if strings.TrimSpace(workspace) == "" {
+		workspace = "."
+	}
+
+	records := make([]v1.ArtifactRecord, 0, len(step.Artifacts))
+	missing := []string{}
+	for _, artifact := range step.Artifacts {
+		path, err := fsx.ResolveWithinBase(workspace, artifact)
+		if err != nil {
+			missing = append(missing, artifact)
+			continue
+		}
+		// #nosec G304 -- artifact path is resolved within the job workspace.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			missing = append(missing, artifact)
+			continue
+		}
+		record := v1.ArtifactRecord{Path: artifact, SHA256: sign.SHA256Hex(data)}
+		if capture.StoreBlobs {
+			// Blobs are masked before storage, so the blob digest may differ
+			// from the on-disk digest that workspace verification checks.
+			rules, err := ex.store.Redaction(ex.jobID)
+			if err != nil {
+				return nil, err
+			}
+			if masked, n := rules.Bytes(data); n > 0 {
+				data = masked
+				record.Redacted = true
+			}
+			if record.BlobSHA256, err = ex.store.PutBlob(ex.jobID, data); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, record)
+	}
+
+	if err := ex.runner.RecordArtifactCapture(ex.jobID, runner.ArtifactCapture{
+		StepID:    step.ID,
+		Artifacts: records,
+		Missing:   missing,
+		Stored:    capture.StoreBlobs,
+	}); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
@@ -0,0 +1,117 @@
+package reference
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition gates a step on an earlier step outcome, the option chosen at an
+// earlier decision step, or a jobspec input. Exactly one of Step, Decision or
+// Input is set.
+type Condition struct {
+	Step     string `json:"step,omitempty"`
+	Outcome  string `json:"outcome,omitempty"`
+	Decision string `json:"decision,omitempty"`
+	Option   string `json:"option,omitempty"`
+	Input    string `json:"input,omitempty"`
+	Equals   any    `json:"equals,omitempty"`
+}
+
+func conditionsHold(conds []Condition, outcomes, choices map[string]string, inputs map[string]any) bool {
+	for _, cond := range conds {
+		if !conditionHolds(cond, outcomes, choices, inputs) {
+			return false
+		}
+	}
+	return true
+}
+
+func conditionHolds(cond Condition, outcomes, choices map[string]string, inputs map[string]any) bool {
+	if cond.Step != "" {
+		return outcomes[cond.Step] == cond.Outcome
+	}
+	if cond.Decision != "" {
+		return choices[cond.Decision] == cond.Option
+	}
+	value, ok := inputs[cond.Input]
+	if !ok {
+		return false
+	}
+	if cond.Equals == nil {
+		return truthy(value)
+	}
+	return fmt.Sprint(value) == fmt.Sprint(cond.Equals)
+}
+
+func truthy(value any) bool {
+	switch typed := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typed
+	case string:
+		return strings.TrimSpace(typed) != ""
+	case []any:
+		return len(typed) > 0
+	case map[string]any:
+		return len(typed) > 0
+	default:
+		return true
+	}
+}
+
+func conditionsField(m map[string]any, key string) ([]Condition, error) {
+	raw, ok := m[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var items []any
+	switch typed := raw.(type) {
+	case map[string]any:
+		items = []any{typed}
+	case []any:
+		items = typed
+	default:
+		return nil, fmt.Errorf("jobspec step %s must be an object or list of objects", key)
+	}
+
+	out := make([]Condition, 0, len(items))
+	for _, item := range items {
+		asMap, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("jobspec step %s entries must be objects", key)
+		}
+		cond := Condition{
+			Step:     strings.TrimSpace(stringField(asMap, "step")),
+			Outcome:  strings.ToLower(strings.TrimSpace(stringField(asMap, "outcome"))),
+			Decision: strings.TrimSpace(stringField(asMap, "decision")),
+			Option:   strings.TrimSpace(stringField(asMap, "option")),
+			Input:    strings.TrimSpace(stringField(asMap, "input")),
+			Equals:   asMap["equals"],
+		}
+		set := 0
+		for _, ref := range []string{cond.Step, cond.Decision, cond.Input} {
+			if ref != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("jobspec step %s entries require exactly one of step, decision, or input", key)
+		}
+		if cond.Decision != "" && cond.Option == "" {
+			return nil, fmt.Errorf("jobspec step %s decision entries require option", key)
+		}
+		if cond.Step != "" {
+			if cond.Outcome == "" {
+				cond.Outcome = OutcomeSucceeded
+			}
+			switch cond.Outcome {
+			case OutcomeSucceeded, OutcomeFailed, OutcomeSkipped:
+			default:
+				return nil, fmt.Errorf("jobspec step %s outcome must be succeeded, failed, or skipped", key)
+			}
+		}
+		out = append(out, cond)
+	}
+	return out, nil
+}
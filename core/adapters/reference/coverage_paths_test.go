@@ -138,10 +138,10 @@ func TestStepsAndFieldHelpersCoveragePaths(t *testing.T) {
 	if len(steps) != 1 || steps[0].RequiredAction != "approval" {
 		t.Fatalf("unexpected normalized step: %+v", steps)
 	}
-	if action := requiredAction(steps[0]); action == nil || action.Kind != "approval" {
+	if action := requiredAction(steps[0], time.Now()); action == nil || action.Kind != "approval" {
 		t.Fatalf("expected required action, got %+v", action)
 	}
-	if action := requiredAction(Step{DecisionNeeded: false}); action != nil {
+	if action := requiredAction(Step{DecisionNeeded: false}, time.Now()); action != nil {
 		t.Fatalf("expected nil required action for non-decision step, got %+v", action)
 	}
 
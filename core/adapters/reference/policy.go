@@ -0,0 +1,114 @@
+package reference
+
+import (
+	"fmt"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// enforcePolicy evaluates the job policy before a step (or every member of a
+// parallel group) runs. A denied step blocks the job with E_POLICY_DENIED; a
+// step that requires approval parks the job on a decision-needed checkpoint
+// and runs once that checkpoint is approved and the job resumed.
+func (ex *execution) enforcePolicy(step Step) (bool, RunResult, error) {
+	if ex.opts.Policy == nil {
+		return false, RunResult{}, nil
+	}
+	subjectID, decision := evaluateStep(ex.opts.Policy, step)
+	switch decision.Action {
+	case policy.ActionDeny:
+		return true, ex.result(queue.StatusBlockedError), ex.block(
+			step.ID,
+			fmt.Sprintf("reference step %s denied by policy (%s)", subjectID, decision.Rule),
+			wrkrerrors.New(
+				wrkrerrors.EPolicyDenied,
+				decision.Reason,
+				map[string]any{"job_id": ex.jobID, "step_id": subjectID, "rule": decision.Rule, "policy_sha256": ex.opts.Policy.SHA256},
+			),
+		)
+	case policy.ActionApproval:
+		if pending := ex.cursor.PendingCheckpointID; pending != "" {
+			approved, err := ex.approved(pending)
+			if err != nil {
+				return true, RunResult{}, err
+			}
+			if approved {
+				return false, RunResult{}, nil
+			}
+			return true, ex.result(queue.StatusBlockedDecision), wrkrerrors.New(
+				wrkrerrors.ECheckpointApprovalRequired,
+				"reference step requires policy approval",
+				map[string]any{"job_id": ex.jobID, "step_id": subjectID, "checkpoint_id": pending},
+			)
+		}
+		summary := fmt.Sprintf("reference step %s requires approval by policy (%s)", subjectID, decision.Rule)
+		cp, err := ex.runner.EmitCheckpoint(ex.jobID, runner.CheckpointInput{
+			Type:    "decision-needed",
+			Summary: summary,
+			Status:  queue.StatusBlockedDecision,
+			RequiredAction: &v1.RequiredAction{
+				Kind:         "policy_approval",
+				Instructions: "approve step " + subjectID + " before it runs",
+			},
+		})
+		if err != nil {
+			return true, RunResult{}, err
+		}
+		ex.cursor.PendingCheckpointID = cp.CheckpointID
+		if err := ex.saveCursor(); err != nil {
+			return true, RunResult{}, err
+		}
+		if _, err := ex.runner.ChangeStatus(ex.jobID, queue.StatusBlockedDecision); err != nil {
+			return true, RunResult{}, err
+		}
+		result := ex.result(queue.StatusBlockedDecision)
+		result.DecisionStepID = step.ID
+		result.DecisionSummary = summary
+		return true, result, nil
+	default:
+		return false, RunResult{}, nil
+	}
+}
+
+// evaluateStep returns the first denial across a step and its group members,
+// or else the first approval requirement.
+func evaluateStep(p *policy.Policy, step Step) (string, policy.Decision) {
+	candidates := append([]Step{step}, step.Parallel...)
+	var (
+		approvalID string
+		approval   *policy.Decision
+	)
+	for _, candidate := range candidates {
+		command := candidate.Command
+		if !candidate.Executed {
+			command = ""
+		}
+		decision := p.Evaluate(policy.Subject{StepID: candidate.ID, Command: command, Paths: candidate.Artifacts})
+		switch decision.Action {
+		case policy.ActionDeny:
+			return candidate.ID, decision
+		case policy.ActionApproval:
+			if approval == nil {
+				approvalID, approval = candidate.ID, &decision
+			}
+		}
+	}
+	if approval != nil {
+		return approvalID, *approval
+	}
+	return step.ID, policy.Decision{Action: policy.ActionAllow}
+}
+
+// approved reports whether checkpointID met its approval quorum and was not
+// rejected.
+func (ex *execution) approved(checkpointID string) (bool, error) {
+	d, err := ex.runner.Decision(ex.jobID, checkpointID)
+	if err != nil {
+		return false, err
+	}
+	return d.Approved && d.Rejection == nil, nil
+}
@@ -6,38 +6,90 @@ import (
 	"os/exec"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/davidahmann/wrkr/core/budget"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
 	"github.com/davidahmann/wrkr/core/queue"
 	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/sandbox"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
 type Step struct {
-	ID             string
-	Summary        string
-	Command        string
-	Artifacts      []string
-	DecisionNeeded bool
-	RequiredAction string
-	Executed       bool
+	ID              string
+	Summary         string
+	Command         string
+	Artifacts       []string
+	DecisionNeeded  bool
+	RequiredAction  string
+	Options         []v1.DecisionOption
+	OnReject        string
+	ApprovalTimeout int
+	EscalateTo      string
+	CancelOnExpiry  bool
+	Executed        bool
+	When            []Condition
+	ContinueOnError bool
+	Parallel        []Step
+	IdempotencyKey  string
+}
+
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+)
+
+// Cursor is the durable resume position of a reference run. GroupCompleted
+// lists members of the parallel group at NextStepIndex that already finished.
+// PendingCheckpointID is the policy approval gating the step at NextStepIndex.
+// Decisions maps decision step ids to the checkpoint that asked for them.
+type Cursor struct {
+	NextStepIndex       int               `json:"next_step_index"`
+	GroupCompleted      []string          `json:"group_completed,omitempty"`
+	Outcomes            map[string]string `json:"outcomes,omitempty"`
+	PendingCheckpointID string            `json:"pending_checkpoint_id,omitempty"`
+	Decisions           map[string]string `json:"decisions,omitempty"`
 }
 
 type RunOptions struct {
-	Now          func() time.Time
-	StartIndex   int
-	BudgetLimits budget.Limits
-	OnAdvance    func(nextStepIndex int) error
+	Now                 func() time.Time
+	StartIndex          int
+	Inputs              map[string]any
+	Outcomes            map[string]string
+	GroupCompleted      []string
+	PendingCheckpointID string
+	Decisions           map[string]string
+	BudgetLimits        budget.Limits
+	Capture             CaptureOptions
+	OnAdvance           func(nextStepIndex int) error
+	OnCursor            func(cursor Cursor) error
+	Sandbox             *sandbox.Sandbox
+	Policy              *policy.Policy
 }
 
 type RunResult struct {
-	Status          queue.Status `json:"status"`
-	DecisionStepID  string       `json:"decision_step_id,omitempty"`
-	DecisionSummary string       `json:"decision_summary,omitempty"`
-	NextStepIndex   int          `json:"next_step_index"`
+	Status          queue.Status      `json:"status"`
+	DecisionStepID  string            `json:"decision_step_id,omitempty"`
+	DecisionSummary string            `json:"decision_summary,omitempty"`
+	NextStepIndex   int               `json:"next_step_index"`
+	Outcomes        map[string]string `json:"outcomes,omitempty"`
+}
+
+type execution struct {
+	jobID  string
+	opts   RunOptions
+	now    func() time.Time
+	store  *store.LocalStore
+	runner *runner.Runner
+	cursor Cursor
+	// choices maps decision step ids to the option chosen on approval.
+	choices map[string]string
+	mu      sync.Mutex
 }
 
 func Run(jobID string, steps []Step, opts RunOptions) (RunResult, error) {
@@ -64,12 +116,27 @@ func Run(jobID string, steps []Step, opts RunOptions) (RunResult, error) {
 	if err != nil {
 		return RunResult{}, err
 	}
+	ex := &execution{
+		jobID:  jobID,
+		opts:   opts,
+		now:    now,
+		store:  s,
+		runner: r,
+		cursor: Cursor{
+			NextStepIndex:       startIndex,
+			GroupCompleted:      uniqueStrings(opts.GroupCompleted),
+			Outcomes:            copyOutcomes(opts.Outcomes),
+			PendingCheckpointID: opts.PendingCheckpointID,
+			Decisions:           copyOutcomes(opts.Decisions),
+		},
+	}
+	if err := ex.resolveChoices(); err != nil {
+		return RunResult{}, err
+	}
 
 	if startIndex >= len(steps) {
-		if opts.OnAdvance != nil {
-			if err := opts.OnAdvance(startIndex); err != nil {
-				return RunResult{}, err
-			}
+		if err := ex.advance(startIndex); err != nil {
+			return RunResult{}, err
 		}
 		state, err := r.Recover(jobID)
 		if err != nil {
@@ -85,107 +152,95 @@ func Run(jobID string, steps []Step, opts RunOptions) (RunResult, error) {
 				return RunResult{}, err
 			}
 		}
-		return RunResult{Status: queue.StatusCompleted, NextStepIndex: startIndex}, nil
+		return ex.result(queue.StatusCompleted), nil
 	}
 
 	for idx := startIndex; idx < len(steps); idx++ {
 		if _, err := r.CheckBudget(jobID, opts.BudgetLimits); err != nil {
-			return RunResult{Status: queue.StatusBlockedBudget, NextStepIndex: idx}, err
-		}
-
-		step := steps[idx]
-		normalized := normalizeStep(step)
-		payload := map[string]any{
-			"adapter":    "reference",
-			"step_id":    normalized.ID,
-			"step_index": idx,
-			"summary":    normalized.Summary,
-			"command":    normalized.Command,
-			"executed":   normalized.Executed,
-			"artifacts":  normalized.Artifacts,
-		}
-		if _, err := s.AppendEvent(jobID, "adapter_step", payload, now()); err != nil {
-			return RunResult{}, err
+			return ex.result(runner.BudgetStopStatus(err)), err
 		}
 
-		if normalized.Executed && normalized.Command != "" {
-			// #nosec G204 -- reference adapter executes explicit step command from jobspec.
-			cmd := exec.Command("sh", "-lc", normalized.Command)
-			if runErr := cmd.Run(); runErr != nil {
-				code := 1
-				var exitErr *exec.ExitError
-				if errors.As(runErr, &exitErr) {
-					code = exitErr.ExitCode()
-				}
-				_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
-					Type:        "blocked",
-					Summary:     fmt.Sprintf("reference step %s failed (exit=%d)", normalized.ID, code),
-					Status:      queue.StatusBlockedError,
-					ReasonCodes: []string{string(wrkrerrors.EAdapterFail)},
-				})
-				_, _ = r.ChangeStatus(jobID, queue.StatusBlockedError)
-				return RunResult{Status: queue.StatusBlockedError, NextStepIndex: idx}, wrkrerrors.New(
-					wrkrerrors.EAdapterFail,
-					"reference adapter step failed",
-					map[string]any{"job_id": jobID, "step_id": normalized.ID, "exit_code": code},
-				)
+		normalized := normalizeStep(steps[idx])
+		if !conditionsHold(normalized.When, ex.cursor.Outcomes, ex.choices, opts.Inputs) {
+			if err := ex.skip(normalized, idx, ""); err != nil {
+				return RunResult{}, err
 			}
+			if err := ex.advance(idx + 1); err != nil {
+				return RunResult{}, err
+			}
+			continue
+		}
+		if gated, result, err := ex.enforcePolicy(normalized); gated {
+			return result, err
 		}
 
-		state, err := r.Recover(jobID)
-		if err != nil {
-			return RunResult{}, err
+		var (
+			outcome   string
+			artifacts []string
+			runErr    error
+		)
+		if len(normalized.Parallel) > 0 {
+			outcome, artifacts, runErr = ex.runGroup(normalized, idx)
+		} else {
+			outcome, runErr = ex.runStep(normalized, idx)
+			artifacts = normalized.Artifacts
 		}
-		toolCallDelta := 0
-		if normalized.Executed && normalized.Command != "" {
-			toolCallDelta = 1
+		var review *reviewRequired
+		if errors.As(runErr, &review) {
+			return ex.requestReview(review)
 		}
-		if _, err := r.UpdateCounters(
-			jobID,
-			state.RetryCount,
-			state.StepCount+1,
-			state.ToolCallCount+toolCallDelta,
-		); err != nil {
-			return RunResult{}, err
+		if runErr != nil {
+			return ex.result(queue.StatusBlockedError), runErr
 		}
+		ex.cursor.Outcomes[normalized.ID] = outcome
+
 		if _, err := r.CheckBudget(jobID, opts.BudgetLimits); err != nil {
-			return RunResult{Status: queue.StatusBlockedBudget, NextStepIndex: idx + 1}, err
+			ex.cursor.NextStepIndex = idx + 1
+			return ex.result(runner.BudgetStopStatus(err)), err
 		}
 
 		checkpointType := "progress"
-		if normalized.DecisionNeeded {
-			checkpointType = "decision-needed"
-		}
 		status := queue.StatusRunning
+		reasonCodes := []string{}
+		summary := normalized.Summary
 		if normalized.DecisionNeeded {
+			checkpointType = "decision-needed"
 			status = queue.StatusBlockedDecision
 		}
-		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
+		if outcome == OutcomeFailed {
+			reasonCodes = append(reasonCodes, string(wrkrerrors.EAdapterFail))
+			summary = fmt.Sprintf("reference step %s failed; continuing (continue_on_error)", normalized.ID)
+		}
+		cp, err := r.EmitCheckpoint(jobID, runner.CheckpointInput{
 			Type:    checkpointType,
-			Summary: normalized.Summary,
+			Summary: summary,
 			Status:  status,
 			ArtifactsDelta: v1.ArtifactsDelta{
-				Added: normalized.Artifacts,
+				Added: artifacts,
 			},
-			RequiredAction: requiredAction(normalized),
+			RequiredAction: requiredAction(normalized, ex.now()),
+			ReasonCodes:    reasonCodes,
 		})
-		nextStepIndex := idx + 1
-		if opts.OnAdvance != nil {
-			if err := opts.OnAdvance(nextStepIndex); err != nil {
+		if normalized.DecisionNeeded {
+			// Without its checkpoint nothing could approve the decision, so
+			// the cursor stays on the step instead of blocking on it.
+			if err != nil {
 				return RunResult{}, err
 			}
+			ex.cursor.Decisions[normalized.ID] = cp.CheckpointID
+		}
+		if err := ex.advance(idx + 1); err != nil {
+			return RunResult{}, err
 		}
 
 		if normalized.DecisionNeeded {
 			if _, err := r.ChangeStatus(jobID, queue.StatusBlockedDecision); err != nil {
 				return RunResult{}, err
 			}
-			return RunResult{
-				Status:          queue.StatusBlockedDecision,
-				DecisionStepID:  normalized.ID,
-				DecisionSummary: normalized.Summary,
-				NextStepIndex:   nextStepIndex,
-			}, nil
+			result := ex.result(queue.StatusBlockedDecision)
+			result.DecisionStepID = normalized.ID
+			result.DecisionSummary = normalized.Summary
+			return result, nil
 		}
 	}
 
@@ -197,12 +252,472 @@ func Run(jobID string, steps []Step, opts RunOptions) (RunResult, error) {
 	if _, err := r.ChangeStatus(jobID, queue.StatusCompleted); err != nil {
 		return RunResult{}, err
 	}
-	if opts.OnAdvance != nil {
-		if err := opts.OnAdvance(len(steps)); err != nil {
+	if err := ex.advance(len(steps)); err != nil {
+		return RunResult{}, err
+	}
+	return ex.result(queue.StatusCompleted), nil
+}
+
+func (ex *execution) runStep(step Step, idx int) (string, error) {
+	if _, err := ex.store.AppendEvent(ex.jobID, "adapter_step", stepPayload(step, idx, ""), ex.now()); err != nil {
+		return "", err
+	}
+
+	outcome, failure, err := ex.execute(step)
+	if err != nil {
+		return "", err
+	}
+	if failure != nil && failure.UncommittedKey != "" {
+		return "", &reviewRequired{StepID: step.ID, Members: []string{step.ID}, Keys: []string{failure.UncommittedKey}}
+	}
+	if failure != nil {
+		details := map[string]any{"job_id": ex.jobID, "step_id": step.ID}
+		message := "reference adapter step failed"
+		if len(failure.Missing) > 0 {
+			details["missing_artifacts"] = failure.Missing
+			message = "reference adapter step artifacts missing"
+		} else {
+			details["exit_code"] = failure.ExitCode
+		}
+		return "", ex.block(step.ID, fmt.Sprintf("reference step %s failed (%s)", step.ID, failure), wrkrerrors.New(
+			wrkrerrors.EAdapterFail,
+			message,
+			details,
+		))
+	}
+
+	if err := ex.countStep(step); err != nil {
+		return "", err
+	}
+	return outcome, nil
+}
+
+type stepFailure struct {
+	ExitCode       int
+	Missing        []string
+	UncommittedKey string
+}
+
+// reviewRequired reports steps whose idempotency key was started but never
+// committed, so their side effects may already have been applied.
+type reviewRequired struct {
+	StepID  string
+	Members []string
+	Keys    []string
+}
+
+func (e *reviewRequired) Error() string {
+	return "reference step " + e.StepID + " started but never committed"
+}
+
+func (f *stepFailure) String() string {
+	if len(f.Missing) > 0 {
+		return "missing artifacts: " + strings.Join(f.Missing, ", ")
+	}
+	return fmt.Sprintf("exit=%d", f.ExitCode)
+}
+
+// execute runs the step command and captures its artifacts. A failure is
+// returned only when the step does not continue on error. Commands are
+// bracketed by started/committed idempotency phases; a committed key is not
+// re-run and a started key without an outcome is reported for review.
+func (ex *execution) execute(step Step) (string, *stepFailure, error) {
+	var failure *stepFailure
+	if step.Executed && step.Command != "" {
+		key, phase, err := ex.idempotencyKey(step)
+		if err != nil {
+			return "", nil, err
+		}
+		switch phase {
+		case runner.IdempotencyCommitted:
+		case runner.IdempotencyStarted:
+			return "", &stepFailure{UncommittedKey: key}, nil
+		default:
+			if _, err := ex.runner.RecordIdempotencyPhase(ex.jobID, key, runner.IdempotencyStarted); err != nil {
+				return "", nil, err
+			}
+			resultPhase := runner.IdempotencyCommitted
+			if code, runErr := runCommand(ex.opts.Sandbox, ex.opts.Capture.Workspace, step.Command); runErr != nil {
+				failure = &stepFailure{ExitCode: code}
+				resultPhase = runner.IdempotencyFailed
+			}
+			if _, err := ex.runner.RecordIdempotencyPhase(ex.jobID, key, resultPhase); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	if failure == nil {
+		missing, err := ex.captureArtifacts(step)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(missing) > 0 {
+			failure = &stepFailure{Missing: missing}
+		}
+	}
+	if failure == nil {
+		return OutcomeSucceeded, nil, nil
+	}
+	if step.ContinueOnError {
+		return OutcomeFailed, nil, nil
+	}
+	return "", failure, nil
+}
+
+// idempotencyKey returns the step's declared key, or derives
+// <job_id>:<step_id>:<attempt> where attempt advances past keys that failed,
+// were sent to review or were superseded by a rewind.
+func (ex *execution) idempotencyKey(step Step) (string, string, error) {
+	state, err := ex.runner.Recover(ex.jobID)
+	if err != nil {
+		return "", "", err
+	}
+	if step.IdempotencyKey != "" {
+		return step.IdempotencyKey, state.IdempotencyPhases[step.IdempotencyKey], nil
+	}
+	prefix := fmt.Sprintf("%s:%s:", ex.jobID, step.ID)
+	attempt := 1
+	for key, phase := range state.IdempotencyPhases {
+		if strings.HasPrefix(key, prefix) && (phase == runner.IdempotencyFailed || phase == runner.IdempotencyReview || phase == runner.IdempotencySuperseded) {
+			attempt++
+		}
+	}
+	key := fmt.Sprintf("%s%d", prefix, attempt)
+	return key, state.IdempotencyPhases[key], nil
+}
+
+// Rewind supersedes the committed derived keys of steps[from:], including
+// parallel group members, so steps re-entered after the cursor moves back
+// run again under a fresh attempt. Declared idempotency keys are kept.
+func Rewind(r *runner.Runner, jobID string, steps []Step, from int) error {
+	if from < 0 || from >= len(steps) {
+		return nil
+	}
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return err
+	}
+	prefixes := []string{}
+	for _, step := range steps[from:] {
+		prefixes = append(prefixes, fmt.Sprintf("%s:%s:", jobID, step.ID))
+		for _, member := range step.Parallel {
+			prefixes = append(prefixes, fmt.Sprintf("%s:%s:", jobID, member.ID))
+		}
+	}
+	keys := make([]string, 0, len(state.IdempotencyPhases))
+	for key, phase := range state.IdempotencyPhases {
+		if phase != runner.IdempotencyCommitted {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := r.RecordIdempotencyPhase(jobID, key, runner.IdempotencySuperseded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requestReview parks the job on a decision-needed checkpoint. Approving it
+// and resuming re-runs the uncommitted steps under a fresh attempt key.
+func (ex *execution) requestReview(review *reviewRequired) (RunResult, error) {
+	for _, key := range review.Keys {
+		if _, err := ex.runner.RecordIdempotencyPhase(ex.jobID, key, runner.IdempotencyReview); err != nil {
 			return RunResult{}, err
 		}
 	}
-	return RunResult{Status: queue.StatusCompleted, NextStepIndex: len(steps)}, nil
+	members := strings.Join(review.Members, ", ")
+	summary := fmt.Sprintf("reference step %s started but never committed; verify side effects before re-running", review.StepID)
+	if _, err := ex.runner.EmitCheckpoint(ex.jobID, runner.CheckpointInput{
+		Type:    "decision-needed",
+		Summary: summary,
+		Status:  queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{
+			Kind:         "idempotency_review",
+			Instructions: "verify whether " + members + " applied side effects; approve to re-run or cancel the job",
+		},
+		ReasonCodes: []string{string(wrkrerrors.EStepUncommitted)},
+	}); err != nil {
+		return RunResult{}, err
+	}
+	if _, err := ex.runner.ChangeStatus(ex.jobID, queue.StatusBlockedDecision); err != nil {
+		return RunResult{}, err
+	}
+	result := ex.result(queue.StatusBlockedDecision)
+	result.DecisionStepID = review.StepID
+	result.DecisionSummary = summary
+	return result, nil
+}
+
+// runGroup executes the members of a parallel group concurrently and joins
+// them before returning. Members recorded in cursor.GroupCompleted are not
+// re-run, and each member completion is persisted before the group finishes.
+func (ex *execution) runGroup(group Step, idx int) (string, []string, error) {
+	completed := make(map[string]struct{}, len(ex.cursor.GroupCompleted))
+	for _, id := range ex.cursor.GroupCompleted {
+		completed[id] = struct{}{}
+	}
+
+	artifacts := []string{}
+	pending := make([]Step, 0, len(group.Parallel))
+	for _, member := range group.Parallel {
+		member = normalizeStep(member)
+		member.ContinueOnError = member.ContinueOnError || group.ContinueOnError
+		artifacts = append(artifacts, member.Artifacts...)
+		if _, ok := completed[member.ID]; ok {
+			continue
+		}
+		if !conditionsHold(member.When, ex.cursor.Outcomes, ex.choices, ex.opts.Inputs) {
+			if err := ex.skip(member, idx, group.ID); err != nil {
+				return "", nil, err
+			}
+			if err := ex.completeMember(member.ID, OutcomeSkipped); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+		pending = append(pending, member)
+	}
+
+	var (
+		wg          sync.WaitGroup
+		failMu      sync.Mutex
+		failed      = map[string]string{}
+		uncommitted = map[string]string{}
+		firstErr    error
+	)
+	recordErr := func(err error) {
+		failMu.Lock()
+		defer failMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, member := range pending {
+		if _, err := ex.store.AppendEvent(ex.jobID, "adapter_step", stepPayload(member, idx, group.ID), ex.now()); err != nil {
+			return "", nil, err
+		}
+		wg.Add(1)
+		go func(member Step) {
+			defer wg.Done()
+			outcome, failure, err := ex.execute(member)
+			if err != nil {
+				recordErr(err)
+				return
+			}
+			if failure != nil {
+				failMu.Lock()
+				if failure.UncommittedKey != "" {
+					uncommitted[member.ID] = failure.UncommittedKey
+				} else {
+					failed[member.ID] = failure.String()
+				}
+				failMu.Unlock()
+				return
+			}
+			if err := ex.countStep(member); err != nil {
+				recordErr(err)
+				return
+			}
+			if err := ex.completeMember(member.ID, outcome); err != nil {
+				recordErr(err)
+			}
+		}(member)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return "", nil, firstErr
+	}
+	if len(failed) > 0 {
+		ids := make([]string, 0, len(failed))
+		for id := range failed {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return "", nil, ex.block(group.ID, fmt.Sprintf("reference group %s failed: %s", group.ID, strings.Join(ids, ", ")), wrkrerrors.New(
+			wrkrerrors.EAdapterFail,
+			"reference adapter parallel group failed",
+			map[string]any{"job_id": ex.jobID, "step_id": group.ID, "failed_members": ids, "failures": failed},
+		))
+	}
+
+	if len(uncommitted) > 0 {
+		review := &reviewRequired{StepID: group.ID}
+		for id := range uncommitted {
+			review.Members = append(review.Members, id)
+		}
+		sort.Strings(review.Members)
+		for _, id := range review.Members {
+			review.Keys = append(review.Keys, uncommitted[id])
+		}
+		return "", nil, review
+	}
+
+	outcome := OutcomeSucceeded
+	for _, member := range group.Parallel {
+		if ex.cursor.Outcomes[member.ID] == OutcomeFailed {
+			outcome = OutcomeFailed
+		}
+	}
+	return outcome, uniqueStrings(artifacts), nil
+}
+
+func (ex *execution) completeMember(memberID, outcome string) error {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	ex.cursor.GroupCompleted = uniqueStrings(append(ex.cursor.GroupCompleted, memberID))
+	ex.cursor.Outcomes[memberID] = outcome
+	return ex.saveCursor()
+}
+
+func (ex *execution) countStep(step Step) error {
+	toolCallDelta := 0
+	if step.Executed && step.Command != "" {
+		toolCallDelta = 1
+	}
+	_, err := ex.runner.IncrementCounters(ex.jobID, 0, 1, toolCallDelta)
+	return err
+}
+
+func (ex *execution) skip(step Step, idx int, groupID string) error {
+	payload := stepPayload(step, idx, groupID)
+	payload["executed"] = false
+	payload["skipped"] = true
+	if _, err := ex.store.AppendEvent(ex.jobID, "adapter_step", payload, ex.now()); err != nil {
+		return err
+	}
+	ex.cursor.Outcomes[step.ID] = OutcomeSkipped
+	// A skipped group skips its members, so conditions may name either.
+	for _, member := range step.Parallel {
+		ex.cursor.Outcomes[normalizeStep(member).ID] = OutcomeSkipped
+	}
+	return nil
+}
+
+func (ex *execution) block(stepID, summary string, cause error) error {
+	reason := wrkrerrors.EAdapterFail
+	var werr wrkrerrors.WrkrError
+	if errors.As(cause, &werr) {
+		reason = werr.Code
+	}
+	_, _ = ex.runner.EmitCheckpoint(ex.jobID, runner.CheckpointInput{
+		Type:        "blocked",
+		Summary:     summary,
+		Status:      queue.StatusBlockedError,
+		ReasonCodes: []string{string(reason)},
+	})
+	_, _ = ex.runner.ChangeStatus(ex.jobID, queue.StatusBlockedError)
+	return cause
+}
+
+func (ex *execution) advance(nextStepIndex int) error {
+	ex.cursor.NextStepIndex = nextStepIndex
+	ex.cursor.GroupCompleted = nil
+	ex.cursor.PendingCheckpointID = ""
+	if ex.opts.OnAdvance != nil {
+		if err := ex.opts.OnAdvance(nextStepIndex); err != nil {
+			return err
+		}
+	}
+	return ex.saveCursor()
+}
+
+func (ex *execution) saveCursor() error {
+	if ex.opts.OnCursor == nil {
+		return nil
+	}
+	return ex.opts.OnCursor(Cursor{
+		NextStepIndex:       ex.cursor.NextStepIndex,
+		GroupCompleted:      append([]string(nil), ex.cursor.GroupCompleted...),
+		Outcomes:            copyOutcomes(ex.cursor.Outcomes),
+		PendingCheckpointID: ex.cursor.PendingCheckpointID,
+		Decisions:           copyOutcomes(ex.cursor.Decisions),
+	})
+}
+
+// resolveChoices loads the option chosen for each decision step so later
+// steps can branch on it.
+func (ex *execution) resolveChoices() error {
+	ex.choices = map[string]string{}
+	for stepID, checkpointID := range ex.cursor.Decisions {
+		decision, err := ex.runner.Decision(ex.jobID, checkpointID)
+		if err != nil {
+			return err
+		}
+		if decision.Option != "" {
+			ex.choices[stepID] = decision.Option
+		}
+	}
+	return nil
+}
+
+func (ex *execution) result(status queue.Status) RunResult {
+	return RunResult{
+		Status:        status,
+		NextStepIndex: ex.cursor.NextStepIndex,
+		Outcomes:      copyOutcomes(ex.cursor.Outcomes),
+	}
+}
+
+func stepPayload(step Step, idx int, groupID string) map[string]any {
+	payload := map[string]any{
+		"adapter":    "reference",
+		"step_id":    step.ID,
+		"step_index": idx,
+		"summary":    step.Summary,
+		"command":    step.Command,
+		"executed":   step.Executed,
+		"artifacts":  step.Artifacts,
+	}
+	if groupID != "" {
+		payload["group_id"] = groupID
+	}
+	if step.IdempotencyKey != "" {
+		payload["idempotency_key"] = step.IdempotencyKey
+	}
+	if len(step.Parallel) > 0 {
+		members := make([]string, 0, len(step.Parallel))
+		for _, member := range step.Parallel {
+			members = append(members, member.ID)
+		}
+		payload["parallel"] = members
+	}
+	return payload
+}
+
+// runCommand runs command from workspace, where its artifacts are captured.
+func runCommand(sb *sandbox.Sandbox, workspace, command string) (int, error) {
+	cmd, err := sb.Command("sh", "-lc", command)
+	if err != nil {
+		return 1, err
+	}
+	cmd.Dir = workspace
+	runErr := cmd.Run()
+	if runErr == nil {
+		return 0, nil
+	}
+	code := 1
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		code = exitErr.ExitCode()
+	}
+	return code, runErr
+}
+
+func copyOutcomes(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
 }
 
 func StepsFromInputs(inputs map[string]any) ([]Step, error) {
@@ -216,29 +731,187 @@ func StepsFromInputs(inputs map[string]any) ([]Step, error) {
 	}
 
 	steps := make([]Step, 0, len(items))
+	declared := map[string]struct{}{}
 	for idx, item := range items {
-		asMap, ok := item.(map[string]any)
-		if !ok {
+		step, err := stepFromInput(item, idx, declared)
+		if err != nil {
+			return nil, err
+		}
+		if rawMembers, ok := item.(map[string]any)["parallel"]; ok {
+			members, ok := rawMembers.([]any)
+			if !ok || len(members) == 0 {
+				return nil, wrkrerrors.New(
+					wrkrerrors.EInvalidInputSchema,
+					"jobspec step parallel must be a non-empty list",
+					map[string]any{"index": idx, "step_id": step.ID},
+				)
+			}
+			if step.Command != "" {
+				return nil, wrkrerrors.New(
+					wrkrerrors.EInvalidInputSchema,
+					"parallel group step must not declare a command",
+					map[string]any{"index": idx, "step_id": step.ID},
+				)
+			}
+			memberIDs := map[string]struct{}{}
+			for _, rawMember := range members {
+				member, err := stepFromInput(rawMember, idx, declared)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := rawMember.(map[string]any)["parallel"]; ok || member.DecisionNeeded {
+					return nil, wrkrerrors.New(
+						wrkrerrors.EInvalidInputSchema,
+						"parallel group members must not nest groups or require decisions",
+						map[string]any{"index": idx, "step_id": step.ID, "member_id": member.ID},
+					)
+				}
+				if _, ok := memberIDs[member.ID]; ok {
+					return nil, wrkrerrors.New(
+						wrkrerrors.EInvalidInputSchema,
+						"parallel group member ids must be unique",
+						map[string]any{"index": idx, "step_id": step.ID, "member_id": member.ID},
+					)
+				}
+				memberIDs[member.ID] = struct{}{}
+				step.Parallel = append(step.Parallel, member)
+			}
+			for id := range memberIDs {
+				declared[id] = struct{}{}
+			}
+		}
+		declared[step.ID] = struct{}{}
+		steps = append(steps, step)
+	}
+	for _, step := range steps {
+		if step.OnReject != "" && StepIndex(steps, step.OnReject) < 0 {
 			return nil, wrkrerrors.New(
 				wrkrerrors.EInvalidInputSchema,
-				"jobspec step must be an object",
-				map[string]any{"index": idx},
+				"step on_reject must reference a top-level step",
+				map[string]any{"step_id": step.ID, "on_reject": step.OnReject},
 			)
 		}
-		step := Step{
-			ID:             stringField(asMap, "id"),
-			Summary:        stringField(asMap, "summary"),
-			Command:        stringField(asMap, "command"),
-			DecisionNeeded: boolField(asMap, "decision_needed"),
-			RequiredAction: stringField(asMap, "required_action"),
-			Executed:       boolFieldWithDefault(asMap, "executed", true),
-		}
-		step.Artifacts = stringSliceField(asMap, "artifacts")
-		steps = append(steps, normalizeStep(step))
 	}
 	return steps, nil
 }
 
+// StepIndex returns the index of the top-level step with id, or -1.
+func StepIndex(steps []Step, id string) int {
+	for idx, step := range steps {
+		if step.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+// optionsField reads decision options given as names or
+// {name, description} objects.
+func optionsField(m map[string]any) ([]v1.DecisionOption, error) {
+	raw, ok := m["options"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("jobspec step options must be a list")
+	}
+	out := make([]v1.DecisionOption, 0, len(items))
+	for _, item := range items {
+		var opt v1.DecisionOption
+		switch typed := item.(type) {
+		case string:
+			opt.Name = typed
+		case map[string]any:
+			opt.Name = stringField(typed, "name")
+			opt.Description = strings.TrimSpace(stringField(typed, "description"))
+		default:
+			return nil, fmt.Errorf("jobspec step options entries must be names or objects")
+		}
+		opt.Name = strings.TrimSpace(opt.Name)
+		if opt.Name == "" {
+			return nil, fmt.Errorf("jobspec step options entries require a name")
+		}
+		out = append(out, opt)
+	}
+	return out, nil
+}
+
+func stepFromInput(item any, idx int, declared map[string]struct{}) (Step, error) {
+	asMap, ok := item.(map[string]any)
+	if !ok {
+		return Step{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"jobspec step must be an object",
+			map[string]any{"index": idx},
+		)
+	}
+	when, err := conditionsField(asMap, "when")
+	if err != nil {
+		return Step{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			err.Error(),
+			map[string]any{"index": idx},
+		)
+	}
+	options, err := optionsField(asMap)
+	if err != nil {
+		return Step{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			err.Error(),
+			map[string]any{"index": idx},
+		)
+	}
+	step := normalizeStep(Step{
+		ID:              stringField(asMap, "id"),
+		Summary:         stringField(asMap, "summary"),
+		Command:         stringField(asMap, "command"),
+		DecisionNeeded:  boolField(asMap, "decision_needed"),
+		RequiredAction:  stringField(asMap, "required_action"),
+		Options:         options,
+		OnReject:        stringField(asMap, "on_reject"),
+		ApprovalTimeout: intField(asMap, "approval_timeout_seconds"),
+		EscalateTo:      stringField(asMap, "escalate_to"),
+		CancelOnExpiry:  boolField(asMap, "cancel_on_expiry"),
+		Executed:        boolFieldWithDefault(asMap, "executed", true),
+		ContinueOnError: boolField(asMap, "continue_on_error"),
+		When:            when,
+		Artifacts:       stringSliceField(asMap, "artifacts"),
+		IdempotencyKey:  stringField(asMap, "idempotency_key"),
+	})
+	if !step.DecisionNeeded && (len(step.Options) > 0 || step.OnReject != "") {
+		return Step{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"options and on_reject require decision_needed",
+			map[string]any{"index": idx, "step_id": step.ID},
+		)
+	}
+	if step.ApprovalTimeout < 0 || (!step.DecisionNeeded && (step.ApprovalTimeout > 0 || step.EscalateTo != "" || step.CancelOnExpiry)) {
+		return Step{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"approval_timeout_seconds, escalate_to and cancel_on_expiry require decision_needed and a non-negative timeout",
+			map[string]any{"index": idx, "step_id": step.ID},
+		)
+	}
+	for _, cond := range step.When {
+		ref := cond.Step
+		if ref == "" {
+			ref = cond.Decision
+		}
+		if ref == "" {
+			continue
+		}
+		if _, ok := declared[ref]; !ok {
+			return Step{}, wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"step condition must reference an earlier step",
+				map[string]any{"index": idx, "step_id": step.ID, "when_step": ref},
+			)
+		}
+	}
+	return step, nil
+}
+
 func normalizeStep(step Step) Step {
 	step.ID = strings.TrimSpace(step.ID)
 	if step.ID == "" {
@@ -249,7 +922,10 @@ func normalizeStep(step Step) Step {
 		step.Summary = "reference adapter step " + step.ID
 	}
 	step.Command = strings.TrimSpace(step.Command)
+	step.IdempotencyKey = strings.TrimSpace(step.IdempotencyKey)
 	step.RequiredAction = strings.TrimSpace(step.RequiredAction)
+	step.OnReject = strings.TrimSpace(step.OnReject)
+	step.EscalateTo = strings.TrimSpace(step.EscalateTo)
 	if step.DecisionNeeded && step.RequiredAction == "" {
 		step.RequiredAction = "approval"
 	}
@@ -257,17 +933,39 @@ func normalizeStep(step Step) Step {
 		step.Artifacts = []string{}
 	}
 	step.Artifacts = uniqueStrings(step.Artifacts)
+	if len(step.Parallel) > 0 {
+		members := make([]Step, 0, len(step.Parallel))
+		for _, member := range step.Parallel {
+			members = append(members, normalizeStep(member))
+		}
+		step.Parallel = members
+	}
 	return step
 }
 
-func requiredAction(step Step) *v1.RequiredAction {
+func requiredAction(step Step, now time.Time) *v1.RequiredAction {
 	if !step.DecisionNeeded {
 		return nil
 	}
-	return &v1.RequiredAction{
-		Kind:         step.RequiredAction,
-		Instructions: "review and approve step " + step.ID,
+	action := &v1.RequiredAction{
+		Kind:           step.RequiredAction,
+		Instructions:   "review and approve step " + step.ID,
+		EscalateTo:     step.EscalateTo,
+		CancelOnExpiry: step.CancelOnExpiry,
+	}
+	if len(step.Options) > 0 {
+		names := make([]string, 0, len(step.Options))
+		for _, opt := range step.Options {
+			names = append(names, opt.Name)
+		}
+		action.Instructions = "choose an option for step " + step.ID + ": " + strings.Join(names, ", ")
+		action.Options = append([]v1.DecisionOption(nil), step.Options...)
+	}
+	if step.ApprovalTimeout > 0 {
+		deadline := now.UTC().Add(time.Duration(step.ApprovalTimeout) * time.Second)
+		action.Deadline = &deadline
 	}
+	return action
 }
 
 func uniqueStrings(items []string) []string {
@@ -300,6 +998,19 @@ func stringField(m map[string]any, key string) string {
 	return s
 }
 
+func intField(m map[string]any, key string) int {
+	switch v := m[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
 func boolField(m map[string]any, key string) bool {
 	v, ok := m[key]
 	if !ok {
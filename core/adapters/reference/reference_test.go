@@ -2,13 +2,19 @@ package reference
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/davidahmann/wrkr/core/budget"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/pack"
+	"github.com/davidahmann/wrkr/core/policy"
 	"github.com/davidahmann/wrkr/core/queue"
 	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/sign"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
@@ -148,3 +154,460 @@ func TestStepsFromInputs(t *testing.T) {
 		t.Fatalf("unexpected step: %+v", steps[0])
 	}
 }
+
+func TestRunConditionalStepsAndContinueOnError(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 14, 1, 45, 0, 0, time.UTC)
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_when")
+
+	result, err := Run("job_ref_when", []Step{
+		{ID: "lint", Summary: "lint", Command: "exit 2", Executed: true, ContinueOnError: true},
+		{ID: "fix", Summary: "fix lint", Command: "true", Executed: true, When: []Condition{{Step: "lint", Outcome: OutcomeFailed}}},
+		{ID: "ship", Summary: "ship", Command: "true", Executed: true, When: []Condition{{Step: "lint", Outcome: OutcomeSucceeded}}},
+		{ID: "staging", Summary: "staging only", Executed: false, When: []Condition{{Input: "env", Equals: "staging"}}},
+		{ID: "prod", Summary: "prod fanout", When: []Condition{{Input: "env", Equals: "prod"}}, Parallel: []Step{
+			{ID: "prod_a", Summary: "a", Executed: false},
+			{ID: "prod_b", Summary: "b", Executed: false},
+		}},
+		{ID: "prod_notice", Summary: "prod skipped", Executed: false, When: []Condition{{Step: "prod_a", Outcome: OutcomeSkipped}}},
+	}, RunOptions{
+		Now:    func() time.Time { return now },
+		Inputs: map[string]any{"env": "staging"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != queue.StatusCompleted {
+		t.Fatalf("expected completed, got %s", result.Status)
+	}
+	want := map[string]string{
+		"lint":        OutcomeFailed,
+		"fix":         OutcomeSucceeded,
+		"ship":        OutcomeSkipped,
+		"staging":     OutcomeSucceeded,
+		"prod":        OutcomeSkipped,
+		"prod_a":      OutcomeSkipped,
+		"prod_b":      OutcomeSkipped,
+		"prod_notice": OutcomeSucceeded,
+	}
+	for id, outcome := range want {
+		if result.Outcomes[id] != outcome {
+			t.Fatalf("expected %s outcome %s, got %+v", id, outcome, result.Outcomes)
+		}
+	}
+}
+
+func TestRunParallelGroupResumesWithoutRerunningFinishedMembers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 14, 1, 50, 0, 0, time.UTC)
+	workDir := t.TempDir()
+	counter := filepath.Join(workDir, "a.count")
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_parallel")
+
+	marker := filepath.Join(workDir, "b.ok")
+	steps := []Step{
+		{ID: "fanout", Summary: "fan out", Parallel: []Step{
+			{ID: "a", Summary: "a", Command: "echo x >> " + counter, Executed: true},
+			{ID: "b", Summary: "b", Command: "test -f " + marker, Executed: true},
+		}},
+		{ID: "join", Summary: "join", Executed: false},
+	}
+
+	var cursors []Cursor
+	onCursor := func(cursor Cursor) error {
+		cursors = append(cursors, cursor)
+		return nil
+	}
+	result, err := Run("job_ref_parallel", steps, RunOptions{Now: func() time.Time { return now }, OnCursor: onCursor})
+	if err == nil {
+		t.Fatal("expected parallel group failure")
+	}
+	if result.Status != queue.StatusBlockedError || result.NextStepIndex != 0 {
+		t.Fatalf("unexpected blocked result: %+v", result)
+	}
+	last := cursors[len(cursors)-1]
+	if len(last.GroupCompleted) != 1 || last.GroupCompleted[0] != "a" {
+		t.Fatalf("expected member a recorded as completed, got %+v", last)
+	}
+
+	if _, err := r.ChangeStatus("job_ref_parallel", queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	if err := os.WriteFile(marker, []byte("ok"), 0o600); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	result, err = Run("job_ref_parallel", steps, RunOptions{
+		Now:            func() time.Time { return now },
+		StartIndex:     last.NextStepIndex,
+		Outcomes:       last.Outcomes,
+		GroupCompleted: last.GroupCompleted,
+		OnCursor:       onCursor,
+	})
+	if err != nil {
+		t.Fatalf("resume Run: %v", err)
+	}
+	if result.Status != queue.StatusCompleted || result.Outcomes["fanout"] != OutcomeSucceeded {
+		t.Fatalf("unexpected resumed result: %+v", result)
+	}
+	raw, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatalf("read counter: %v", err)
+	}
+	if got := strings.Count(string(raw), "x"); got != 1 {
+		t.Fatalf("expected member a to run once, ran %d times", got)
+	}
+	state, err := r.Recover("job_ref_parallel")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.StepCount != 3 || state.ToolCallCount != 2 {
+		t.Fatalf("unexpected counters: steps=%d tools=%d", state.StepCount, state.ToolCallCount)
+	}
+}
+
+func TestStepsFromInputsConditionsAndGroups(t *testing.T) {
+	steps, err := StepsFromInputs(map[string]any{
+		"steps": []any{
+			map[string]any{"id": "build", "command": "true", "continue_on_error": true},
+			map[string]any{"id": "tests", "parallel": []any{
+				map[string]any{"id": "unit", "command": "true", "when": map[string]any{"step": "build"}},
+				map[string]any{"id": "e2e", "command": "true", "when": []any{map[string]any{"input": "e2e"}}},
+			}},
+			map[string]any{"id": "report", "when": map[string]any{"step": "unit", "outcome": "failed"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StepsFromInputs: %v", err)
+	}
+	if len(steps) != 3 || len(steps[1].Parallel) != 2 || !steps[0].ContinueOnError {
+		t.Fatalf("unexpected steps: %+v", steps)
+	}
+	if steps[1].Parallel[0].When[0].Outcome != OutcomeSucceeded {
+		t.Fatalf("expected default outcome succeeded, got %+v", steps[1].Parallel[0].When)
+	}
+
+	invalid := []map[string]any{
+		{"id": "a", "when": map[string]any{"step": "later"}},
+		{"id": "a", "when": map[string]any{"step": "x", "input": "y"}},
+		{"id": "a", "when": "bad"},
+		{"id": "g", "parallel": []any{}},
+		{"id": "g", "command": "true", "parallel": []any{map[string]any{"id": "m"}}},
+		{"id": "g", "parallel": []any{map[string]any{"id": "m"}, map[string]any{"id": "m"}}},
+		{"id": "g", "parallel": []any{map[string]any{"id": "m", "decision_needed": true}}},
+		{"id": "g", "parallel": []any{map[string]any{"id": "m"}, map[string]any{"id": "n", "when": map[string]any{"step": "m"}}}},
+	}
+	for i, step := range invalid {
+		if _, err := StepsFromInputs(map[string]any{"steps": []any{step}}); err == nil {
+			t.Fatalf("case %d: expected invalid step error", i)
+		}
+	}
+}
+
+func TestRunStopsWhenDecisionCheckpointFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 1, 50, 0, 0, time.UTC)
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_cp_fail")
+
+	// cancel_on_expiry without a deadline fails checkpoint validation.
+	_, err := Run("job_ref_cp_fail", []Step{
+		{ID: "gate", Summary: "gate", DecisionNeeded: true, CancelOnExpiry: true},
+	}, RunOptions{Now: func() time.Time { return now }})
+	if err == nil {
+		t.Fatal("expected decision checkpoint error")
+	}
+	state, err := r.Recover("job_ref_cp_fail")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusRunning {
+		t.Fatalf("expected job to stay running, got %s", state.Status)
+	}
+}
+
+func TestRunCapturesArtifactDigests(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 14, 1, 55, 0, 0, time.UTC)
+	workspace := t.TempDir()
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_capture")
+
+	capture := CaptureOptions{Mode: CaptureModeCapture, Workspace: workspace, StoreBlobs: true}
+	_, err := Run("job_ref_capture", []Step{
+		{ID: "write", Summary: "write", Command: "mkdir -p out && printf report > out/r.md", Artifacts: []string{"out/r.md"}, Executed: true},
+		{ID: "forget", Summary: "forget", Command: "true", Artifacts: []string{"out/missing.md"}, Executed: true},
+	}, RunOptions{Now: func() time.Time { return now }, Capture: capture})
+	if err == nil {
+		t.Fatal("expected missing artifact failure")
+	}
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EAdapterFail || werr.Details["missing_artifacts"] == nil {
+		t.Fatalf("expected missing artifact adapter failure, got %v", err)
+	}
+
+	captures, err := r.ListArtifactCaptures("job_ref_capture")
+	if err != nil {
+		t.Fatalf("ListArtifactCaptures: %v", err)
+	}
+	if len(captures) != 2 || len(captures[0].Artifacts) != 1 || len(captures[1].Missing) != 1 {
+		t.Fatalf("unexpected captures: %+v", captures)
+	}
+	digest := captures[0].Artifacts[0].SHA256
+	if digest != sign.SHA256Hex([]byte("report")) || captures[0].Artifacts[0].BlobSHA256 != digest {
+		t.Fatalf("expected content digest, got %+v", captures[0].Artifacts[0])
+	}
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	if raw, err := s.ReadBlob("job_ref_capture", digest); err != nil || string(raw) != "report" {
+		t.Fatalf("expected stored blob, got %q err=%v", raw, err)
+	}
+}
+
+func TestRunCaptureMasksBinaryBlobs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 1, 55, 0, 0, time.UTC)
+	workspace := t.TempDir()
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_binary")
+
+	capture := CaptureOptions{Mode: CaptureModeCapture, Workspace: workspace, StoreBlobs: true}
+	if _, err := Run("job_ref_binary", []Step{
+		{ID: "write", Summary: "write", Command: `printf '\377\376 token=s3cr3tvalue' > out.bin`, Artifacts: []string{"out.bin"}, Executed: true},
+	}, RunOptions{Now: func() time.Time { return now }, Capture: capture}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	captures, err := r.ListArtifactCaptures("job_ref_binary")
+	if err != nil {
+		t.Fatalf("ListArtifactCaptures: %v", err)
+	}
+	record := captures[0].Artifacts[0]
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	raw, err := s.ReadBlob("job_ref_binary", record.BlobSHA256)
+	if err != nil || !record.Redacted || string(raw) != "\xff\xfe token=[REDACTED]" {
+		t.Fatalf("expected masked binary blob, got %q redacted=%v err=%v", raw, record.Redacted, err)
+	}
+	if record.SHA256 != sign.SHA256Hex([]byte("\xff\xfe token=s3cr3tvalue")) {
+		t.Fatalf("expected on-disk digest, got %+v", record)
+	}
+}
+
+func TestRedactedCaptureVerifiesAgainstWorkspace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 1, 55, 0, 0, time.UTC)
+	workspace := t.TempDir()
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_verify")
+
+	capture := CaptureOptions{Mode: CaptureModeCapture, Workspace: workspace, StoreBlobs: true}
+	if _, err := Run("job_ref_verify", []Step{
+		{ID: "write", Summary: "write", Command: "printf 'deploy --token=s3cr3tvalue' > out.txt && printf ok > 'token=s3cr3tvalue.txt'", Artifacts: []string{"out.txt", "token=s3cr3tvalue.txt"}, Executed: true},
+	}, RunOptions{Now: func() time.Time { return now }, Capture: capture}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	exported, err := pack.ExportJobpack("job_ref_verify", pack.ExportOptions{
+		OutDir:          filepath.Join(t.TempDir(), "out"),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	result, err := pack.VerifyJobpackWorkspace(exported.Path, workspace)
+	if err != nil {
+		t.Fatalf("VerifyJobpackWorkspace: %v", err)
+	}
+	if result.ArtifactsVerified != 1 || result.ArtifactsSkipped != 1 {
+		t.Fatalf("expected one verified and one skipped artifact, got %+v", result)
+	}
+}
+
+func TestCaptureFromConfig(t *testing.T) {
+	opts, err := CaptureFromConfig(nil, "/work")
+	if err != nil || opts.Mode != CaptureModeReferenceOnly {
+		t.Fatalf("expected reference-only default, got %+v err=%v", opts, err)
+	}
+	opts, err = CaptureFromConfig(map[string]any{"capture": map[string]any{"mode": "capture", "store_blobs": true}}, "/work")
+	if err != nil || opts.Mode != CaptureModeCapture || !opts.StoreBlobs || opts.Workspace != "/work" {
+		t.Fatalf("unexpected capture options: %+v err=%v", opts, err)
+	}
+	if _, err := CaptureFromConfig(map[string]any{"capture": map[string]any{"mode": "raw"}}, ""); err == nil {
+		t.Fatal("expected invalid mode error")
+	}
+	if _, err := CaptureFromConfig(map[string]any{"capture": "capture"}, ""); err == nil {
+		t.Fatal("expected non-object capture error")
+	}
+}
+
+func TestRunReportsUncommittedStepForReview(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 14, 2, 0, 0, 0, time.UTC)
+	counter := filepath.Join(t.TempDir(), "deploy.count")
+
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_idem")
+
+	steps := []Step{
+		{ID: "deploy", Summary: "deploy", Command: "echo x >> " + counter, Executed: true},
+		{ID: "notify", Summary: "notify", Command: "true", Executed: true, IdempotencyKey: "notify-v1"},
+	}
+	// Simulate a crash after deploy started but before it committed.
+	if _, err := r.RecordIdempotencyPhase("job_ref_idem", "job_ref_idem:deploy:1", runner.IdempotencyStarted); err != nil {
+		t.Fatalf("RecordIdempotencyPhase: %v", err)
+	}
+	result, err := Run("job_ref_idem", steps, RunOptions{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != queue.StatusBlockedDecision || result.DecisionStepID != "deploy" || result.NextStepIndex != 0 {
+		t.Fatalf("expected review decision for deploy, got %+v", result)
+	}
+	checkpoints, err := r.ListCheckpoints("job_ref_idem")
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	last := checkpoints[len(checkpoints)-1]
+	if last.Type != "decision-needed" || len(last.ReasonCodes) != 1 || last.ReasonCodes[0] != string(wrkrerrors.EStepUncommitted) {
+		t.Fatalf("unexpected review checkpoint: %+v", last)
+	}
+	if _, err := os.Stat(counter); !os.IsNotExist(err) {
+		t.Fatalf("expected deploy not to re-run before review, stat err=%v", err)
+	}
+
+	if _, err := r.RecordIdempotencyKey("job_ref_idem", "notify-v1"); err != nil {
+		t.Fatalf("RecordIdempotencyKey: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_ref_idem", queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	result, err = Run("job_ref_idem", steps, RunOptions{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("resume Run: %v", err)
+	}
+	if result.Status != queue.StatusCompleted {
+		t.Fatalf("expected completed after review, got %+v", result)
+	}
+	state, err := r.Recover("job_ref_idem")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.IdempotencyPhases["job_ref_idem:deploy:1"] != runner.IdempotencyReview || !state.IdempotencyKeys["job_ref_idem:deploy:2"] {
+		t.Fatalf("unexpected idempotency phases: %+v", state.IdempotencyPhases)
+	}
+	if state.ToolCallCount != 2 {
+		t.Fatalf("expected both steps counted once, got tool calls=%d", state.ToolCallCount)
+	}
+	raw, err := os.ReadFile(counter)
+	if err != nil || strings.Count(string(raw), "x") != 1 {
+		t.Fatalf("expected deploy to run exactly once after review, got %q err=%v", raw, err)
+	}
+}
+
+func TestRunEnforcesPolicyBeforeSteps(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 14, 2, 10, 0, 0, time.UTC)
+	marker := filepath.Join(t.TempDir(), "deployed")
+
+	p, err := policy.Parse([]byte("commands:\n  deny: [\"*git push*\"]\nrequire_approval:\n  steps: [deploy]\n"), "policy.yaml")
+	if err != nil {
+		t.Fatalf("policy.Parse: %v", err)
+	}
+	r := setupReferenceRunner(t, now)
+	initReferenceJob(t, r, "job_ref_policy")
+	if _, err := r.RecordApprovalRules("job_ref_policy", []policy.ApprovalRule{{Name: "pair", Kinds: []string{"policy_approval"}, Quorum: 2}}); err != nil {
+		t.Fatalf("RecordApprovalRules: %v", err)
+	}
+
+	steps := []Step{
+		{ID: "build", Summary: "build", Command: "true", Executed: true},
+		{ID: "deploy", Summary: "deploy", Command: "touch " + marker, Executed: true},
+	}
+	var cursor Cursor
+	opts := RunOptions{
+		Now:    func() time.Time { return now },
+		Policy: p,
+		OnCursor: func(c Cursor) error {
+			cursor = c
+			return nil
+		},
+	}
+	result, err := Run("job_ref_policy", steps, opts)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != queue.StatusBlockedDecision || result.DecisionStepID != "deploy" || result.NextStepIndex != 1 {
+		t.Fatalf("expected policy approval stop before deploy, got %+v", result)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("deploy ran before approval, stat err=%v", err)
+	}
+	if cursor.PendingCheckpointID == "" {
+		t.Fatalf("expected pending checkpoint in cursor: %+v", cursor)
+	}
+
+	ex := &execution{jobID: "job_ref_policy", runner: r}
+	for _, approver := range []string{"alice", "bob"} {
+		if approved, err := ex.approved(cursor.PendingCheckpointID); err != nil || approved {
+			t.Fatalf("expected approval below quorum to gate the step, got %v err=%v", approved, err)
+		}
+		if _, err := r.ApproveCheckpoint("job_ref_policy", cursor.PendingCheckpointID, "ok", approver); err != nil {
+			t.Fatalf("ApproveCheckpoint: %v", err)
+		}
+	}
+	if _, err := r.Resume("job_ref_policy", runner.ResumeInput{}); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	opts.StartIndex = cursor.NextStepIndex
+	opts.Outcomes = cursor.Outcomes
+	opts.PendingCheckpointID = cursor.PendingCheckpointID
+	result, err = Run("job_ref_policy", steps, opts)
+	if err != nil {
+		t.Fatalf("Run after approval: %v", err)
+	}
+	if result.Status != queue.StatusCompleted {
+		t.Fatalf("expected completion after approval, got %+v", result)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("expected deploy to run after approval: %v", err)
+	}
+
+	initReferenceJob(t, r, "job_ref_policy_deny")
+	_, err = Run("job_ref_policy_deny", []Step{
+		{ID: "group", Summary: "group", Parallel: []Step{
+			{ID: "lint", Summary: "lint", Command: "true", Executed: true},
+			{ID: "push", Summary: "push", Command: "git push origin main", Executed: true},
+		}},
+	}, RunOptions{Now: func() time.Time { return now }, Policy: p})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EPolicyDenied || werr.Details["step_id"] != "push" {
+		t.Fatalf("expected policy denial for push, got %v", err)
+	}
+	checkpoints, err := r.ListCheckpoints("job_ref_policy_deny")
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	last := checkpoints[len(checkpoints)-1]
+	if last.Type != "blocked" || len(last.ReasonCodes) != 1 || last.ReasonCodes[0] != string(wrkrerrors.EPolicyDenied) {
+		t.Fatalf("unexpected denial checkpoint: %+v", last)
+	}
+	if state, _ := r.Recover("job_ref_policy_deny"); state.Status != queue.StatusBlockedError || state.ToolCallCount != 0 {
+		t.Fatalf("expected blocked job with no commands run, got %+v", state)
+	}
+}
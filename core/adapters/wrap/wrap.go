@@ -7,10 +7,13 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
 	"github.com/davidahmann/wrkr/core/queue"
 	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/sandbox"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
@@ -18,6 +21,9 @@ import (
 type RunOptions struct {
 	Now            func() time.Time
 	ExpectedOutput []string
+	Sandbox        *v1.SandboxSpec
+	Workspace      string
+	Policy         *policy.Policy
 }
 
 type RunResult struct {
@@ -64,8 +70,21 @@ func Run(jobID string, command []string, opts RunOptions) (RunResult, error) {
 		Status:  queue.StatusRunning,
 	})
 
+	if opts.Policy != nil {
+		if result, err := enforcePolicy(r, jobID, command, opts); err != nil {
+			return result, err
+		}
+	}
+
+	sb, err := sandbox.Apply(r, jobID, opts.Sandbox, opts.Workspace)
+	if err != nil {
+		return RunResult{JobID: jobID, Status: queue.StatusBlockedError, ExitCode: 1}, err
+	}
 	// #nosec G204 -- wrap intentionally executes user-supplied adapter command.
-	cmd := exec.Command(command[0], command[1:]...)
+	cmd, err := sb.Command(command[0], command[1:]...)
+	if err != nil {
+		return RunResult{}, err
+	}
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -91,6 +110,7 @@ func Run(jobID string, command []string, opts RunOptions) (RunResult, error) {
 		ArtifactsDelta: v1.ArtifactsDelta{
 			Added: opts.ExpectedOutput,
 		},
+		Attachments: logAttachments(stdout.Bytes(), stderr.Bytes()),
 	})
 
 	if runErr != nil {
@@ -133,6 +153,43 @@ func Run(jobID string, command []string, opts RunOptions) (RunResult, error) {
 	}, nil
 }
 
+// enforcePolicy records the policy and evaluates the wrapped command before
+// it runs. Wrap runs are not resumable, so a command that requires approval
+// stops on a decision-needed checkpoint without executing.
+func enforcePolicy(r *runner.Runner, jobID string, command []string, opts RunOptions) (RunResult, error) {
+	if _, err := r.RecordPolicy(jobID, *opts.Policy.Ref()); err != nil {
+		return RunResult{}, err
+	}
+	joined := strings.Join(command, " ")
+	decision := opts.Policy.Evaluate(policy.Subject{StepID: "wrap", Command: joined, Paths: opts.ExpectedOutput})
+	details := map[string]any{"job_id": jobID, "command": joined, "rule": decision.Rule, "policy_sha256": opts.Policy.SHA256}
+	switch decision.Action {
+	case policy.ActionDeny:
+		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
+			Type:        "blocked",
+			Summary:     fmt.Sprintf("wrap command denied by policy (%s)", decision.Rule),
+			Status:      queue.StatusBlockedError,
+			ReasonCodes: []string{string(wrkrerrors.EPolicyDenied)},
+		})
+		_, _ = r.ChangeStatus(jobID, queue.StatusBlockedError)
+		return RunResult{JobID: jobID, Status: queue.StatusBlockedError, ExitCode: 1}, wrkrerrors.New(wrkrerrors.EPolicyDenied, decision.Reason, details)
+	case policy.ActionApproval:
+		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
+			Type:    "decision-needed",
+			Summary: fmt.Sprintf("wrap command requires approval by policy (%s)", decision.Rule),
+			Status:  queue.StatusBlockedDecision,
+			RequiredAction: &v1.RequiredAction{
+				Kind:         "policy_approval",
+				Instructions: "approve the command, then run it through a jobspec or without the approval rule",
+			},
+		})
+		_, _ = r.ChangeStatus(jobID, queue.StatusBlockedDecision)
+		return RunResult{JobID: jobID, Status: queue.StatusBlockedDecision, ExitCode: 1}, wrkrerrors.New(wrkrerrors.ECheckpointApprovalRequired, decision.Reason, details)
+	default:
+		return RunResult{}, nil
+	}
+}
+
 func trimCommand(command []string) []string {
 	out := make([]string, 0, len(command))
 	for _, part := range command {
@@ -143,3 +200,28 @@ func trimCommand(command []string) []string {
 	}
 	return out
 }
+
+// logAttachments attaches the command's output to the progress checkpoint,
+// keeping the tail of logs over the attachment size limit.
+func logAttachments(stdout, stderr []byte) []runner.AttachmentInput {
+	out := []runner.AttachmentInput{}
+	for _, log := range []struct {
+		name string
+		data []byte
+	}{{"stdout.log", stdout}, {"stderr.log", stderr}} {
+		if len(log.data) == 0 {
+			continue
+		}
+		data := log.data
+		if len(data) > runner.MaxAttachmentBytes {
+			data = data[len(data)-runner.MaxAttachmentBytes:]
+			// Start on a rune boundary so text logs stay valid UTF-8 and
+			// are still redacted.
+			for len(data) > 0 && !utf8.RuneStart(data[0]) {
+				data = data[1:]
+			}
+		}
+		out = append(out, runner.AttachmentInput{Name: log.name, Data: data})
+	}
+	return out
+}
@@ -1,9 +1,18 @@
 package wrap
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
@@ -51,3 +60,119 @@ func TestRunFailure(t *testing.T) {
 		t.Fatalf("expected exit 7, got %d", result.ExitCode)
 	}
 }
+
+func TestRunAppliesSandboxProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("WRKR_WRAP_SECRET", "hidden")
+	now := time.Date(2026, 2, 14, 1, 5, 0, 0, time.UTC)
+
+	result, err := Run("job_wrap_sandbox", []string{"sh", "-c", `printf '%s' "${WRKR_WRAP_SECRET:-scrubbed}"`}, RunOptions{
+		Now:     func() time.Time { return now },
+		Sandbox: &v1.SandboxSpec{EnvAllowlist: []string{"PATH"}, MaxOpenFiles: 128},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Stdout != "scrubbed" {
+		t.Fatalf("expected scrubbed environment, got %q", result.Stdout)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	events, err := s.LoadEvents("job_wrap_sandbox")
+	if err != nil {
+		t.Fatalf("LoadEvents: %v", err)
+	}
+	found := false
+	for _, event := range events {
+		if event.Type == "sandbox_applied" && strings.Contains(string(event.Payload), "rlimit_nofile") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected sandbox_applied event")
+	}
+}
+
+func TestRunSandboxFailsClosed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+	now := time.Date(2026, 2, 14, 1, 10, 0, 0, time.UTC)
+
+	_, err := Run("job_wrap_unsafe", []string{"/bin/sh", "-c", "true"}, RunOptions{
+		Now:     func() time.Time { return now },
+		Sandbox: &v1.SandboxSpec{ReadOnly: true},
+	})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EUnsafeOperation {
+		t.Fatalf("expected unsafe operation, got %v", err)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	state, err := r.Recover("job_wrap_unsafe")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusBlockedError || len(state.LastReasonCodes) == 0 || state.LastReasonCodes[0] != string(wrkrerrors.EUnsafeOperation) {
+		t.Fatalf("expected blocked job with unsafe reason, got %+v", state)
+	}
+}
+
+func TestRunPolicyDeniesCommandBeforeExecution(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 14, 1, 10, 0, 0, time.UTC)
+	marker := filepath.Join(t.TempDir(), "ran")
+
+	p, err := policy.Parse([]byte("commands:\n  allow: [\"go *\"]\nrequire_approval:\n  commands: [\"go generate*\"]\n"), "policy.yaml")
+	if err != nil {
+		t.Fatalf("policy.Parse: %v", err)
+	}
+	result, err := Run("job_wrap_policy", []string{"touch", marker}, RunOptions{
+		Now:    func() time.Time { return now },
+		Policy: p,
+	})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EPolicyDenied || werr.Details["rule"] != "commands.allow" {
+		t.Fatalf("expected allowlist denial, got %v", err)
+	}
+	if result.Status != queue.StatusBlockedError {
+		t.Fatalf("expected blocked_error, got %+v", result)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("denied command ran, stat err=%v", err)
+	}
+
+	result, err = Run("job_wrap_policy_approval", []string{"go", "generate", "./..."}, RunOptions{
+		Now:    func() time.Time { return now },
+		Policy: p,
+	})
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.ECheckpointApprovalRequired || result.Status != queue.StatusBlockedDecision {
+		t.Fatalf("expected approval stop, got %+v err=%v", result, err)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	state, err := r.Recover("job_wrap_policy")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Policy == nil || state.Policy.SHA256 != p.SHA256 {
+		t.Fatalf("expected policy recorded in state, got %+v", state.Policy)
+	}
+}
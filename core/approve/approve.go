@@ -7,6 +7,9 @@ import (
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 )
 
+// UnknownActor is used when no approver or submitter identity is available.
+const UnknownActor = "unknown"
+
 func ResolveApprovedBy(explicit string) string {
 	v := strings.TrimSpace(explicit)
 	if v != "" {
@@ -18,7 +21,23 @@ func ResolveApprovedBy(explicit string) string {
 	if user := strings.TrimSpace(os.Getenv("USER")); user != "" {
 		return user
 	}
-	return "unknown"
+	return UnknownActor
+}
+
+// ResolveSubmittedBy names the job submitter, falling back to
+// WRKR_SUBMITTED_BY and then USER.
+func ResolveSubmittedBy(explicit string) string {
+	v := strings.TrimSpace(explicit)
+	if v != "" {
+		return v
+	}
+	if env := strings.TrimSpace(os.Getenv("WRKR_SUBMITTED_BY")); env != "" {
+		return env
+	}
+	if user := strings.TrimSpace(os.Getenv("USER")); user != "" {
+		return user
+	}
+	return UnknownActor
 }
 
 func ValidateReason(reason string) error {
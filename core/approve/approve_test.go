@@ -22,3 +22,15 @@ func TestValidateReason(t *testing.T) {
 		t.Fatalf("expected non-empty reason to pass, got %v", err)
 	}
 }
+
+func TestResolveSubmittedBy(t *testing.T) {
+	t.Setenv("WRKR_SUBMITTED_BY", "submitter")
+	if got := ResolveSubmittedBy(""); got != "submitter" {
+		t.Fatalf("expected submitter, got %q", got)
+	}
+	t.Setenv("WRKR_SUBMITTED_BY", "")
+	t.Setenv("USER", "")
+	if got := ResolveSubmittedBy(""); got != UnknownActor {
+		t.Fatalf("expected %s, got %q", UnknownActor, got)
+	}
+}
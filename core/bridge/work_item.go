@@ -49,6 +49,13 @@ func BuildWorkItemPayload(jobID string, checkpoint v1.Checkpoint, opts BuildOpti
 		ArtifactPointers: artifactPointers(checkpoint),
 		NextCommands:     nextCommands(jobID, checkpoint),
 	}
+	if action := checkpoint.RequiredAction; action != nil {
+		payload.Assignee = strings.TrimSpace(action.EscalateTo)
+		if action.Deadline != nil {
+			deadline := action.Deadline.UTC()
+			payload.Deadline = &deadline
+		}
+	}
 	if payload.CreatedAt.IsZero() {
 		payload.CreatedAt = now().UTC()
 	}
@@ -167,26 +174,40 @@ func renderTemplate(payload v1.WorkItemPayload, template string) string {
 	switch template {
 	case "jira":
 		return strings.TrimSpace(fmt.Sprintf(
-			"# JIRA Work Item\n\nSummary: `%s/%s` requires `%s`\n\nReason codes: %s\n\nNext commands:\n%s\n",
+			"# JIRA Work Item\n\nSummary: `%s/%s` requires `%s`\n\n%sReason codes: %s\n\nNext commands:\n%s\n",
 			payload.JobID,
 			payload.CheckpointID,
 			payload.RequiredAction,
+			routingLines(payload),
 			strings.Join(payload.ReasonCodes, ", "),
 			bulletList(payload.NextCommands),
 		))
 	default:
 		return strings.TrimSpace(fmt.Sprintf(
-			"# GitHub Work Item\n\nJob `%s` checkpoint `%s` (`%s`) requires `%s`.\n\nReason codes: %s\n\nNext commands:\n%s\n",
+			"# GitHub Work Item\n\nJob `%s` checkpoint `%s` (`%s`) requires `%s`.\n\n%sReason codes: %s\n\nNext commands:\n%s\n",
 			payload.JobID,
 			payload.CheckpointID,
 			payload.CheckpointType,
 			payload.RequiredAction,
+			routingLines(payload),
 			strings.Join(payload.ReasonCodes, ", "),
 			bulletList(payload.NextCommands),
 		))
 	}
 }
 
+// routingLines renders the assignee and deadline when set.
+func routingLines(payload v1.WorkItemPayload) string {
+	lines := ""
+	if payload.Assignee != "" {
+		lines += fmt.Sprintf("Assignee: %s\n\n", payload.Assignee)
+	}
+	if payload.Deadline != nil {
+		lines += fmt.Sprintf("Deadline: %s\n\n", payload.Deadline.UTC().Format(time.RFC3339))
+	}
+	return lines
+}
+
 func bulletList(items []string) string {
 	if len(items) == 0 {
 		return "- (none)"
@@ -2,6 +2,24 @@ package budget
 
 import "fmt"
 
+// Dimension names used in warning thresholds and reason summaries.
+const (
+	DimensionWallTime  = "wall_time_seconds"
+	DimensionRetries   = "retry_count"
+	DimensionSteps     = "step_count"
+	DimensionToolCalls = "tool_call_count"
+	DimensionCost      = "estimated_cost"
+	DimensionTokens    = "tokens"
+
+	// ReasonWarning marks the progress checkpoint emitted when a warning
+	// threshold is first crossed.
+	ReasonWarning = "budget_warning"
+
+	// ReasonPoolExhausted marks the blocked checkpoint emitted when the
+	// job's shared budget pool has no headroom left.
+	ReasonPoolExhausted = "budget_pool_exhausted"
+)
+
 type Limits struct {
 	MaxWallTimeSeconds int
 	MaxRetries         int
@@ -9,6 +27,13 @@ type Limits struct {
 	MaxToolCalls       int
 	MaxEstimatedCost   *float64
 	MaxTokens          *int
+	// WarnAtPercent maps a dimension to the share of its limit (1-99) at
+	// which a warning is raised. WarnRequiresDecision asks for approval to
+	// continue once a warning is raised.
+	WarnAtPercent        map[string]int `json:",omitempty"`
+	WarnRequiresDecision bool           `json:",omitempty"`
+	// Pool names a shared budget pool from the store's budget_pools.yaml.
+	Pool string `json:",omitempty"`
 }
 
 type Usage struct {
@@ -23,6 +48,18 @@ type Usage struct {
 type Result struct {
 	Exceeded   bool
 	Violations []string
+	Warnings   []Warning
+}
+
+type Warning struct {
+	Dimension string  `json:"dimension"`
+	Percent   int     `json:"percent"`
+	Usage     float64 `json:"usage"`
+	Limit     float64 `json:"limit"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s>=%d%%", w.Dimension, w.Percent)
 }
 
 func Evaluate(limits Limits, usage Usage) Result {
@@ -50,5 +87,45 @@ func Evaluate(limits Limits, usage Usage) Result {
 	return Result{
 		Exceeded:   len(violations) > 0,
 		Violations: violations,
+		Warnings:   warnings(limits, usage),
+	}
+}
+
+// warnings reports dimensions at or past their warning threshold but still
+// within their limit, in a fixed dimension order.
+func warnings(limits Limits, usage Usage) []Warning {
+	if len(limits.WarnAtPercent) == 0 {
+		return nil
+	}
+	type dimension struct {
+		name  string
+		limit float64
+		used  float64
+		ok    bool
+	}
+	dims := []dimension{
+		{DimensionWallTime, float64(limits.MaxWallTimeSeconds), float64(usage.WallTimeSeconds), limits.MaxWallTimeSeconds > 0},
+		{DimensionRetries, float64(limits.MaxRetries), float64(usage.RetryCount), limits.MaxRetries > 0},
+		{DimensionSteps, float64(limits.MaxStepCount), float64(usage.StepCount), limits.MaxStepCount > 0},
+		{DimensionToolCalls, float64(limits.MaxToolCalls), float64(usage.ToolCallCount), limits.MaxToolCalls > 0},
+	}
+	if limits.MaxEstimatedCost != nil && usage.EstimatedCost != nil {
+		dims = append(dims, dimension{DimensionCost, *limits.MaxEstimatedCost, *usage.EstimatedCost, *limits.MaxEstimatedCost > 0})
+	}
+	if limits.MaxTokens != nil && usage.Tokens != nil {
+		dims = append(dims, dimension{DimensionTokens, float64(*limits.MaxTokens), float64(*usage.Tokens), *limits.MaxTokens > 0})
+	}
+
+	out := []Warning{}
+	for _, dim := range dims {
+		percent := limits.WarnAtPercent[dim.name]
+		if !dim.ok || percent <= 0 || percent >= 100 {
+			continue
+		}
+		if dim.used > dim.limit || dim.used*100 < dim.limit*float64(percent) {
+			continue
+		}
+		out = append(out, Warning{Dimension: dim.name, Percent: percent, Usage: dim.used, Limit: dim.limit})
 	}
+	return out
 }
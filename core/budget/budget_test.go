@@ -47,3 +47,30 @@ func TestEvaluateWithinLimits(t *testing.T) {
 		t.Fatalf("expected not exceeded, got %v", result.Violations)
 	}
 }
+
+func TestEvaluateWarnings(t *testing.T) {
+	t.Parallel()
+
+	tokenLimit := 1000
+	tokens := 850
+	result := Evaluate(
+		Limits{
+			MaxStepCount:  10,
+			MaxToolCalls:  10,
+			MaxRetries:    4,
+			MaxTokens:     &tokenLimit,
+			WarnAtPercent: map[string]int{DimensionSteps: 80, DimensionToolCalls: 80, DimensionRetries: 50, DimensionTokens: 90},
+		},
+		Usage{StepCount: 8, ToolCallCount: 11, RetryCount: 1, Tokens: &tokens},
+	)
+	if !result.Exceeded || len(result.Violations) != 1 {
+		t.Fatalf("expected tool call violation, got %+v", result)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].String() != "step_count>=80%" {
+		t.Fatalf("expected only the step warning, got %+v", result.Warnings)
+	}
+
+	if got := Evaluate(Limits{MaxStepCount: 10}, Usage{StepCount: 9}); len(got.Warnings) != 0 {
+		t.Fatalf("expected no warnings without thresholds, got %+v", got.Warnings)
+	}
+}
@@ -0,0 +1,194 @@
+package budgetpool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigFile is the pool config file name under the store root.
+	ConfigFile = "budget_pools.yaml"
+
+	schemaID      = "wrkr.budget_pools"
+	schemaVersion = "v1"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// Config lists the named budget pools shared by jobs in a store.
+type Config struct {
+	SchemaID      string `yaml:"schema_id" json:"schema_id"`
+	SchemaVersion string `yaml:"schema_version" json:"schema_version"`
+	Pools         []Pool `yaml:"pools" json:"pools"`
+	Path          string `yaml:"-" json:"path"`
+}
+
+// Pool caps the aggregate usage of its member jobs within a window. Windows
+// are aligned to UTC: day starts at midnight, week on Monday.
+type Pool struct {
+	Name               string   `yaml:"name" json:"name"`
+	Window             string   `yaml:"window" json:"window"`
+	MaxEstimatedCost   *float64 `yaml:"max_estimated_cost,omitempty" json:"max_estimated_cost,omitempty"`
+	MaxTokens          *int     `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+	MaxWallTimeSeconds int      `yaml:"max_wall_time_seconds,omitempty" json:"max_wall_time_seconds,omitempty"`
+}
+
+// Usage is the aggregate usage of a pool's jobs inside the current window.
+type Usage struct {
+	WindowStart     time.Time `json:"window_start"`
+	WindowEnd       time.Time `json:"window_end"`
+	Jobs            []string  `json:"jobs"`
+	EstimatedCost   float64   `json:"estimated_cost"`
+	Tokens          int       `json:"tokens"`
+	WallTimeSeconds int       `json:"wall_time_seconds"`
+}
+
+// Headroom is what remains of each configured pool limit.
+type Headroom struct {
+	Pool       string   `json:"pool"`
+	Window     string   `json:"window"`
+	Usage      Usage    `json:"usage"`
+	Cost       *float64 `json:"remaining_estimated_cost,omitempty"`
+	Tokens     *int     `json:"remaining_tokens,omitempty"`
+	WallTime   *int     `json:"remaining_wall_time_seconds,omitempty"`
+	Exhausted  bool     `json:"exhausted"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// Load reads the pool config from the store root. A missing file yields an
+// empty config.
+func Load(storeRoot string) (*Config, error) {
+	path := filepath.Join(storeRoot, ConfigFile)
+	// #nosec G304 -- pool config lives at a fixed name under the store root.
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{SchemaID: schemaID, SchemaVersion: schemaVersion, Path: path}, nil
+		}
+		return nil, fmt.Errorf("read budget pools: %w", err)
+	}
+	return Parse(raw, path)
+}
+
+func Parse(raw []byte, path string) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid budget pools yaml", map[string]any{"path": path, "error": err.Error()})
+	}
+	if cfg.SchemaID == "" {
+		cfg.SchemaID = schemaID
+	}
+	if cfg.SchemaVersion == "" {
+		cfg.SchemaVersion = schemaVersion
+	}
+	if cfg.SchemaID != schemaID || cfg.SchemaVersion != schemaVersion {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"budget pools schema mismatch",
+			map[string]any{"path": path, "schema_id": cfg.SchemaID, "schema_version": cfg.SchemaVersion},
+		)
+	}
+	seen := map[string]struct{}{}
+	for _, p := range cfg.Pools {
+		details := map[string]any{"path": path, "pool": p.Name}
+		if !namePattern.MatchString(p.Name) {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget pool name is invalid", details)
+		}
+		if _, ok := seen[p.Name]; ok {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget pool name is duplicated", details)
+		}
+		seen[p.Name] = struct{}{}
+		if _, err := ParseWindow(p.Window); err != nil {
+			details["window"] = p.Window
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget pool window is invalid", details)
+		}
+		if p.MaxEstimatedCost == nil && p.MaxTokens == nil && p.MaxWallTimeSeconds <= 0 {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget pool needs at least one limit", details)
+		}
+	}
+	cfg.Path = path
+	return &cfg, nil
+}
+
+// Find returns the named pool or an E_INVALID_INPUT_SCHEMA error.
+func (c *Config) Find(name string) (Pool, error) {
+	if c != nil {
+		for _, p := range c.Pools {
+			if p.Name == name {
+				return p, nil
+			}
+		}
+	}
+	path := ""
+	if c != nil {
+		path = c.Path
+	}
+	return Pool{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "unknown budget pool", map[string]any{"pool": name, "config": path})
+}
+
+// ParseWindow accepts hour, day, week or a Go duration of at least a minute.
+func ParseWindow(window string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(window)) {
+	case "hour":
+		return time.Hour, nil
+	case "day":
+		return 24 * time.Hour, nil
+	case "week":
+		return 7 * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("window shorter than a minute")
+	}
+	return d, nil
+}
+
+// WindowBounds returns the window containing now.
+func (p Pool) WindowBounds(now time.Time) (time.Time, time.Time) {
+	d, err := ParseWindow(p.Window)
+	if err != nil {
+		d = 24 * time.Hour
+	}
+	start := now.UTC().Truncate(d)
+	return start, start.Add(d)
+}
+
+// Evaluate reports the remaining headroom. A pool is exhausted once usage
+// reaches any limit.
+func Evaluate(p Pool, usage Usage) Headroom {
+	h := Headroom{Pool: p.Name, Window: p.Window, Usage: usage}
+	if p.MaxEstimatedCost != nil {
+		remaining := *p.MaxEstimatedCost - usage.EstimatedCost
+		h.Cost = &remaining
+		if remaining <= 0 {
+			h.Violations = append(h.Violations, fmt.Sprintf("pool %s estimated_cost>=%.4f", p.Name, *p.MaxEstimatedCost))
+		}
+	}
+	if p.MaxTokens != nil {
+		remaining := *p.MaxTokens - usage.Tokens
+		h.Tokens = &remaining
+		if remaining <= 0 {
+			h.Violations = append(h.Violations, fmt.Sprintf("pool %s tokens>=%d", p.Name, *p.MaxTokens))
+		}
+	}
+	if p.MaxWallTimeSeconds > 0 {
+		remaining := p.MaxWallTimeSeconds - usage.WallTimeSeconds
+		h.WallTime = &remaining
+		if remaining <= 0 {
+			h.Violations = append(h.Violations, fmt.Sprintf("pool %s wall_time_seconds>=%d", p.Name, p.MaxWallTimeSeconds))
+		}
+	}
+	h.Exhausted = len(h.Violations) > 0
+	return h
+}
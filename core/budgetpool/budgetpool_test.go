@@ -0,0 +1,90 @@
+package budgetpool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAndFind(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]byte(`
+pools:
+  - name: team-a
+    window: day
+    max_estimated_cost: 5
+    max_tokens: 1000
+  - name: nightly
+    window: 6h
+    max_wall_time_seconds: 3600
+`), "budget_pools.yaml")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	pool, err := cfg.Find("team-a")
+	if err != nil || *pool.MaxEstimatedCost != 5 {
+		t.Fatalf("Find: %+v %v", pool, err)
+	}
+	if _, err := cfg.Find("missing"); err == nil {
+		t.Fatal("expected unknown pool error")
+	}
+
+	for name, raw := range map[string]string{
+		"bad window":   "pools: [{name: a, window: soon, max_tokens: 1}]",
+		"short window": "pools: [{name: a, window: 10s, max_tokens: 1}]",
+		"no limits":    "pools: [{name: a, window: day}]",
+		"duplicate":    "pools: [{name: a, window: day, max_tokens: 1}, {name: a, window: hour, max_tokens: 1}]",
+		"bad name":     "pools: [{name: 'a/b', window: day, max_tokens: 1}]",
+		"schema":       "schema_id: other\n",
+	} {
+		if _, err := Parse([]byte(raw), "x"); err == nil {
+			t.Fatalf("%s: expected parse error", name)
+		}
+	}
+}
+
+func TestLoadMissingConfigIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	cfg, err := Load(root)
+	if err != nil || len(cfg.Pools) != 0 || cfg.Path != filepath.Join(root, ConfigFile) {
+		t.Fatalf("expected empty config, got %+v %v", cfg, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ConfigFile), []byte("pools: [{name: a, window: week, max_tokens: 10}]\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if cfg, err = Load(root); err != nil || len(cfg.Pools) != 1 {
+		t.Fatalf("Load: %+v %v", cfg, err)
+	}
+}
+
+func TestWindowBoundsAndEvaluate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 18, 15, 4, 0, 0, time.UTC) // Wednesday
+	start, end := Pool{Window: "day"}.WindowBounds(now)
+	if !start.Equal(time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC)) || !end.Equal(start.Add(24*time.Hour)) {
+		t.Fatalf("unexpected day window: %s %s", start, end)
+	}
+	start, _ = Pool{Window: "week"}.WindowBounds(now)
+	if !start.Equal(time.Date(2026, 2, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected week to start Monday, got %s", start)
+	}
+
+	cost := 5.0
+	tokens := 1000
+	pool := Pool{Name: "team-a", Window: "day", MaxEstimatedCost: &cost, MaxTokens: &tokens}
+	h := Evaluate(pool, Usage{EstimatedCost: 1.5, Tokens: 1000})
+	if !h.Exhausted || *h.Cost != 3.5 || *h.Tokens != 0 || h.WallTime != nil {
+		t.Fatalf("unexpected headroom: %+v", h)
+	}
+	if len(h.Violations) != 1 || h.Violations[0] != "pool team-a tokens>=1000" {
+		t.Fatalf("unexpected violations: %+v", h.Violations)
+	}
+	if Evaluate(pool, Usage{Tokens: 999}).Exhausted {
+		t.Fatal("expected headroom below the limit")
+	}
+}
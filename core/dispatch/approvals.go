@@ -0,0 +1,206 @@
+package dispatch
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/bridge"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+// PendingDecision is one decision-needed checkpoint a job is blocked on.
+type PendingDecision struct {
+	JobID        string     `json:"job_id"`
+	CheckpointID string     `json:"checkpoint_id"`
+	Kind         string     `json:"kind,omitempty"`
+	Summary      string     `json:"summary"`
+	CreatedAt    time.Time  `json:"created_at"`
+	AgeSeconds   int64      `json:"age_seconds"`
+	Deadline     *time.Time `json:"deadline,omitempty"`
+	Expired      bool       `json:"expired"`
+	Escalated    bool       `json:"escalated"`
+	EscalateTo   string     `json:"escalate_to,omitempty"`
+	Approvals    int        `json:"approvals"`
+	Quorum       int        `json:"quorum"`
+}
+
+// Expiry reports what happened to one expired decision.
+type Expiry struct {
+	JobID        string       `json:"job_id"`
+	CheckpointID string       `json:"checkpoint_id"`
+	Status       queue.Status `json:"status"`
+	EscalationID string       `json:"escalation_checkpoint_id,omitempty"`
+	EscalateTo   string       `json:"escalate_to,omitempty"`
+	WorkItemPath string       `json:"work_item_path,omitempty"`
+	TemplatePath string       `json:"template_path,omitempty"`
+	ReasonCode   string       `json:"reason_code"`
+}
+
+type ExpireOptions struct {
+	Now             func() time.Time
+	OutDir          string
+	Template        string
+	ProducerVersion string
+}
+
+// PendingDecisions lists unresolved decisions across the store, oldest first.
+func PendingDecisions(now func() time.Time) ([]PendingDecision, error) {
+	if now == nil {
+		now = time.Now
+	}
+	s, r, err := openStoreRunner(now)
+	if err != nil {
+		return nil, err
+	}
+	jobIDs, err := s.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PendingDecision, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		cp, decision, err := r.PendingDecision(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if cp == nil {
+			continue
+		}
+		pending := PendingDecision{
+			JobID:        jobID,
+			CheckpointID: cp.CheckpointID,
+			Summary:      cp.Summary,
+			CreatedAt:    cp.CreatedAt.UTC(),
+			AgeSeconds:   int64(now().UTC().Sub(cp.CreatedAt.UTC()).Seconds()),
+			Escalated:    slices.Contains(cp.ReasonCodes, runner.ReasonApprovalEscalated),
+			Approvals:    len(decision.Approvers),
+			Quorum:       decision.Quorum,
+		}
+		if action := cp.RequiredAction; action != nil {
+			pending.Kind = action.Kind
+			pending.EscalateTo = action.EscalateTo
+			if action.Deadline != nil {
+				deadline := action.Deadline.UTC()
+				pending.Deadline = &deadline
+				pending.Expired = !now().UTC().Before(deadline)
+			}
+		}
+		out = append(out, pending)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].JobID < out[j].JobID
+	})
+	return out, nil
+}
+
+// ExpireDecisions escalates or cancels every decision past its deadline. An
+// escalation re-issues the bridge work item, addressed to the escalation
+// target, and repoints the reference decision step at the new checkpoint.
+func ExpireDecisions(opts ExpireOptions) ([]Expiry, error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	s, r, err := openStoreRunner(now)
+	if err != nil {
+		return nil, err
+	}
+	jobIDs, err := s.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Expiry, 0)
+	for _, jobID := range jobIDs {
+		expired, _, err := r.PendingDecision(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if expired == nil {
+			continue
+		}
+		cp, err := r.ExpireDecision(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if cp == nil {
+			continue
+		}
+		state, err := r.Recover(jobID)
+		if err != nil {
+			return nil, err
+		}
+		expiry := Expiry{
+			JobID:        jobID,
+			CheckpointID: expired.CheckpointID,
+			Status:       state.Status,
+			ReasonCode:   runner.ReasonApprovalExpired,
+		}
+		if cp.Type == "decision-needed" {
+			expiry.EscalationID = cp.CheckpointID
+			expiry.EscalateTo = cp.RequiredAction.EscalateTo
+			expiry.ReasonCode = runner.ReasonApprovalEscalated
+			if err := repointDecision(s, jobID, expired.CheckpointID, cp.CheckpointID, now); err != nil {
+				return nil, err
+			}
+			payload, err := bridge.BuildWorkItemPayload(jobID, *cp, bridge.BuildOptions{Now: now, ProducerVersion: opts.ProducerVersion})
+			if err != nil {
+				return nil, err
+			}
+			written, err := bridge.WriteWorkItemPayload(payload, opts.OutDir, opts.Template)
+			if err != nil {
+				return nil, err
+			}
+			expiry.WorkItemPath = written.JSONPath
+			expiry.TemplatePath = written.TemplatePath
+		}
+		out = append(out, expiry)
+	}
+	return out, nil
+}
+
+// repointDecision moves reference decision steps and the adapter's pending
+// approval from an expired checkpoint to its escalation so decide, reject and
+// option branches follow it.
+func repointDecision(s *store.LocalStore, jobID, from, to string, now func() time.Time) error {
+	runtimeCfg, err := LoadRuntimeConfig(s, jobID)
+	if err != nil || runtimeCfg == nil {
+		return err
+	}
+	changed := false
+	for stepID, cpID := range runtimeCfg.DecisionCheckpoints {
+		if cpID == from {
+			runtimeCfg.DecisionCheckpoints[stepID] = to
+			changed = true
+		}
+	}
+	if strings.TrimSpace(runtimeCfg.PendingCheckpointID) == from {
+		runtimeCfg.PendingCheckpointID = to
+		changed = true
+	}
+	if runtimeCfg.LLM != nil && runtimeCfg.LLM.PendingCheckpointID == from {
+		runtimeCfg.LLM.PendingCheckpointID = to
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return SaveRuntimeConfig(s, jobID, *runtimeCfg, now())
+}
+
+func openStoreRunner(now func() time.Time) (*store.LocalStore, *runner.Runner, error) {
+	s, err := store.New("")
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := runner.New(s, runner.Options{Now: now})
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, r, nil
+}
@@ -0,0 +1,176 @@
+package dispatch
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/adapters/llm"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+const expiringJobSpec = `schema_id: wrkr.jobspec
+schema_version: v1
+created_at: "2026-02-14T04:00:00Z"
+producer_version: test
+name: expiring-decision
+objective: test decision expiry
+inputs:
+  steps:
+    - id: review
+      summary: pick a strategy
+      decision_needed: true
+      options: [fast, thorough]
+      approval_timeout_seconds: 3600
+      EXPIRY_FIELD
+    - id: fast
+      summary: fast path
+      when: { decision: review, option: fast }
+    - id: thorough
+      summary: thorough path
+      when: { decision: review, option: thorough }
+expected_artifacts: []
+adapter: { name: reference }
+budgets:
+  max_wall_time_seconds: 100000
+  max_retries: 1
+  max_step_count: 10
+  max_tool_calls: 10
+checkpoint_policy:
+  min_interval_seconds: 86400
+  required_types: [plan, completed]
+environment_fingerprint:
+  rules: [go_version]
+`
+
+func TestExpireDecisionsEscalatesOrCancels(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	now := time.Date(2026, 2, 14, 4, 0, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	specs := map[string]string{
+		"escalate.yaml": strings.Replace(expiringJobSpec, "EXPIRY_FIELD", "escalate_to: release-lead", 1),
+		"cancel.yaml":   strings.Replace(expiringJobSpec, "EXPIRY_FIELD", "cancel_on_expiry: true", 1),
+	}
+	for name, body := range specs {
+		if err := os.WriteFile(name, []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if _, err := Submit("cancel.yaml", SubmitOptions{Now: nowFn, JobID: "job_cancel"}); err != nil {
+		t.Fatalf("Submit cancel: %v", err)
+	}
+	now = now.Add(10 * time.Minute)
+	if _, err := Submit("escalate.yaml", SubmitOptions{Now: nowFn, JobID: "job_escalate"}); err != nil {
+		t.Fatalf("Submit escalate: %v", err)
+	}
+
+	now = now.Add(20 * time.Minute)
+	pending, err := PendingDecisions(nowFn)
+	if err != nil {
+		t.Fatalf("PendingDecisions: %v", err)
+	}
+	if len(pending) != 2 || pending[0].JobID != "job_cancel" || pending[0].AgeSeconds != 1800 || pending[1].AgeSeconds != 1200 {
+		t.Fatalf("expected oldest decision first, got %+v", pending)
+	}
+	if pending[0].Deadline == nil || pending[0].Expired || pending[1].EscalateTo != "release-lead" {
+		t.Fatalf("unexpected deadline fields: %+v", pending)
+	}
+	expired, err := ExpireDecisions(ExpireOptions{Now: nowFn})
+	if err != nil || len(expired) != 0 {
+		t.Fatalf("expected nothing to expire yet, got %+v %v", expired, err)
+	}
+
+	now = now.Add(time.Hour)
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: nowFn})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	original := pending[1].CheckpointID
+	if _, err := r.DecideCheckpoint("job_escalate", original, "fast", "late", "lead"); err == nil {
+		t.Fatal("expected approval after the deadline to fail")
+	}
+
+	expired, err = ExpireDecisions(ExpireOptions{Now: nowFn, Template: "github"})
+	if err != nil {
+		t.Fatalf("ExpireDecisions: %v", err)
+	}
+	if len(expired) != 2 {
+		t.Fatalf("expected two expiries, got %+v", expired)
+	}
+	canceled, escalated := expired[0], expired[1]
+	if canceled.JobID != "job_cancel" || canceled.Status != queue.StatusCanceled || canceled.ReasonCode != runner.ReasonApprovalExpired {
+		t.Fatalf("expected canceled job, got %+v", canceled)
+	}
+	if escalated.Status != queue.StatusBlockedDecision || escalated.EscalateTo != "release-lead" || escalated.EscalationID == "" {
+		t.Fatalf("expected escalation, got %+v", escalated)
+	}
+	raw, err := os.ReadFile(escalated.WorkItemPath)
+	if err != nil {
+		t.Fatalf("read work item: %v", err)
+	}
+	if !strings.Contains(string(raw), `"assignee":"release-lead"`) || !strings.Contains(string(raw), runner.ReasonApprovalEscalated) {
+		t.Fatalf("expected work item addressed to the escalation target, got %s", raw)
+	}
+	if again, err := ExpireDecisions(ExpireOptions{Now: nowFn}); err != nil || len(again) != 0 {
+		t.Fatalf("expected escalation to expire once, got %+v %v", again, err)
+	}
+
+	pending, err = PendingDecisions(nowFn)
+	if err != nil {
+		t.Fatalf("PendingDecisions after expiry: %v", err)
+	}
+	if len(pending) != 1 || pending[0].CheckpointID != escalated.EscalationID || !pending[0].Escalated {
+		t.Fatalf("expected only the escalation pending, got %+v", pending)
+	}
+	if _, err := r.DecideCheckpoint("job_escalate", escalated.EscalationID, "thorough", "escalated review", "release-lead"); err != nil {
+		t.Fatalf("DecideCheckpoint escalation: %v", err)
+	}
+	resumed, err := Resume("job_escalate", ResumeOptions{Now: nowFn})
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	cfg, err := LoadRuntimeConfig(s, "job_escalate")
+	if err != nil {
+		t.Fatalf("LoadRuntimeConfig: %v", err)
+	}
+	if resumed.Status != queue.StatusCompleted || cfg.StepOutcomes["thorough"] != "succeeded" || cfg.StepOutcomes["fast"] != "skipped" {
+		t.Fatalf("expected escalated decision to pick the branch, got %+v %+v", resumed, cfg.StepOutcomes)
+	}
+}
+
+func TestRepointDecisionMovesLLMPendingCheckpoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 4, 0, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	cfg := RuntimeConfig{
+		Adapter: "llm",
+		LLM:     &llm.State{PendingToolCallID: "call_1", PendingCheckpointID: "cp_3"},
+	}
+	if err := SaveRuntimeConfig(s, "job_llm_escalate", cfg, now); err != nil {
+		t.Fatalf("SaveRuntimeConfig: %v", err)
+	}
+	if err := repointDecision(s, "job_llm_escalate", "cp_3", "cp_5", nowFn); err != nil {
+		t.Fatalf("repointDecision: %v", err)
+	}
+	loaded, err := LoadRuntimeConfig(s, "job_llm_escalate")
+	if err != nil {
+		t.Fatalf("LoadRuntimeConfig: %v", err)
+	}
+	if loaded.LLM == nil || loaded.LLM.PendingCheckpointID != "cp_5" || loaded.LLM.PendingToolCallID != "call_1" {
+		t.Fatalf("expected llm approval to follow the escalation, got %+v", loaded.LLM)
+	}
+}
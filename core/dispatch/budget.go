@@ -0,0 +1,179 @@
+package dispatch
+
+import (
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+// RaiseBudgetOptions carries the limits to raise; unset fields keep their
+// current value.
+type RaiseBudgetOptions struct {
+	Now        func() time.Time
+	Limits     budget.Limits
+	Reason     string
+	ApprovedBy string
+}
+
+type RaiseBudgetResult struct {
+	JobID     string             `json:"job_id"`
+	Status    queue.Status       `json:"status"`
+	Amendment v1.BudgetAmendment `json:"amendment"`
+	Budgets   budget.Limits      `json:"budgets"`
+}
+
+// RaiseBudget raises budget limits on a submitted job, records a
+// budget_amended event and updates runtime_config.json so that a later
+// resume continues under the new limits.
+func RaiseBudget(jobID string, opts RaiseBudgetOptions) (RaiseBudgetResult, error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	jobID = strings.TrimSpace(jobID)
+	if strings.TrimSpace(opts.Reason) == "" {
+		return RaiseBudgetResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget raise reason is required", nil)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	exists, err := s.JobExists(jobID)
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	if !exists {
+		return RaiseBudgetResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "job not found", map[string]any{"job_id": jobID})
+	}
+	r, err := runner.New(s, runner.Options{Now: now})
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	if state.Status == queue.StatusCompleted || state.Status == queue.StatusCanceled {
+		return RaiseBudgetResult{}, wrkrerrors.New(
+			wrkrerrors.EInvalidStateTransition,
+			"cannot raise budget on a finished job",
+			map[string]any{"job_id": jobID, "status": state.Status},
+		)
+	}
+	runtimeCfg, err := LoadRuntimeConfig(s, jobID)
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	if runtimeCfg == nil {
+		return RaiseBudgetResult{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"job has no submitted budgets to raise",
+			map[string]any{"job_id": jobID},
+		)
+	}
+
+	raised, changes, err := raiseLimits(runtimeCfg.Budgets, opts.Limits)
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	state, err = r.AmendBudget(jobID, v1.BudgetAmendment{
+		AmendedAt:  now().UTC(),
+		Reason:     opts.Reason,
+		ApprovedBy: opts.ApprovedBy,
+		Changes:    changes,
+	})
+	if err != nil {
+		return RaiseBudgetResult{}, err
+	}
+	runtimeCfg.Budgets = raised
+	if err := SaveRuntimeConfig(s, jobID, *runtimeCfg, now()); err != nil {
+		return RaiseBudgetResult{}, err
+	}
+
+	return RaiseBudgetResult{
+		JobID:     jobID,
+		Status:    state.Status,
+		Amendment: state.BudgetAmendments[len(state.BudgetAmendments)-1],
+		Budgets:   raised,
+	}, nil
+}
+
+// raiseLimits applies the requested limits on top of current. Every requested
+// limit must already be set and can only go up; lowering or adding a limit
+// is a new submission, not an amendment.
+func raiseLimits(current, requested budget.Limits) (budget.Limits, []v1.BudgetLimitChange, error) {
+	out := current
+	changes := []v1.BudgetLimitChange{}
+	raise := func(dimension string, from, to float64) error {
+		if from <= 0 {
+			return wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"budget limit is not set and cannot be raised",
+				map[string]any{"dimension": dimension},
+			)
+		}
+		if to <= from {
+			return wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"budget limit can only be raised",
+				map[string]any{"dimension": dimension, "current": from, "requested": to},
+			)
+		}
+		changes = append(changes, v1.BudgetLimitChange{Dimension: dimension, From: from, To: to})
+		return nil
+	}
+
+	ints := []struct {
+		dimension string
+		current   int
+		requested int
+		target    *int
+	}{
+		{budget.DimensionWallTime, current.MaxWallTimeSeconds, requested.MaxWallTimeSeconds, &out.MaxWallTimeSeconds},
+		{budget.DimensionRetries, current.MaxRetries, requested.MaxRetries, &out.MaxRetries},
+		{budget.DimensionSteps, current.MaxStepCount, requested.MaxStepCount, &out.MaxStepCount},
+		{budget.DimensionToolCalls, current.MaxToolCalls, requested.MaxToolCalls, &out.MaxToolCalls},
+	}
+	for _, limit := range ints {
+		if limit.requested == 0 {
+			continue
+		}
+		if err := raise(limit.dimension, float64(limit.current), float64(limit.requested)); err != nil {
+			return budget.Limits{}, nil, err
+		}
+		*limit.target = limit.requested
+	}
+	if requested.MaxTokens != nil {
+		from := 0
+		if current.MaxTokens != nil {
+			from = *current.MaxTokens
+		}
+		if err := raise(budget.DimensionTokens, float64(from), float64(*requested.MaxTokens)); err != nil {
+			return budget.Limits{}, nil, err
+		}
+		v := *requested.MaxTokens
+		out.MaxTokens = &v
+	}
+	if requested.MaxEstimatedCost != nil {
+		from := 0.0
+		if current.MaxEstimatedCost != nil {
+			from = *current.MaxEstimatedCost
+		}
+		if err := raise(budget.DimensionCost, from, *requested.MaxEstimatedCost); err != nil {
+			return budget.Limits{}, nil, err
+		}
+		v := *requested.MaxEstimatedCost
+		out.MaxEstimatedCost = &v
+	}
+	if len(changes) == 0 {
+		return budget.Limits{}, nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "at least one budget limit to raise is required", nil)
+	}
+	return out, changes, nil
+}
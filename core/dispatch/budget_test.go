@@ -0,0 +1,183 @@
+package dispatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	"github.com/davidahmann/wrkr/core/budgetpool"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+func TestRaiseBudgetLetsResumeContinue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	now := time.Date(2026, 2, 14, 2, 30, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	if err := os.WriteFile("jobspec.yaml", []byte(`schema_id: wrkr.jobspec
+schema_version: v1
+created_at: "2026-02-14T02:30:00Z"
+producer_version: test
+name: raise-budget
+objective: test budget raise
+inputs:
+  steps:
+    - id: one
+      summary: first
+    - id: two
+      summary: second
+    - id: three
+      summary: third
+expected_artifacts: []
+adapter: { name: reference }
+budgets:
+  max_wall_time_seconds: 100
+  max_retries: 1
+  max_step_count: 1
+  max_tool_calls: 10
+checkpoint_policy:
+  min_interval_seconds: 1
+  required_types: [plan, progress, blocked]
+environment_fingerprint:
+  rules: [go_version]
+`), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+	if _, err := Submit("jobspec.yaml", SubmitOptions{Now: nowFn, JobID: "job_raise"}); err == nil {
+		t.Fatal("expected budget exceeded error")
+	}
+
+	maxSteps := budget.Limits{MaxStepCount: 5}
+	if _, err := RaiseBudget("job_raise", RaiseBudgetOptions{Now: nowFn, Limits: maxSteps, ApprovedBy: "lead"}); err == nil {
+		t.Fatal("expected missing reason to fail")
+	}
+	if _, err := RaiseBudget("job_raise", RaiseBudgetOptions{Now: nowFn, Limits: budget.Limits{MaxRetries: 0, MaxToolCalls: 5}, Reason: "r", ApprovedBy: "lead"}); err == nil {
+		t.Fatal("expected lowering a limit to fail")
+	}
+	tokens := 100
+	if _, err := RaiseBudget("job_raise", RaiseBudgetOptions{Now: nowFn, Limits: budget.Limits{MaxTokens: &tokens}, Reason: "r", ApprovedBy: "lead"}); err == nil {
+		t.Fatal("expected raising an unset limit to fail")
+	}
+
+	raised, err := RaiseBudget("job_raise", RaiseBudgetOptions{Now: nowFn, Limits: maxSteps, Reason: "three steps are expected", ApprovedBy: "lead"})
+	if err != nil {
+		t.Fatalf("RaiseBudget: %v", err)
+	}
+	if raised.Status != queue.StatusBlockedBudget || raised.Budgets.MaxStepCount != 5 || raised.Budgets.MaxToolCalls != 10 || len(raised.Amendment.Changes) != 1 {
+		t.Fatalf("unexpected raise result: %+v", raised)
+	}
+	if change := raised.Amendment.Changes[0]; change.Dimension != budget.DimensionSteps || change.From != 1 || change.To != 5 {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+
+	resumed, err := Resume("job_raise", ResumeOptions{Now: nowFn})
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if resumed.Status != queue.StatusCompleted {
+		t.Fatalf("expected completed after raise, got %+v", resumed)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: nowFn})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	state, err := r.Recover("job_raise")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(state.BudgetAmendments) != 1 || state.BudgetAmendments[0].ApprovedBy != "lead" {
+		t.Fatalf("expected replayed amendment history, got %+v", state.BudgetAmendments)
+	}
+	if _, err := RaiseBudget("job_raise", RaiseBudgetOptions{Now: nowFn, Limits: budget.Limits{MaxStepCount: 9}, Reason: "r", ApprovedBy: "lead"}); err == nil {
+		t.Fatal("expected raise on completed job to fail")
+	}
+}
+
+func TestSubmitHonorsBudgetPool(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+	now := time.Date(2026, 2, 14, 3, 0, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	spec := func(pool string) string {
+		return `schema_id: wrkr.jobspec
+schema_version: v1
+created_at: "2026-02-14T03:00:00Z"
+producer_version: test
+name: pooled
+objective: test budget pool
+inputs:
+  steps:
+    - id: one
+      summary: first
+expected_artifacts: []
+adapter: { name: reference }
+budgets:
+  max_wall_time_seconds: 100
+  max_retries: 1
+  max_step_count: 5
+  max_tool_calls: 10
+  pool: ` + pool + `
+checkpoint_policy:
+  min_interval_seconds: 1
+  required_types: [plan, progress, blocked]
+environment_fingerprint:
+  rules: [go_version]
+`
+	}
+	if err := os.WriteFile("unknown.yaml", []byte(spec("team-b")), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+	if err := os.WriteFile("pooled.yaml", []byte(spec("team-a")), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Root(), budgetpool.ConfigFile), []byte("pools:\n  - name: team-a\n    window: day\n    max_estimated_cost: 1\n"), 0o600); err != nil {
+		t.Fatalf("write pools: %v", err)
+	}
+
+	if _, err := Submit("unknown.yaml", SubmitOptions{Now: nowFn, JobID: "job_unknown_pool"}); err == nil {
+		t.Fatal("expected unknown pool to fail submit")
+	}
+	if exists, _ := s.JobExists("job_unknown_pool"); exists {
+		t.Fatal("expected no job for unknown pool")
+	}
+
+	result, err := Submit("pooled.yaml", SubmitOptions{Now: nowFn, JobID: "job_pool_first"})
+	if err != nil || result.Status != queue.StatusCompleted {
+		t.Fatalf("expected first pooled job to complete, got %+v err=%v", result, err)
+	}
+	r, err := runner.New(s, runner.Options{Now: nowFn})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.RecordUsage("job_pool_first", runner.Usage{EstimatedCost: 1.25}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+
+	if _, err := Submit("pooled.yaml", SubmitOptions{Now: nowFn, JobID: "job_pool_second"}); err == nil {
+		t.Fatal("expected exhausted pool to block submit")
+	}
+	state, err := r.Recover("job_pool_second")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusBlockedBudget || len(state.LastReasonCodes) != 1 || state.LastReasonCodes[0] != budget.ReasonPoolExhausted {
+		t.Fatalf("expected pool-exhausted block, got %+v", state)
+	}
+}
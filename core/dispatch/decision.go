@@ -0,0 +1,111 @@
+package dispatch
+
+import (
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/adapters/reference"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+type RejectOptions struct {
+	Now          func() time.Time
+	CheckpointID string
+	Reason       string
+	RejectedBy   string
+}
+
+type RejectResult struct {
+	JobID     string           `json:"job_id"`
+	Status    queue.Status     `json:"status"`
+	Rejection runner.Rejection `json:"rejection"`
+}
+
+// Reject rejects a decision-needed checkpoint. When the reference decision
+// step that raised it declares on_reject, the cursor moves to that step and a
+// later resume continues from there; otherwise the job is canceled. Steps
+// from the route onward run again even if they committed before.
+func Reject(jobID string, opts RejectOptions) (RejectResult, error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	jobID = strings.TrimSpace(jobID)
+	checkpointID := strings.TrimSpace(opts.CheckpointID)
+
+	s, err := store.New("")
+	if err != nil {
+		return RejectResult{}, err
+	}
+	exists, err := s.JobExists(jobID)
+	if err != nil {
+		return RejectResult{}, err
+	}
+	if !exists {
+		return RejectResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "job not found", map[string]any{"job_id": jobID})
+	}
+	r, err := runner.New(s, runner.Options{Now: now})
+	if err != nil {
+		return RejectResult{}, err
+	}
+	runtimeCfg, err := LoadRuntimeConfig(s, jobID)
+	if err != nil {
+		return RejectResult{}, err
+	}
+
+	routeStep, routeIndex, err := rejectRoute(runtimeCfg, checkpointID)
+	if err != nil {
+		return RejectResult{}, err
+	}
+	rejection, err := r.RejectCheckpoint(jobID, checkpointID, opts.Reason, opts.RejectedBy, routeStep)
+	if err != nil {
+		return RejectResult{}, err
+	}
+	if routeStep != "" {
+		steps, err := reference.StepsFromInputs(runtimeCfg.Inputs)
+		if err != nil {
+			return RejectResult{}, err
+		}
+		if err := reference.Rewind(r, jobID, steps, routeIndex); err != nil {
+			return RejectResult{}, err
+		}
+		runtimeCfg.NextStepIndex = routeIndex
+		runtimeCfg.GroupCompleted = nil
+		runtimeCfg.PendingCheckpointID = ""
+		if err := SaveRuntimeConfig(s, jobID, *runtimeCfg, now()); err != nil {
+			return RejectResult{}, err
+		}
+	}
+
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return RejectResult{}, err
+	}
+	return RejectResult{JobID: jobID, Status: state.Status, Rejection: *rejection}, nil
+}
+
+// rejectRoute finds the on_reject step of the reference decision step that
+// emitted checkpointID.
+func rejectRoute(runtimeCfg *RuntimeConfig, checkpointID string) (string, int, error) {
+	if runtimeCfg == nil || adapterNameOrDefault(runtimeCfg.Adapter) != "reference" {
+		return "", 0, nil
+	}
+	steps, err := reference.StepsFromInputs(runtimeCfg.Inputs)
+	if err != nil {
+		return "", 0, err
+	}
+	for stepID, cpID := range runtimeCfg.DecisionCheckpoints {
+		if cpID != checkpointID {
+			continue
+		}
+		idx := reference.StepIndex(steps, stepID)
+		if idx < 0 || steps[idx].OnReject == "" {
+			return "", 0, nil
+		}
+		return steps[idx].OnReject, reference.StepIndex(steps, steps[idx].OnReject), nil
+	}
+	return "", 0, nil
+}
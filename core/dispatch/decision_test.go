@@ -0,0 +1,210 @@
+package dispatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+const decisionJobSpec = `schema_id: wrkr.jobspec
+schema_version: v1
+created_at: "2026-02-14T04:00:00Z"
+producer_version: test
+name: decision-options
+objective: test decision options
+inputs:
+  steps:
+    - id: review
+      summary: pick a strategy
+      decision_needed: true
+      options:
+        - fast
+        - name: thorough
+          description: run every check
+      on_reject: fallback
+    - id: fast
+      summary: fast path
+      when: { decision: review, option: fast }
+    - id: thorough
+      summary: thorough path
+      when: { decision: review, option: thorough }
+    - id: fallback
+      summary: fallback path
+expected_artifacts: []
+adapter: { name: reference }
+budgets:
+  max_wall_time_seconds: 100
+  max_retries: 1
+  max_step_count: 10
+  max_tool_calls: 10
+checkpoint_policy:
+  min_interval_seconds: 3600
+  required_types: [plan, completed]
+environment_fingerprint:
+  rules: [go_version]
+`
+
+func TestDecisionOptionsBranchAndRejectRoutes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	now := time.Date(2026, 2, 14, 4, 0, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	if err := os.WriteFile("jobspec.yaml", []byte(decisionJobSpec), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: nowFn})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	decisionCheckpoint := func(jobID string) string {
+		t.Helper()
+		checkpoints, err := r.ListCheckpoints(jobID)
+		if err != nil {
+			t.Fatalf("ListCheckpoints: %v", err)
+		}
+		for _, cp := range checkpoints {
+			if cp.Type == "decision-needed" {
+				if cp.RequiredAction == nil || len(cp.RequiredAction.Options) != 2 || cp.RequiredAction.Options[1].Description != "run every check" {
+					t.Fatalf("expected options on decision checkpoint, got %+v", cp.RequiredAction)
+				}
+				return cp.CheckpointID
+			}
+		}
+		t.Fatalf("decision checkpoint missing for %s", jobID)
+		return ""
+	}
+
+	for _, jobID := range []string{"job_decide", "job_reject"} {
+		submitted, err := Submit("jobspec.yaml", SubmitOptions{Now: nowFn, JobID: jobID})
+		if err != nil {
+			t.Fatalf("Submit %s: %v", jobID, err)
+		}
+		if submitted.Status != queue.StatusBlockedDecision {
+			t.Fatalf("expected blocked_decision, got %+v", submitted)
+		}
+	}
+
+	cpID := decisionCheckpoint("job_decide")
+	if _, err := r.ApproveCheckpoint("job_decide", cpID, "ok", "lead"); err == nil {
+		t.Fatal("expected plain approval of an options checkpoint to fail")
+	}
+	if _, err := r.DecideCheckpoint("job_decide", cpID, "slow", "ok", "lead"); err == nil {
+		t.Fatal("expected unknown option to fail")
+	}
+	if _, err := r.DecideCheckpoint("job_decide", cpID, "thorough", "needs every check", "lead"); err != nil {
+		t.Fatalf("DecideCheckpoint: %v", err)
+	}
+	if _, err := Resume("job_decide", ResumeOptions{Now: nowFn}); err != nil {
+		t.Fatalf("Resume decided job: %v", err)
+	}
+	cfg, err := LoadRuntimeConfig(s, "job_decide")
+	if err != nil {
+		t.Fatalf("LoadRuntimeConfig: %v", err)
+	}
+	if cfg.StepOutcomes["fast"] != "skipped" || cfg.StepOutcomes["thorough"] != "succeeded" || cfg.StepOutcomes["fallback"] != "succeeded" {
+		t.Fatalf("expected thorough branch, got %+v", cfg.StepOutcomes)
+	}
+
+	rejected, err := Reject("job_reject", RejectOptions{Now: nowFn, CheckpointID: decisionCheckpoint("job_reject"), Reason: "neither", RejectedBy: "lead"})
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if rejected.Status != queue.StatusBlockedDecision || rejected.Rejection.RouteStep != "fallback" {
+		t.Fatalf("expected routed rejection, got %+v", rejected)
+	}
+	resumed, err := Resume("job_reject", ResumeOptions{Now: nowFn})
+	if err != nil {
+		t.Fatalf("Resume rejected job: %v", err)
+	}
+	if resumed.Status != queue.StatusCompleted {
+		t.Fatalf("expected completed after routed rejection, got %+v", resumed)
+	}
+	cfg, err = LoadRuntimeConfig(s, "job_reject")
+	if err != nil {
+		t.Fatalf("LoadRuntimeConfig: %v", err)
+	}
+	if _, ran := cfg.StepOutcomes["thorough"]; ran || cfg.StepOutcomes["fallback"] != "succeeded" {
+		t.Fatalf("expected fallback only, got %+v", cfg.StepOutcomes)
+	}
+}
+
+const loopJobSpec = `schema_id: wrkr.jobspec
+schema_version: v1
+created_at: "2026-02-14T04:00:00Z"
+producer_version: test
+name: reject-loop
+objective: test backward reject routes
+inputs:
+  steps:
+    - id: build
+      summary: build the change
+      command: echo run >> %s
+    - id: review
+      summary: review the build
+      decision_needed: true
+      on_reject: build
+expected_artifacts: []
+adapter: { name: reference }
+budgets:
+  max_wall_time_seconds: 100
+  max_retries: 1
+  max_step_count: 10
+  max_tool_calls: 10
+checkpoint_policy:
+  min_interval_seconds: 3600
+  required_types: [plan, completed]
+environment_fingerprint:
+  rules: [go_version]
+`
+
+func TestRejectRouteBackRerunsCommittedSteps(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	now := time.Date(2026, 2, 14, 4, 0, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	runs := filepath.Join(t.TempDir(), "runs.txt")
+	if err := os.WriteFile("jobspec.yaml", []byte(fmt.Sprintf(loopJobSpec, runs)), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+	if _, err := Submit("jobspec.yaml", SubmitOptions{Now: nowFn, JobID: "job_loop"}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	cfg, err := LoadRuntimeConfig(s, "job_loop")
+	if err != nil {
+		t.Fatalf("LoadRuntimeConfig: %v", err)
+	}
+	if _, err := Reject("job_loop", RejectOptions{Now: nowFn, CheckpointID: cfg.DecisionCheckpoints["review"], Reason: "redo", RejectedBy: "lead"}); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	resumed, err := Resume("job_loop", ResumeOptions{Now: nowFn})
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if resumed.Status != queue.StatusBlockedDecision {
+		t.Fatalf("expected the review step to block again, got %+v", resumed)
+	}
+	raw, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatalf("read runs: %v", err)
+	}
+	if got := strings.Count(string(raw), "run"); got != 2 {
+		t.Fatalf("expected build to run twice, ran %d times", got)
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/davidahmann/wrkr/core/adapters/llm"
+	"github.com/davidahmann/wrkr/core/adapters/mcp"
 	"github.com/davidahmann/wrkr/core/adapters/reference"
 	"github.com/davidahmann/wrkr/core/budget"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
 	"github.com/davidahmann/wrkr/core/queue"
 	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/sandbox"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
@@ -62,6 +66,8 @@ func executeWithLease(
 					recordHeartbeatErr(err)
 					return
 				}
+				// A silence warning is advisory and must not stop the job.
+				_, _ = r.CheckCheckpointInterval(jobID)
 			}
 		}
 	}()
@@ -95,18 +101,46 @@ func runAdapter(
 	s *store.LocalStore,
 	now func() time.Time,
 ) (adapterRunResult, error) {
+	var sb *sandbox.Sandbox
+	if adapterName != "noop" {
+		prepared, err := sandbox.Apply(r, jobID, runtimeCfg.Sandbox, runtimeCfg.Workspace)
+		if err != nil {
+			return adapterRunResult{Status: queue.StatusBlockedError, NextStepIndex: runtimeCfg.NextStepIndex}, err
+		}
+		sb = prepared
+	}
+
 	switch adapterName {
 	case "reference":
 		steps, err := reference.StepsFromInputs(runtimeCfg.Inputs)
 		if err != nil {
 			return adapterRunResult{}, err
 		}
+		capture, err := reference.CaptureFromConfig(runtimeCfg.AdapterConfig, runtimeCfg.Workspace)
+		if err != nil {
+			return adapterRunResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, err.Error(), nil)
+		}
+		var cursorMu sync.Mutex
 		result, err := reference.Run(jobID, steps, reference.RunOptions{
-			Now:          now,
-			StartIndex:   runtimeCfg.NextStepIndex,
-			BudgetLimits: runtimeCfg.Budgets,
-			OnAdvance: func(nextStepIndex int) error {
-				runtimeCfg.NextStepIndex = nextStepIndex
+			Now:                 now,
+			StartIndex:          runtimeCfg.NextStepIndex,
+			Inputs:              runtimeCfg.Inputs,
+			Outcomes:            runtimeCfg.StepOutcomes,
+			GroupCompleted:      runtimeCfg.GroupCompleted,
+			PendingCheckpointID: runtimeCfg.PendingCheckpointID,
+			Decisions:           runtimeCfg.DecisionCheckpoints,
+			BudgetLimits:        runtimeCfg.Budgets,
+			Capture:             capture,
+			Sandbox:             sb,
+			Policy:              runtimeCfg.Policy,
+			OnCursor: func(cursor reference.Cursor) error {
+				cursorMu.Lock()
+				defer cursorMu.Unlock()
+				runtimeCfg.NextStepIndex = cursor.NextStepIndex
+				runtimeCfg.GroupCompleted = cursor.GroupCompleted
+				runtimeCfg.StepOutcomes = cursor.Outcomes
+				runtimeCfg.PendingCheckpointID = cursor.PendingCheckpointID
+				runtimeCfg.DecisionCheckpoints = cursor.Decisions
 				return SaveRuntimeConfig(s, jobID, *runtimeCfg, now())
 			},
 		})
@@ -119,6 +153,52 @@ func runAdapter(
 		}
 		return runResult, nil
 
+	case "llm":
+		cfg, err := llm.ConfigFromAdapter(runtimeCfg.AdapterConfig, runtimeCfg.Inputs)
+		if err != nil {
+			return adapterRunResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, err.Error(), nil)
+		}
+		var state llm.State
+		if runtimeCfg.LLM != nil {
+			state = *runtimeCfg.LLM
+		}
+		result, err := llm.Run(jobID, cfg, llm.RunOptions{
+			Now:          now,
+			State:        state,
+			Workspace:    runtimeCfg.Workspace,
+			BudgetLimits: runtimeCfg.Budgets,
+			Sandbox:      sb,
+			PriceTable:   runtimeCfg.PriceTable,
+			OnState: func(state llm.State) error {
+				runtimeCfg.LLM = &state
+				return SaveRuntimeConfig(s, jobID, *runtimeCfg, now())
+			},
+		})
+		return adapterRunResult{Status: result.Status, NextStepIndex: runtimeCfg.NextStepIndex}, err
+
+	case "mcp":
+		cfg, err := mcp.ConfigFromAdapter(runtimeCfg.AdapterConfig, runtimeCfg.Inputs)
+		if err != nil {
+			return adapterRunResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, err.Error(), nil)
+		}
+		result, err := mcp.Run(jobID, cfg, mcp.RunOptions{
+			Now: now,
+			Cursor: mcp.Cursor{
+				NextCallIndex:       runtimeCfg.NextStepIndex,
+				PendingCheckpointID: runtimeCfg.PendingCheckpointID,
+			},
+			Workspace:       runtimeCfg.Workspace,
+			BudgetLimits:    runtimeCfg.Budgets,
+			ProducerVersion: runtimeCfg.ProducerVersion,
+			Sandbox:         sb,
+			OnCursor: func(cursor mcp.Cursor) error {
+				runtimeCfg.NextStepIndex = cursor.NextCallIndex
+				runtimeCfg.PendingCheckpointID = cursor.PendingCheckpointID
+				return SaveRuntimeConfig(s, jobID, *runtimeCfg, now())
+			},
+		})
+		return adapterRunResult{Status: result.Status, NextStepIndex: result.NextCallIndex}, err
+
 	case "noop":
 		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
 			Type:    "completed",
@@ -146,6 +226,7 @@ func budgetFromSpec(spec v1.BudgetSpec) budget.Limits {
 		MaxRetries:         spec.MaxRetries,
 		MaxStepCount:       spec.MaxStepCount,
 		MaxToolCalls:       spec.MaxToolCalls,
+		Pool:               strings.TrimSpace(spec.Pool),
 	}
 	if spec.MaxEstimatedCost != nil {
 		value := *spec.MaxEstimatedCost
@@ -155,9 +236,38 @@ func budgetFromSpec(spec v1.BudgetSpec) budget.Limits {
 		value := *spec.MaxTokens
 		limits.MaxTokens = &value
 	}
+	if spec.Warnings != nil {
+		limits.WarnRequiresDecision = spec.Warnings.RequireDecision
+		limits.WarnAtPercent = map[string]int{}
+		if spec.Warnings.Percent > 0 {
+			for _, dim := range []string{
+				budget.DimensionWallTime,
+				budget.DimensionRetries,
+				budget.DimensionSteps,
+				budget.DimensionToolCalls,
+				budget.DimensionCost,
+				budget.DimensionTokens,
+			} {
+				limits.WarnAtPercent[dim] = spec.Warnings.Percent
+			}
+		}
+		for dim, percent := range spec.Warnings.Dimensions {
+			limits.WarnAtPercent[dim] = percent
+		}
+	}
 	return limits
 }
 
+// workspaceFromInputs resolves inputs.workspace against the submitting
+// process's working directory so resume can run from anywhere.
+func workspaceFromInputs(inputs map[string]any) (string, error) {
+	workspace, _ := inputs["workspace"].(string)
+	if strings.TrimSpace(workspace) == "" {
+		workspace = "."
+	}
+	return fsx.NormalizeAbsolutePath(workspace)
+}
+
 func adapterNameOrDefault(name string) string {
 	normalized := strings.ToLower(strings.TrimSpace(name))
 	if normalized == "" {
@@ -8,21 +8,35 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/davidahmann/wrkr/core/adapters/llm"
 	"github.com/davidahmann/wrkr/core/budget"
 	"github.com/davidahmann/wrkr/core/fsx"
+	"github.com/davidahmann/wrkr/core/policy"
+	"github.com/davidahmann/wrkr/core/pricing"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
 type RuntimeConfig struct {
-	SchemaID        string         `json:"schema_id"`
-	SchemaVersion   string         `json:"schema_version"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	ProducerVersion string         `json:"producer_version"`
-	Adapter         string         `json:"adapter"`
-	Inputs          map[string]any `json:"inputs"`
-	Budgets         budget.Limits  `json:"budgets"`
-	NextStepIndex   int            `json:"next_step_index"`
+	SchemaID            string            `json:"schema_id"`
+	SchemaVersion       string            `json:"schema_version"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	ProducerVersion     string            `json:"producer_version"`
+	Adapter             string            `json:"adapter"`
+	Inputs              map[string]any    `json:"inputs"`
+	AdapterConfig       map[string]any    `json:"adapter_config,omitempty"`
+	Workspace           string            `json:"workspace,omitempty"`
+	Budgets             budget.Limits     `json:"budgets"`
+	NextStepIndex       int               `json:"next_step_index"`
+	GroupCompleted      []string          `json:"group_completed,omitempty"`
+	StepOutcomes        map[string]string `json:"step_outcomes,omitempty"`
+	LLM                 *llm.State        `json:"llm,omitempty"`
+	PendingCheckpointID string            `json:"pending_checkpoint_id,omitempty"`
+	DecisionCheckpoints map[string]string `json:"decision_checkpoints,omitempty"`
+	Sandbox             *v1.SandboxSpec   `json:"sandbox,omitempty"`
+	Policy              *policy.Policy    `json:"policy,omitempty"`
+	PriceTable          *pricing.Table    `json:"price_table,omitempty"`
 }
 
 func runtimeConfigPath(s *store.LocalStore, jobID string) string {
@@ -90,4 +104,3 @@ func LoadRuntimeConfig(s *store.LocalStore, jobID string) (*RuntimeConfig, error
 	}
 	return &cfg, nil
 }
-
@@ -5,17 +5,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/davidahmann/wrkr/core/accept"
+	"github.com/davidahmann/wrkr/core/budgetpool"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
+	"github.com/davidahmann/wrkr/core/pricing"
 	"github.com/davidahmann/wrkr/core/projectconfig"
 	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/redact"
 	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
 type SubmitOptions struct {
-	Now       func() time.Time
-	JobID     string
-	FromServe bool
+	Now         func() time.Time
+	JobID       string
+	SubmittedBy string
+	FromServe   bool
 }
 
 type SubmitResult struct {
@@ -60,13 +67,100 @@ func Submit(specPath string, opts SubmitOptions) (SubmitResult, error) {
 		)
 	}
 
+	workspace, err := workspaceFromInputs(spec.Inputs)
+	if err != nil {
+		return SubmitResult{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"jobspec.inputs.workspace is invalid",
+			map[string]any{"error": err.Error()},
+		)
+	}
+
+	acceptanceSpec := v1.AcceptanceSpec{ExpectedArtifacts: spec.ExpectedArtifacts, Config: spec.Acceptance}
+	if err := accept.ValidateJobAcceptance(acceptanceSpec); err != nil {
+		return SubmitResult{}, err
+	}
+
+	var jobPolicy *policy.Policy
+	var approvalRules []policy.ApprovalRule
+	if strings.TrimSpace(spec.Policy) != "" {
+		jobPolicy, err = policy.Load(spec.Policy)
+		if err != nil {
+			return SubmitResult{}, err
+		}
+		approvalRules, err = jobPolicy.ApprovalRules()
+		if err != nil {
+			return SubmitResult{}, err
+		}
+	}
+
+	var priceTable *pricing.Table
+	if strings.TrimSpace(spec.PriceTable) != "" {
+		priceTable, err = pricing.Load(spec.PriceTable)
+		if err != nil {
+			return SubmitResult{}, err
+		}
+	}
+
+	limits := budgetFromSpec(spec.Budgets)
+	if limits.Pool != "" {
+		pools, err := budgetpool.Load(s.Root())
+		if err != nil {
+			return SubmitResult{}, err
+		}
+		if _, err := pools.Find(limits.Pool); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+
 	r, err := runner.New(s, runner.Options{Now: now})
 	if err != nil {
 		return SubmitResult{}, err
 	}
-	if _, err := r.InitJobWithEnvRules(jobID, spec.EnvironmentFingerprint.Rules); err != nil {
+	if spec.Redaction != nil {
+		// Stored before the first event so every payload is masked with it.
+		if err := s.SetRedaction(jobID, redact.Config{Patterns: spec.Redaction.Patterns, EnvVars: spec.Redaction.EnvVars}); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if _, err := r.InitJobWithEnvFingerprint(jobID, spec.EnvironmentFingerprint.Rules, spec.EnvironmentFingerprint.Advisory); err != nil {
 		return SubmitResult{}, err
 	}
+	if submittedBy := strings.TrimSpace(opts.SubmittedBy); submittedBy != "" {
+		if _, err := r.RecordSubmitter(jobID, submittedBy); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if jobPolicy != nil {
+		if _, err := r.RecordPolicy(jobID, *jobPolicy.Ref()); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if len(approvalRules) > 0 {
+		if _, err := r.RecordApprovalRules(jobID, approvalRules); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if priceTable != nil {
+		if _, err := r.RecordPriceTable(jobID, *priceTable.Ref()); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if limits.Pool != "" {
+		if _, err := r.RecordBudgetPool(jobID, limits.Pool); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if spec.CheckpointPolicy.MinIntervalSeconds > 0 || len(spec.CheckpointPolicy.RequiredTypes) > 0 {
+		if _, err := r.RecordCheckpointPolicy(jobID, spec.CheckpointPolicy); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	if len(acceptanceSpec.ExpectedArtifacts) > 0 || len(acceptanceSpec.Config) > 0 {
+		if _, err := r.RecordAcceptance(jobID, acceptanceSpec); err != nil {
+			return SubmitResult{}, err
+		}
+	}
 	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
 		return SubmitResult{}, err
 	}
@@ -84,13 +178,25 @@ func Submit(specPath string, opts SubmitOptions) (SubmitResult, error) {
 		ProducerVersion: spec.ProducerVersion,
 		Adapter:         adapterName,
 		Inputs:          spec.Inputs,
-		Budgets:         budgetFromSpec(spec.Budgets),
+		AdapterConfig:   spec.Adapter.Config,
+		Workspace:       workspace,
+		Budgets:         limits,
+		Sandbox:         spec.Sandbox,
+		Policy:          jobPolicy,
+		PriceTable:      priceTable,
 		NextStepIndex:   0,
 	}
 	if err := SaveRuntimeConfig(s, jobID, runtimeCfg, now()); err != nil {
 		return SubmitResult{}, err
 	}
 
+	if limits.Pool != "" {
+		// Do not start work against a pool that is already exhausted.
+		if _, err := r.CheckBudget(jobID, limits); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+
 	adapterResult, runErr := executeWithLease(r, jobID, now, func() (adapterRunResult, error) {
 		return runAdapter(adapterName, jobID, &runtimeCfg, r, s, now)
 	})
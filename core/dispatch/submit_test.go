@@ -87,6 +87,9 @@ environment_fingerprint:
 	if len(state.EnvFingerprintRules) != 1 || state.EnvFingerprintRules[0] != "go_version" {
 		t.Fatalf("expected env rules from jobspec, got %+v", state.EnvFingerprintRules)
 	}
+	if state.Acceptance == nil || len(state.Acceptance.ExpectedArtifacts) != 1 || state.Acceptance.ExpectedArtifacts[0] != "reports/out.md" {
+		t.Fatalf("expected acceptance spec recorded from jobspec, got %+v", state.Acceptance)
+	}
 }
 
 func TestSubmitResumeContinuesRemainingSteps(t *testing.T) {
@@ -0,0 +1,102 @@
+package dispatch
+
+import (
+	"strings"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+type UsageOptions struct {
+	Now func() time.Time
+}
+
+type UsageResult struct {
+	JobID            string         `json:"job_id"`
+	Status           queue.Status   `json:"status"`
+	TokensIn         int            `json:"tokens_in"`
+	TokensOut        int            `json:"tokens_out"`
+	EstimatedCost    float64        `json:"estimated_cost"`
+	BudgetCheckpoint *v1.Checkpoint `json:"budget_checkpoint,omitempty"`
+}
+
+// RecordUsage records usage reported by an external agent and, for a running
+// job with submitted budgets, checks the token and cost limits (and warning
+// thresholds) right away so they apply without waiting for the next adapter
+// step. A job with a price table prices every report from it and rejects
+// reported costs.
+func RecordUsage(jobID string, usage runner.Usage, opts UsageOptions) (UsageResult, error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	jobID = strings.TrimSpace(jobID)
+
+	s, err := store.New("")
+	if err != nil {
+		return UsageResult{}, err
+	}
+	exists, err := s.JobExists(jobID)
+	if err != nil {
+		return UsageResult{}, err
+	}
+	if !exists {
+		return UsageResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "job not found", map[string]any{"job_id": jobID})
+	}
+	r, err := runner.New(s, runner.Options{Now: now})
+	if err != nil {
+		return UsageResult{}, err
+	}
+	runtimeCfg, err := LoadRuntimeConfig(s, jobID)
+	if err != nil {
+		return UsageResult{}, err
+	}
+	if runtimeCfg != nil && runtimeCfg.PriceTable != nil {
+		// The jobpack credits the table for the job's cost, so every report
+		// must be priced from it.
+		if usage.EstimatedCost != 0 {
+			return UsageResult{}, wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"estimated_cost cannot be reported for a job priced by a price table",
+				map[string]any{"job_id": jobID, "price_table_version": runtimeCfg.PriceTable.Version},
+			)
+		}
+		if strings.TrimSpace(usage.Model) == "" {
+			return UsageResult{}, wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"model is required to price usage with the job's price table",
+				map[string]any{"job_id": jobID},
+			)
+		}
+		if usage.EstimatedCost, err = runtimeCfg.PriceTable.Cost(usage.Model, usage.TokensIn, usage.TokensOut); err != nil {
+			return UsageResult{}, err
+		}
+	}
+	state, err := r.RecordUsage(jobID, usage)
+	if err != nil {
+		return UsageResult{}, err
+	}
+
+	var (
+		cp       *v1.Checkpoint
+		checkErr error
+	)
+	if state.Status == queue.StatusRunning && runtimeCfg != nil {
+		cp, checkErr = r.CheckBudget(jobID, runtimeCfg.Budgets)
+		if checkErr != nil {
+			state.Status = runner.BudgetStopStatus(checkErr)
+		}
+	}
+	return UsageResult{
+		JobID:            jobID,
+		Status:           state.Status,
+		TokensIn:         state.TokensIn,
+		TokensOut:        state.TokensOut,
+		EstimatedCost:    state.EstimatedCost,
+		BudgetCheckpoint: cp,
+	}, checkErr
+}
@@ -0,0 +1,56 @@
+package dispatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/pricing"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+func TestRecordUsagePricesFromPriceTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 14, 3, 30, 0, 0, time.UTC)
+	nowFn := func() time.Time { return now }
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: nowFn})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.InitJob("job_priced"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_priced", queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	table, err := pricing.Parse([]byte("version: v7\nmodels:\n  gpt-test: {input_per_1k_tokens: 0.5, output_per_1k_tokens: 1.5}\n"), "prices.yaml")
+	if err != nil {
+		t.Fatalf("pricing.Parse: %v", err)
+	}
+	if err := SaveRuntimeConfig(s, "job_priced", RuntimeConfig{Adapter: "reference", PriceTable: table}, now); err != nil {
+		t.Fatalf("SaveRuntimeConfig: %v", err)
+	}
+
+	result, err := RecordUsage("job_priced", runner.Usage{Model: "gpt-test", TokensIn: 2000, TokensOut: 1000}, UsageOptions{Now: nowFn})
+	if err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+	if result.EstimatedCost != 2.5 {
+		t.Fatalf("expected table-priced cost 2.5, got %v", result.EstimatedCost)
+	}
+	if _, err := RecordUsage("job_priced", runner.Usage{Model: "gpt-test", TokensIn: 10, EstimatedCost: 0.25}, UsageOptions{Now: nowFn}); err == nil {
+		t.Fatal("expected reported cost to be rejected for a priced job")
+	}
+	if _, err := RecordUsage("job_priced", runner.Usage{Model: "unpriced", TokensIn: 10}, UsageOptions{Now: nowFn}); err == nil {
+		t.Fatal("expected unknown model to fail")
+	}
+	if _, err := RecordUsage("job_priced", runner.Usage{TokensIn: 10}, UsageOptions{Now: nowFn}); err == nil {
+		t.Fatal("expected missing model to fail")
+	}
+}
@@ -0,0 +1,65 @@
+package envfp
+
+import (
+	"slices"
+	"sort"
+)
+
+// Change is one rule whose value differs between two fingerprints.
+type Change struct {
+	Rule     string `json:"rule"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Advisory bool   `json:"advisory,omitempty"`
+}
+
+// Diff lists the rules whose values differ from recorded to current, sorted by
+// rule. Per-rule digests are compared when both sides carry one, so a value
+// masked in the event log still diffs correctly. A rule is advisory when
+// either fingerprint marks it so.
+func Diff(recorded, current Fingerprint) []Change {
+	rules := map[string]struct{}{}
+	for _, rule := range recorded.Rules {
+		rules[rule] = struct{}{}
+	}
+	for _, rule := range current.Rules {
+		rules[rule] = struct{}{}
+	}
+
+	changes := []Change{}
+	for rule := range rules {
+		expected, actual := recorded.Values[rule], current.Values[rule]
+		oldDigest, oldOK := recorded.Digests[rule]
+		newDigest, newOK := current.Digests[rule]
+		if oldOK && newOK {
+			if oldDigest == newDigest {
+				continue
+			}
+		} else if expected == actual && slices.Contains(recorded.Rules, rule) == slices.Contains(current.Rules, rule) {
+			continue
+		}
+		changes = append(changes, Change{
+			Rule:     rule,
+			Expected: expected,
+			Actual:   actual,
+			Advisory: slices.Contains(recorded.Advisory, rule) || slices.Contains(current.Advisory, rule),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Rule < changes[j].Rule })
+	return changes
+}
+
+// Blocking reports whether a fingerprint mismatch should block resume: a
+// non-advisory rule changed, or the hashes differ without any rule to show
+// for it.
+func Blocking(recorded, current Fingerprint, changes []Change) bool {
+	if recorded.Hash == "" || recorded.Hash == current.Hash {
+		return false
+	}
+	for _, change := range changes {
+		if !change.Advisory {
+			return true
+		}
+	}
+	return len(changes) == 0
+}
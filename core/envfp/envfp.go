@@ -17,6 +17,8 @@ import (
 type Fingerprint struct {
 	Rules      []string          `json:"rules"`
 	Values     map[string]string `json:"values"`
+	Digests    map[string]string `json:"digests,omitempty"`
+	Advisory   []string          `json:"advisory,omitempty"`
 	Hash       string            `json:"hash"`
 	CapturedAt time.Time         `json:"captured_at"`
 }
@@ -26,6 +28,12 @@ func DefaultRules() []string {
 }
 
 func Capture(rules []string, now time.Time) (Fingerprint, error) {
+	return CaptureWithAdvisory(rules, nil, now)
+}
+
+// CaptureWithAdvisory captures rules plus the advisory rules, whose changes
+// are reported by Diff but do not block resume.
+func CaptureWithAdvisory(rules, advisory []string, now time.Time) (Fingerprint, error) {
 	if len(rules) == 0 {
 		rules = DefaultRules()
 	}
@@ -40,13 +48,31 @@ func Capture(rules []string, now time.Time) (Fingerprint, error) {
 		if _, ok := seen[r]; ok {
 			continue
 		}
+		if err := validateRule(r); err != nil {
+			return Fingerprint{}, err
+		}
 		seen[r] = struct{}{}
 		norm = append(norm, r)
 	}
 	if len(norm) == 0 {
 		norm = DefaultRules()
 	}
+	var advisoryRules []string
+	for _, rule := range advisory {
+		r := strings.TrimSpace(rule)
+		if r == "" || slices.Contains(advisoryRules, r) {
+			continue
+		}
+		if err := validateRule(r); err != nil {
+			return Fingerprint{}, err
+		}
+		advisoryRules = append(advisoryRules, r)
+		if !slices.Contains(norm, r) {
+			norm = append(norm, r)
+		}
+	}
 	slices.Sort(norm)
+	slices.Sort(advisoryRules)
 
 	values := make(map[string]string, len(norm))
 	for _, rule := range norm {
@@ -72,6 +98,36 @@ func Capture(rules []string, now time.Time) (Fingerprint, error) {
 		case strings.HasPrefix(rule, "env:"):
 			key := strings.TrimPrefix(rule, "env:")
 			values[rule] = os.Getenv(key)
+		case strings.HasPrefix(rule, "file:"):
+			value, err := fileValue(strings.TrimPrefix(rule, "file:"))
+			if err != nil {
+				return Fingerprint{}, err
+			}
+			values[rule] = value
+		case strings.HasPrefix(rule, "glob:"):
+			value, err := globValue(strings.TrimPrefix(rule, "glob:"))
+			if err != nil {
+				return Fingerprint{}, err
+			}
+			values[rule] = value
+		case strings.HasPrefix(rule, "cmd:"):
+			value, err := commandValue(strings.TrimPrefix(rule, "cmd:"))
+			if err != nil {
+				return Fingerprint{}, err
+			}
+			values[rule] = value
+		case rule == "git:head":
+			value, err := gitHeadValue()
+			if err != nil {
+				return Fingerprint{}, err
+			}
+			values[rule] = value
+		case rule == "git:dirty":
+			value, err := gitDirtyValue()
+			if err != nil {
+				return Fingerprint{}, err
+			}
+			values[rule] = value
 		default:
 			return Fingerprint{}, fmt.Errorf("unsupported environment fingerprint rule %q", rule)
 		}
@@ -90,9 +146,16 @@ func Capture(rules []string, now time.Time) (Fingerprint, error) {
 	}
 	sum := sha256.Sum256(canon)
 
+	digests := make(map[string]string, len(values))
+	for rule, value := range values {
+		digests[rule] = sha256Hex([]byte(value))
+	}
+
 	return Fingerprint{
 		Rules:      norm,
 		Values:     values,
+		Digests:    digests,
+		Advisory:   advisoryRules,
 		Hash:       hex.EncodeToString(sum[:]),
 		CapturedAt: now.UTC(),
 	}, nil
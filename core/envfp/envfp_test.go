@@ -1,6 +1,11 @@
 package envfp
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -40,3 +45,175 @@ func TestCaptureEnvRuleReflectsChanges(t *testing.T) {
 		t.Fatalf("expected hash change after env change, got %s", a.Hash)
 	}
 }
+
+func TestCaptureFileGlobAndCommandRules(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.MkdirAll(filepath.Join("deps", "nested"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, "go.sum", "a v1\n")
+	writeFile(t, filepath.Join("deps", "a.lock"), "one")
+	writeFile(t, filepath.Join("deps", "nested", "b.lock"), "two")
+	writeFile(t, filepath.Join("deps", "nested", "c.txt"), "ignored")
+
+	rules := []string{"file:go.sum", "file:absent.lock", "glob:deps/**/*.lock", "cmd:git", "cmd:wrkr-no-such-tool"}
+	a, err := Capture(rules, time.Now())
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if !strings.HasPrefix(a.Values["file:go.sum"], "sha256:") || a.Values["file:absent.lock"] != "missing" {
+		t.Fatalf("unexpected file values: %+v", a.Values)
+	}
+	if !strings.HasSuffix(a.Values["glob:deps/**/*.lock"], " files=2") {
+		t.Fatalf("unexpected glob value %q", a.Values["glob:deps/**/*.lock"])
+	}
+	if !strings.HasPrefix(a.Values["cmd:git"], "git version") || a.Values["cmd:wrkr-no-such-tool"] != "missing" {
+		t.Fatalf("unexpected cmd values: %+v", a.Values)
+	}
+
+	writeFile(t, filepath.Join("deps", "nested", "b.lock"), "three")
+	writeFile(t, filepath.Join("deps", "nested", "c.txt"), "still ignored")
+	b, err := Capture(rules, time.Now())
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if a.Values["glob:deps/**/*.lock"] == b.Values["glob:deps/**/*.lock"] || a.Values["file:go.sum"] != b.Values["file:go.sum"] {
+		t.Fatalf("expected only the glob value to change: %+v vs %+v", a.Values, b.Values)
+	}
+
+	for _, bad := range []string{"file:", "glob:../outside/*", "cmd:git version"} {
+		if _, err := Capture([]string{bad}, time.Now()); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestCaptureGitRulesReadRepositoryState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	fp, err := Capture([]string{"git:head", "git:dirty"}, time.Now())
+	if err != nil {
+		t.Fatalf("Capture outside repo: %v", err)
+	}
+	if fp.Values["git:head"] != "none" || fp.Values["git:dirty"] != "none" {
+		t.Fatalf("expected none outside a repository, got %+v", fp.Values)
+	}
+
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	gitCmd("init", "-q", "-b", "main")
+	fp, err = Capture([]string{"git:head"}, time.Now())
+	if err != nil || fp.Values["git:head"] != "refs/heads/main (unborn)" {
+		t.Fatalf("expected unborn head, got %+v (%v)", fp.Values, err)
+	}
+
+	if err := os.MkdirAll("sub", 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, "tracked.txt", "v1\n")
+	writeFile(t, filepath.Join("sub", "deep.txt"), "deep\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-q", "-m", "init")
+	head := gitCmd("rev-parse", "HEAD")
+
+	t.Chdir(filepath.Join(dir, "sub"))
+	fp, err = Capture([]string{"git:head", "git:dirty"}, time.Now())
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if fp.Values["git:head"] != head || fp.Values["git:dirty"] != "false" {
+		t.Fatalf("expected clean head %s, got %+v", head, fp.Values)
+	}
+	t.Chdir(dir)
+
+	writeFile(t, "untracked.txt", "new\n")
+	if fp, _ = Capture([]string{"git:dirty"}, time.Now()); fp.Values["git:dirty"] != "false" {
+		t.Fatalf("expected untracked files to be ignored, got %+v", fp.Values)
+	}
+	writeFile(t, "tracked.txt", "v2\n")
+	if fp, _ = Capture([]string{"git:dirty"}, time.Now()); fp.Values["git:dirty"] != "true" {
+		t.Fatalf("expected modified tree to be dirty, got %+v", fp.Values)
+	}
+	writeFile(t, "tracked.txt", "v1\n")
+	if fp, _ = Capture([]string{"git:dirty"}, time.Now()); fp.Values["git:dirty"] != "false" {
+		t.Fatalf("expected restored content to be clean, got %+v", fp.Values)
+	}
+	if err := os.Remove(filepath.Join("sub", "deep.txt")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if fp, _ = Capture([]string{"git:dirty"}, time.Now()); fp.Values["git:dirty"] != "true" {
+		t.Fatalf("expected deleted file to be dirty, got %+v", fp.Values)
+	}
+
+	gitCmd("checkout", "-q", "--", ".")
+	gitCmd("config", "core.autocrlf", "true")
+	writeFile(t, "tracked.txt", "v1\r\n")
+	if fp, _ = Capture([]string{"git:dirty"}, time.Now()); fp.Values["git:dirty"] != "false" {
+		t.Fatalf("expected line-ending conversion to be clean, got %+v", fp.Values)
+	}
+	gitCmd("config", "core.autocrlf", "false")
+	gitCmd("checkout", "-q", "--", ".")
+	gitCmd("add", "untracked.txt")
+	if fp, _ = Capture([]string{"git:dirty"}, time.Now()); fp.Values["git:dirty"] != "true" {
+		t.Fatalf("expected staged file to be dirty, got %+v", fp.Values)
+	}
+	gitCmd("commit", "-q", "-m", "add")
+	head = gitCmd("rev-parse", "HEAD")
+	gitCmd("pack-refs", "--all")
+	if fp, _ = Capture([]string{"git:head"}, time.Now()); fp.Values["git:head"] != head {
+		t.Fatalf("expected packed ref to resolve, got %+v", fp.Values)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestDiffComparesDigestsAndMarksAdvisory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC)
+	current, err := CaptureWithAdvisory([]string{"os"}, []string{"arch"}, now)
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if !slices.Equal(current.Rules, []string{"arch", "os"}) || !slices.Equal(current.Advisory, []string{"arch"}) {
+		t.Fatalf("expected advisory rule captured, got %+v", current)
+	}
+
+	recorded := current
+	recorded.Hash = "old"
+	recorded.Values = map[string]string{"os": "[REDACTED]", "arch": "bogus"}
+	recorded.Digests = map[string]string{"os": current.Digests["os"], "arch": sha256Hex([]byte("bogus"))}
+
+	changes := Diff(recorded, current)
+	if len(changes) != 1 || changes[0].Rule != "arch" || changes[0].Expected != "bogus" || !changes[0].Advisory {
+		t.Fatalf("expected one advisory change, got %+v", changes)
+	}
+	if Blocking(recorded, current, changes) {
+		t.Fatal("advisory-only change must not block")
+	}
+
+	recorded.Digests["os"] = sha256Hex([]byte("plan9"))
+	changes = Diff(recorded, current)
+	if len(changes) != 2 || changes[1].Rule != "os" || changes[1].Advisory || !Blocking(recorded, current, changes) {
+		t.Fatalf("expected blocking os change, got %+v", changes)
+	}
+	if !Blocking(recorded, current, nil) {
+		t.Fatal("a hash mismatch without changes must block")
+	}
+}
@@ -0,0 +1,194 @@
+package envfp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitRepo locates a working tree's git metadata. GitDir holds HEAD;
+// CommonDir holds refs and config and differs only for worktrees.
+type gitRepo struct {
+	WorkTree  string
+	GitDir    string
+	CommonDir string
+}
+
+// findGitRepo walks up from dir to the nearest .git directory or gitfile.
+func findGitRepo(dir string) (*gitRepo, error) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				if gitDir, err = readGitFile(dotGit); err != nil {
+					return nil, err
+				}
+			}
+			repo := &gitRepo{WorkTree: dir, GitDir: gitDir, CommonDir: gitDir}
+			// #nosec G304 -- commondir lives in the repository's own git dir.
+			if raw, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+				common := strings.TrimSpace(string(raw))
+				if !filepath.IsAbs(common) {
+					common = filepath.Join(gitDir, common)
+				}
+				repo.CommonDir = filepath.Clean(common)
+			}
+			return repo, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("stat %s: %w", dotGit, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func readGitFile(path string) (string, error) {
+	// #nosec G304 -- path is a .git file found while walking up from cwd.
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	line := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("unrecognized gitfile %s", path)
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// gitHeadValue reports the commit HEAD points at. An unborn branch is
+// reported as its ref.
+func gitHeadValue() (string, error) {
+	repo, err := cwdGitRepo()
+	if err != nil || repo == nil {
+		return valueNone, err
+	}
+	// #nosec G304 -- HEAD lives in the repository's own git dir.
+	raw, err := os.ReadFile(filepath.Join(repo.GitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("read git HEAD: %w", err)
+	}
+	head := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(head, "ref:") {
+		return head, nil
+	}
+	ref := strings.TrimSpace(strings.TrimPrefix(head, "ref:"))
+	commit, err := repo.resolveRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if commit == "" {
+		return ref + " (unborn)", nil
+	}
+	return commit, nil
+}
+
+func (g *gitRepo) resolveRef(ref string) (string, error) {
+	if strings.Contains(ref, "..") {
+		return "", fmt.Errorf("invalid git ref %q", ref)
+	}
+	for _, dir := range []string{g.GitDir, g.CommonDir} {
+		// #nosec G304 -- loose refs live in the repository's own git dir.
+		raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(raw)), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("read git ref %s: %w", ref, err)
+		}
+	}
+	// #nosec G304 -- packed-refs lives in the repository's own git dir.
+	f, err := os.Open(filepath.Join(g.CommonDir, "packed-refs"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("open packed-refs: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read packed-refs: %w", err)
+	}
+	return "", nil
+}
+
+// gitDirtyValue reports "true" when a tracked file differs from HEAD: staged,
+// modified, deleted, mode-changed or unmerged. Git compares contents through
+// the repository's autocrlf, eol and clean filters (such as LFS). Untracked
+// files are ignored.
+func gitDirtyValue() (string, error) {
+	repo, err := cwdGitRepo()
+	if err != nil || repo == nil {
+		return valueNone, err
+	}
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return valueMissing, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	// The index is compared with HEAD, then the work tree with the index, so
+	// an unborn branch needs no HEAD commit.
+	for _, args := range [][]string{{"--cached"}, nil} {
+		changed, err := gitDiffChanged(ctx, path, repo.WorkTree, args...)
+		if ctx.Err() == context.DeadlineExceeded {
+			return valueTimeout, nil
+		}
+		if err != nil || changed {
+			return strconv.FormatBool(changed), err
+		}
+	}
+	return "false", nil
+}
+
+// gitDiffChanged runs git diff --quiet in dir and reports whether it found
+// changes. --no-optional-locks keeps the probe from rewriting the index.
+func gitDiffChanged(ctx context.Context, git, dir string, args ...string) (bool, error) {
+	// #nosec G204 -- fixed git arguments; dir is the cwd repository's work tree.
+	cmd := exec.CommandContext(ctx, git, append([]string{"--no-optional-locks", "diff", "--quiet", "--no-ext-diff"}, args...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("run git diff: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return false, nil
+}
+
+func cwdGitRepo() (*gitRepo, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("resolve cwd: %w", err)
+	}
+	return findGitRepo(cwd)
+}
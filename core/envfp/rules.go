@@ -0,0 +1,151 @@
+package envfp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/fsx"
+)
+
+// Values recorded when a rule's subject does not exist.
+const (
+	valueMissing  = "missing"
+	valueNone     = "none"
+	valueTimeout  = "timeout"
+	maxVersionLen = 200
+)
+
+// commandTimeout bounds each cmd:<name> --version probe.
+var commandTimeout = 5 * time.Second
+
+var commandNamePattern = regexp.MustCompile(`^[A-Za-z0-9._+-]+$`)
+
+// validateRule rejects malformed parameterized rules before anything runs.
+func validateRule(rule string) error {
+	switch {
+	case strings.HasPrefix(rule, "file:"):
+		if strings.TrimSpace(strings.TrimPrefix(rule, "file:")) == "" {
+			return fmt.Errorf("environment fingerprint rule %q needs a path", rule)
+		}
+	case strings.HasPrefix(rule, "glob:"):
+		pattern := strings.TrimSpace(strings.TrimPrefix(rule, "glob:"))
+		if pattern == "" || filepath.IsAbs(pattern) || strings.Contains(pattern, "..") {
+			return fmt.Errorf("environment fingerprint rule %q needs a relative pattern", rule)
+		}
+	case strings.HasPrefix(rule, "cmd:"):
+		if !commandNamePattern.MatchString(strings.TrimPrefix(rule, "cmd:")) {
+			return fmt.Errorf("environment fingerprint rule %q needs a command name", rule)
+		}
+	}
+	return nil
+}
+
+// fileValue hashes one file's contents.
+func fileValue(path string) (string, error) {
+	resolved, err := resolveRulePath(strings.TrimSpace(path))
+	if err != nil {
+		return "", err
+	}
+	// #nosec G304 -- file rules are declared in the jobspec and resolved within the working directory.
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return valueMissing, nil
+		}
+		return "", fmt.Errorf("read fingerprint file %s: %w", path, err)
+	}
+	return "sha256:" + sha256Hex(data), nil
+}
+
+// globValue hashes the sorted paths and contents of every file under the
+// working directory matching pattern.
+func globValue(pattern string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("resolve cwd: %w", err)
+	}
+	matches, err := fsx.Glob(cwd, pattern)
+	if err != nil {
+		return "", fmt.Errorf("fingerprint %w", err)
+	}
+
+	lines := make([]string, 0, len(matches))
+	for _, rel := range matches {
+		path := filepath.Join(cwd, filepath.FromSlash(rel))
+		info, err := os.Lstat(path)
+		if err != nil {
+			return "", fmt.Errorf("stat fingerprint file %s: %w", rel, err)
+		}
+		var data []byte
+		if info.Mode()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return "", fmt.Errorf("read fingerprint link %s: %w", rel, err)
+			}
+			data = []byte(target)
+		} else {
+			// #nosec G304 -- path comes from walking the working directory.
+			if data, err = os.ReadFile(path); err != nil {
+				return "", fmt.Errorf("read fingerprint file %s: %w", rel, err)
+			}
+		}
+		lines = append(lines, rel+"\x00"+sha256Hex(data)+"\n")
+	}
+	return fmt.Sprintf("sha256:%s files=%d", sha256Hex([]byte(strings.Join(lines, ""))), len(lines)), nil
+}
+
+// commandValue runs `<name> --version` and keeps the first output line.
+func commandValue(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return valueMissing, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	// #nosec G204 -- the command name is validated and only --version is passed.
+	cmd := exec.CommandContext(ctx, path, "--version")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second
+	runErr := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return valueTimeout, nil
+	}
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(out.String()), "\n", 2)[0])
+	if len(line) > maxVersionLen {
+		line = line[:maxVersionLen]
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Sprintf("exit=%d %s", exitErr.ExitCode(), line), nil
+		}
+		return "", fmt.Errorf("run %s --version: %w", name, runErr)
+	}
+	return line, nil
+}
+
+func resolveRulePath(path string) (string, error) {
+	cleaned := filepath.Clean(path)
+	if filepath.IsAbs(cleaned) {
+		return fsx.NormalizeAbsolutePath(cleaned)
+	}
+	return fsx.ResolveWithinWorkingDir(cleaned)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -15,6 +15,8 @@ const (
 	ECheckpointApprovalRequired Code = "E_CHECKPOINT_APPROVAL_REQUIRED"
 	EAcceptMissingArtifact      Code = "E_ACCEPT_MISSING_ARTIFACT"
 	EAcceptTestFail             Code = "E_ACCEPT_TEST_FAIL"
+	EAcceptWaiverExpired        Code = "E_ACCEPT_WAIVER_EXPIRED"
+	EAcceptCheckpointMissing    Code = "E_ACCEPT_CHECKPOINT_MISSING"
 	EVerifyHashMismatch         Code = "E_VERIFY_HASH_MISMATCH"
 	EStoreCorrupt               Code = "E_STORE_CORRUPT"
 	EEnvFingerprintMismatch     Code = "E_ENV_FINGERPRINT_MISMATCH"
@@ -22,6 +24,8 @@ const (
 	EInvalidStateTransition     Code = "E_INVALID_STATE_TRANSITION"
 	EInvalidInputSchema         Code = "E_INVALID_INPUT_SCHEMA"
 	EUnsafeOperation            Code = "E_UNSAFE_OPERATION"
+	EStepUncommitted            Code = "E_STEP_UNCOMMITTED"
+	EPolicyDenied               Code = "E_POLICY_DENIED"
 )
 
 type WrkrError struct {
@@ -47,11 +51,11 @@ func ExitCodeFor(code Code) int {
 		return 2
 	case ECheckpointApprovalRequired:
 		return 4
-	case EAcceptMissingArtifact, EAcceptTestFail:
+	case EAcceptMissingArtifact, EAcceptTestFail, EAcceptWaiverExpired, EAcceptCheckpointMissing:
 		return 5
 	case EInvalidInputSchema:
 		return 6
-	case EUnsafeOperation:
+	case EUnsafeOperation, EPolicyDenied:
 		return 8
 	default:
 		return 1
@@ -16,8 +16,11 @@ func TestExitCodeMapping(t *testing.T) {
 		ECheckpointApprovalRequired: 4,
 		EAcceptMissingArtifact:      5,
 		EAcceptTestFail:             5,
+		EAcceptWaiverExpired:        5,
+		EAcceptCheckpointMissing:    5,
 		EInvalidInputSchema:         6,
 		EUnsafeOperation:            8,
+		EPolicyDenied:               8,
 		EAdapterFail:                1,
 		EInvalidStateTransition:     1,
 	}
@@ -38,6 +41,8 @@ func TestReasonCodeCatalogIsStable(t *testing.T) {
 		ECheckpointApprovalRequired,
 		EAcceptMissingArtifact,
 		EAcceptTestFail,
+		EAcceptWaiverExpired,
+		EAcceptCheckpointMissing,
 		EVerifyHashMismatch,
 		EStoreCorrupt,
 		EEnvFingerprintMismatch,
@@ -45,6 +50,8 @@ func TestReasonCodeCatalogIsStable(t *testing.T) {
 		EInvalidStateTransition,
 		EInvalidInputSchema,
 		EUnsafeOperation,
+		EStepUncommitted,
+		EPolicyDenied,
 	}
 
 	seen := map[Code]bool{}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"testing"
 	"time"
@@ -166,3 +167,37 @@ func TestResolveWithinBaseRejectsNestedSymlinkEscape(t *testing.T) {
 		t.Fatal("expected nested symlink escape to be rejected")
 	}
 }
+
+func TestGlobMatchesAcrossDirectories(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, rel := range []string{"a.go", "pkg/b.go", "pkg/deep/c.go", "pkg/d.txt", ".git/e.go"} {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	cases := map[string][]string{
+		"**/*.go":   {"a.go", "pkg/b.go", "pkg/deep/c.go"},
+		"pkg/*.go":  {"pkg/b.go"},
+		"pkg/**":    {"pkg/b.go", "pkg/d.txt", "pkg/deep/c.go"},
+		"missing/*": {},
+	}
+	for pattern, want := range cases {
+		got, err := Glob(root, pattern)
+		if err != nil {
+			t.Fatalf("Glob(%q): %v", pattern, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("Glob(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+	if _, err := Glob(root, "../*.go"); err == nil {
+		t.Fatal("expected escaping glob to fail")
+	}
+}
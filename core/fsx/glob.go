@@ -0,0 +1,102 @@
+package fsx
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Glob returns the slash-separated paths, relative to root, of every file
+// under root matching pattern, sorted. `**` spans directories; `*` and `?`
+// stay within one path segment. `.git` directories are skipped.
+func Glob(root, pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(strings.TrimSpace(pattern)))
+	if pattern == "." || filepath.IsAbs(pattern) || strings.HasPrefix(pattern, "../") || pattern == ".." {
+		return nil, fmt.Errorf("glob %q must be relative", pattern)
+	}
+	matcher, err := CompileGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	start := filepath.Join(root, filepath.FromSlash(globPrefix(pattern)))
+	if _, err := os.Lstat(start); errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+
+	matches := []string{}
+	err = filepath.WalkDir(start, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matcher.MatchString(rel) {
+			matches = append(matches, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk glob %s: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// CompileGlob translates a slash-separated glob into an anchored regexp.
+func CompileGlob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// globPrefix is the leading run of pattern segments without wildcards, so
+// the walk starts as deep as possible.
+func globPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	static := make([]string, 0, len(segments))
+	for _, segment := range segments[:len(segments)-1] {
+		if strings.ContainsAny(segment, "*?") {
+			break
+		}
+		static = append(static, segment)
+	}
+	return strings.Join(static, "/")
+}
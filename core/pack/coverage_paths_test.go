@@ -168,7 +168,7 @@ func TestBuildArtifactsManifestCoveragePaths(t *testing.T) {
 				Added: []string{"reports/a.md"},
 			},
 		},
-	}, "test", now)
+	}, nil, "test", now)
 
 	if manifest.JobID != "job_artifacts" || manifest.CaptureMode != "reference-only" {
 		t.Fatalf("unexpected manifest header: %+v", manifest)
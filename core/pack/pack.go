@@ -9,6 +9,7 @@ import (
 
 	"github.com/davidahmann/wrkr/core/fsx"
 	"github.com/davidahmann/wrkr/core/out"
+	"github.com/davidahmann/wrkr/core/redact"
 	"github.com/davidahmann/wrkr/core/runner"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/sign"
@@ -71,6 +72,17 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 	if err != nil {
 		return ExportResult{}, err
 	}
+	captures, err := r.ListArtifactCaptures(jobID)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	// Events were masked when appended; masking again at export covers
+	// jobs recorded before redaction and env values only known now.
+	rules, err := s.Redaction(jobID)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	redactions := 0
 
 	files := map[string][]byte{}
 
@@ -88,7 +100,13 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 			"retry_count":     state.RetryCount,
 			"step_count":      state.StepCount,
 			"tool_call_count": state.ToolCallCount,
+			"tokens_in":       state.TokensIn,
+			"tokens_out":      state.TokensOut,
+			"estimated_cost":  state.EstimatedCost,
 		},
+		Policy:           state.Policy,
+		BudgetAmendments: state.BudgetAmendments,
+		PriceTable:       state.PriceTable,
 	}
 	jobBytes, err := EncodeJSONCanonical(jobRecord)
 	if err != nil {
@@ -113,6 +131,9 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 				}
 			}
 		}
+		masked, n := rules.Value(payload)
+		payload = masked.(map[string]any)
+		redactions += n
 		projectedEvents = append(projectedEvents, v1.EventRecord{
 			Envelope: v1.Envelope{
 				SchemaID:        "wrkr.event",
@@ -133,13 +154,45 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 	}
 	files["events.jsonl"] = eventBytes
 
+	checkpoints, n, err := redactRecords(rules, checkpoints)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	redactions += n
+	approvals, n, err = redactRecords(rules, approvals)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	redactions += n
+
+	for _, cp := range checkpoints {
+		for _, attachment := range cp.Attachments {
+			name := AttachmentPath(attachment)
+			if _, ok := files[name]; ok {
+				continue
+			}
+			data, err := r.ReadAttachment(jobID, attachment)
+			if err != nil {
+				return ExportResult{}, err
+			}
+			files[name] = data
+		}
+	}
+
 	checkpointBytes, err := MarshalJSONLCanonical(checkpoints)
 	if err != nil {
 		return ExportResult{}, err
 	}
 	files["checkpoints.jsonl"] = checkpointBytes
 
-	artifactsManifest := buildArtifactsManifest(jobID, checkpoints, producerVersion, now())
+	artifactsManifest := buildArtifactsManifest(jobID, checkpoints, captures, producerVersion, now())
+	for i, record := range artifactsManifest.Artifacts {
+		if path, n := rules.String(record.Path); n > 0 {
+			artifactsManifest.Artifacts[i].Path = path
+			artifactsManifest.Artifacts[i].PathRedacted = true
+			redactions += n
+		}
+	}
 	artifactBytes, err := EncodeJSONCanonical(artifactsManifest)
 	if err != nil {
 		return ExportResult{}, err
@@ -163,6 +216,14 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 			return ExportResult{}, err
 		}
 		files["accept/accept_result.json"] = canonicalAccept
+		logs, n, err := acceptLogs(s.JobDir(jobID), acceptBytes, rules)
+		if err != nil {
+			return ExportResult{}, err
+		}
+		for path, data := range logs {
+			files[path] = data
+		}
+		redactions += n
 	} else if !os.IsNotExist(err) {
 		return ExportResult{}, err
 	}
@@ -178,6 +239,8 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 		JobID: jobID,
 		Files: fileList,
 	}
+	ref := rules.Ref(redactions)
+	manifest.Redaction = &ref
 	manifestHash, err := ComputeManifestSHA256(manifest)
 	if err != nil {
 		return ExportResult{}, err
@@ -223,7 +286,17 @@ func ExportJobpack(jobID string, opts ExportOptions) (ExportResult, error) {
 	}, nil
 }
 
-func buildArtifactsManifest(jobID string, checkpoints []v1.Checkpoint, producerVersion string, now time.Time) v1.ArtifactsManifest {
+func buildArtifactsManifest(jobID string, checkpoints []v1.Checkpoint, captures []runner.ArtifactCapture, producerVersion string, now time.Time) v1.ArtifactsManifest {
+	envelope := v1.Envelope{
+		SchemaID:        "wrkr.artifacts_manifest",
+		SchemaVersion:   "v1",
+		CreatedAt:       now.UTC(),
+		ProducerVersion: producerVersion,
+	}
+	if len(captures) > 0 {
+		return capturedArtifactsManifest(jobID, captures, envelope)
+	}
+
 	seen := map[string]struct{}{}
 	for _, cp := range checkpoints {
 		for _, path := range cp.ArtifactsDelta.Added {
@@ -249,18 +322,112 @@ func buildArtifactsManifest(jobID string, checkpoints []v1.Checkpoint, producerV
 	}
 
 	return v1.ArtifactsManifest{
-		Envelope: v1.Envelope{
-			SchemaID:        "wrkr.artifacts_manifest",
-			SchemaVersion:   "v1",
-			CreatedAt:       now.UTC(),
-			ProducerVersion: producerVersion,
-		},
+		Envelope:    envelope,
 		JobID:       jobID,
 		CaptureMode: "reference-only",
 		Artifacts:   artifacts,
 	}
 }
 
+// capturedArtifactsManifest keeps the latest on-disk digest per path. A path
+// stays missing only if no later capture recorded it.
+func capturedArtifactsManifest(jobID string, captures []runner.ArtifactCapture, envelope v1.Envelope) v1.ArtifactsManifest {
+	latest := map[string]v1.ArtifactRecord{}
+	missing := map[string]struct{}{}
+	for _, capture := range captures {
+		for _, record := range capture.Artifacts {
+			latest[record.Path] = record
+			delete(missing, record.Path)
+		}
+		for _, path := range capture.Missing {
+			delete(latest, path)
+			missing[path] = struct{}{}
+		}
+	}
+
+	artifacts := make([]v1.ArtifactRecord, 0, len(latest))
+	for _, record := range latest {
+		artifacts = append(artifacts, record)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	missingPaths := make([]string, 0, len(missing))
+	for path := range missing {
+		missingPaths = append(missingPaths, path)
+	}
+	sort.Strings(missingPaths)
+
+	manifest := v1.ArtifactsManifest{
+		Envelope:    envelope,
+		JobID:       jobID,
+		CaptureMode: "capture",
+		Artifacts:   artifacts,
+	}
+	if len(missingPaths) > 0 {
+		manifest.Missing = missingPaths
+	}
+	return manifest
+}
+
+// AttachmentPath is where a checkpoint attachment is stored in a jobpack.
+func AttachmentPath(attachment v1.Attachment) string {
+	return "attachments/" + attachment.SHA256
+}
+
+// acceptLogs reads the command check logs listed in accept_result.json,
+// masked again like events.
+func acceptLogs(jobDir string, acceptBytes []byte, rules *redact.Ruleset) (map[string][]byte, int, error) {
+	var result v1.AcceptanceResult
+	if err := json.Unmarshal(acceptBytes, &result); err != nil {
+		return nil, 0, fmt.Errorf("decode accept_result: %w", err)
+	}
+	out := map[string][]byte{}
+	total := 0
+	for _, check := range result.Checks {
+		for _, rel := range check.Logs {
+			path, err := fsx.ResolveWithinBase(jobDir, rel)
+			if err != nil {
+				return nil, 0, fmt.Errorf("resolve acceptance log %s: %w", rel, err)
+			}
+			// #nosec G304 -- path is resolved within the store job dir.
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, 0, fmt.Errorf("read acceptance log %s: %w", rel, err)
+			}
+			masked, n := rules.String(string(data))
+			out[rel] = []byte(masked)
+			total += n
+		}
+	}
+	return out, total, nil
+}
+
+// redactRecords masks the string fields of each record by round-tripping it
+// through JSON.
+func redactRecords[T any](rules *redact.Ruleset, records []T) ([]T, int, error) {
+	total := 0
+	out := make([]T, 0, len(records))
+	for _, record := range records {
+		raw, err := json.Marshal(record)
+		if err != nil {
+			return nil, 0, fmt.Errorf("encode record for redaction: %w", err)
+		}
+		masked, n, err := rules.JSON(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+		if n > 0 {
+			var redacted T
+			if err := json.Unmarshal(masked, &redacted); err != nil {
+				return nil, 0, fmt.Errorf("decode redacted record: %w", err)
+			}
+			record = redacted
+			total += n
+		}
+		out = append(out, record)
+	}
+	return out, total, nil
+}
+
 func canonicalizeRawJSON(raw []byte) ([]byte, error) {
 	var data any
 	if err := json.Unmarshal(raw, &data); err != nil {
@@ -10,7 +10,10 @@ import (
 
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/redact"
 	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/sign"
 	"github.com/davidahmann/wrkr/core/store"
 	"github.com/davidahmann/wrkr/core/zipx"
 )
@@ -318,6 +321,96 @@ func TestExportPreservesExecutedFlagFromEventPayload(t *testing.T) {
 	}
 }
 
+func TestExportRecordsPolicyHash(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 18, 5, 0, 0, time.UTC)
+	setupJob(t, "job_policy_hash", now)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	ref := v1.PolicyRef{Path: "/work/policy.yaml", SHA256: strings.Repeat("a", 64)}
+	if _, err := r.RecordPolicy("job_policy_hash", ref); err != nil {
+		t.Fatalf("RecordPolicy: %v", err)
+	}
+
+	exported, err := ExportJobpack("job_policy_hash", ExportOptions{
+		OutDir:          t.TempDir(),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := VerifyJobpack(exported.Path); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	archive, err := LoadArchive(exported.Path)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	job, err := DecodeJobRecord(archive.Files)
+	if err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	if job.Policy == nil || *job.Policy != ref {
+		t.Fatalf("expected policy ref in job.json, got %+v", job.Policy)
+	}
+}
+
+func TestExportIncludesBudgetAmendments(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 18, 10, 0, 0, time.UTC)
+	setupJob(t, "job_budget_amended", now)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	amendment := v1.BudgetAmendment{
+		Reason:     "more steps needed",
+		ApprovedBy: "lead",
+		Changes:    []v1.BudgetLimitChange{{Dimension: "step_count", From: 5, To: 10}},
+	}
+	if _, err := r.AmendBudget("job_budget_amended", amendment); err != nil {
+		t.Fatalf("AmendBudget: %v", err)
+	}
+
+	exported, err := ExportJobpack("job_budget_amended", ExportOptions{
+		OutDir:          t.TempDir(),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := VerifyJobpack(exported.Path); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	archive, err := LoadArchive(exported.Path)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	job, err := DecodeJobRecord(archive.Files)
+	if err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	if len(job.BudgetAmendments) != 1 || job.BudgetAmendments[0].Reason != "more steps needed" || !job.BudgetAmendments[0].AmendedAt.Equal(now) {
+		t.Fatalf("expected amendment history in job.json, got %+v", job.BudgetAmendments)
+	}
+}
+
 func rewriteArchiveManifest(archive *Archive) error {
 	manifest := archive.Manifest
 	files := make(map[string][]byte, len(archive.Files))
@@ -343,3 +436,219 @@ func rewriteArchiveManifest(archive *Archive) error {
 	archive.Manifest = manifest
 	return nil
 }
+
+func TestExportCapturedArtifactsVerifyAgainstWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 18, 30, 0, 0, time.UTC)
+	setupJob(t, "job_pack_capture", now)
+
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "result.md"), []byte("v1"), 0o600); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if err := r.RecordArtifactCapture("job_pack_capture", runner.ArtifactCapture{
+		StepID:    "write",
+		Artifacts: []v1.ArtifactRecord{{Path: "result.md", SHA256: sign.SHA256Hex([]byte("v1"))}},
+		Missing:   []string{"notes.md"},
+	}); err != nil {
+		t.Fatalf("RecordArtifactCapture: %v", err)
+	}
+
+	exported, err := ExportJobpack("job_pack_capture", ExportOptions{
+		OutDir:          filepath.Join(t.TempDir(), "out"),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	result, err := VerifyJobpackWorkspace(exported.Path, workspace)
+	if err != nil {
+		t.Fatalf("VerifyJobpackWorkspace: %v", err)
+	}
+	if result.ArtifactsVerified != 1 {
+		t.Fatalf("expected one verified artifact, got %+v", result)
+	}
+
+	if err := os.WriteFile(filepath.Join(workspace, "result.md"), []byte("v2"), 0o600); err != nil {
+		t.Fatalf("rewrite artifact: %v", err)
+	}
+	_, err = VerifyJobpackWorkspace(exported.Path, workspace)
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EVerifyHashMismatch {
+		t.Fatalf("expected hash mismatch, got %v", err)
+	}
+
+	setupJob(t, "job_pack_reference", now)
+	reference, err := ExportJobpack("job_pack_reference", ExportOptions{
+		OutDir:          filepath.Join(t.TempDir(), "out"),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export reference: %v", err)
+	}
+	if _, err := VerifyJobpackWorkspace(reference.Path, workspace); err == nil {
+		t.Fatal("expected reference-only manifest to be rejected for workspace verify")
+	}
+}
+
+func TestExportRedactsSecretsAndReportsRuleset(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 18, 0, 0, 0, time.UTC)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	if err := s.SetRedaction("job_redact", redact.Config{EnvVars: []string{"WRKR_TEST_DEPLOY_KEY"}}); err != nil {
+		t.Fatalf("SetRedaction: %v", err)
+	}
+	setupJob(t, "job_redact", now)
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	if _, err := r.EmitCheckpoint("job_redact", runner.CheckpointInput{
+		Type:    "progress",
+		Summary: "ran deploy --api-key=abc123 with key-from-env-9",
+	}); err != nil {
+		t.Fatalf("emit checkpoint: %v", err)
+	}
+	// The env value only becomes known at export, so it is masked there.
+	t.Setenv("WRKR_TEST_DEPLOY_KEY", "key-from-env-9")
+
+	exported, err := ExportJobpack("job_redact", ExportOptions{
+		OutDir:          filepath.Join(t.TempDir(), "out"),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	archive, err := LoadArchive(exported.Path)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	for _, name := range []string{"events.jsonl", "checkpoints.jsonl"} {
+		data := string(archive.Files[name])
+		if strings.Contains(data, "abc123") || strings.Contains(data, "key-from-env-9") {
+			t.Fatalf("expected %s to be redacted: %s", name, data)
+		}
+	}
+	ref := archive.Manifest.Redaction
+	if ref == nil || len(ref.Builtins) == 0 || ref.Redactions != 2 || strings.Join(ref.EnvVars, ",") != "WRKR_TEST_DEPLOY_KEY" {
+		t.Fatalf("unexpected redaction report: %+v", ref)
+	}
+	if _, err := VerifyJobpack(exported.Path); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestExportIncludesCheckpointAttachments(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 18, 0, 0, 0, time.UTC)
+	setupJob(t, "job_attach", now)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	cp, err := r.EmitCheckpoint("job_attach", runner.CheckpointInput{
+		Type:        "progress",
+		Summary:     "tests passed",
+		Attachments: []runner.AttachmentInput{{Name: "test.log", Data: []byte("ok  ./...\n")}},
+	})
+	if err != nil {
+		t.Fatalf("emit checkpoint: %v", err)
+	}
+
+	exported, err := ExportJobpack("job_attach", ExportOptions{
+		OutDir:          filepath.Join(t.TempDir(), "out"),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	archive, err := LoadArchive(exported.Path)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	path := AttachmentPath(cp.Attachments[0])
+	if string(archive.Files[path]) != "ok  ./...\n" || fileHashes(archive.Manifest)[path] != cp.Attachments[0].SHA256 {
+		t.Fatalf("expected attachment %s in jobpack", path)
+	}
+	if _, err := VerifyJobpack(exported.Path); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if err := verifyAttachments(&Archive{Manifest: v1.JobpackManifest{}, Files: archive.Files}); err == nil {
+		t.Fatal("expected attachment missing from manifest to fail verification")
+	}
+}
+
+func TestExportIncludesAcceptanceLogs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	now := time.Date(2026, 2, 13, 18, 0, 0, 0, time.UTC)
+	setupJob(t, "job_accept_logs", now)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	jobDir := s.JobDir("job_accept_logs")
+	accept := `{"schema_id":"wrkr.accept_result","schema_version":"v1","created_at":"2026-02-13T18:00:00Z","producer_version":"test",` +
+		`"job_id":"job_accept_logs","checks_run":1,"checks_passed":1,"failures":[],"reason_codes":[],` +
+		`"checks":[{"name":"test_command","passed":true,"message":"command succeeded","duration_ms":12,` +
+		`"logs":["accept/test_command.stdout.log","accept/test_command.stderr.log"]}]}`
+	if err := os.WriteFile(filepath.Join(jobDir, "accept_result.json"), []byte(accept), 0o600); err != nil {
+		t.Fatalf("write accept_result: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(jobDir, "accept"), 0o750); err != nil {
+		t.Fatalf("mkdir accept: %v", err)
+	}
+	for name, content := range map[string]string{"test_command.stdout.log": "ok  ./...\n", "test_command.stderr.log": "token --api-key=abc123\n"} {
+		if err := os.WriteFile(filepath.Join(jobDir, "accept", name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write log: %v", err)
+		}
+	}
+
+	exported, err := ExportJobpack("job_accept_logs", ExportOptions{
+		OutDir:          filepath.Join(t.TempDir(), "out"),
+		ProducerVersion: "test",
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	archive, err := LoadArchive(exported.Path)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	if string(archive.Files["accept/test_command.stdout.log"]) != "ok  ./...\n" {
+		t.Fatalf("expected stdout log in jobpack, got %q", archive.Files["accept/test_command.stdout.log"])
+	}
+	if stderr := string(archive.Files["accept/test_command.stderr.log"]); stderr == "" || strings.Contains(stderr, "abc123") {
+		t.Fatalf("expected redacted stderr log in jobpack, got %q", stderr)
+	}
+	if _, err := VerifyJobpack(exported.Path); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
@@ -4,18 +4,24 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/schema/validate"
 	"github.com/davidahmann/wrkr/core/sign"
 )
 
 type VerifyResult struct {
-	JobID          string `json:"job_id"`
-	ManifestSHA256 string `json:"manifest_sha256"`
-	FilesVerified  int    `json:"files_verified"`
+	JobID             string `json:"job_id"`
+	ManifestSHA256    string `json:"manifest_sha256"`
+	FilesVerified     int    `json:"files_verified"`
+	ArtifactsVerified int    `json:"artifacts_verified,omitempty"`
+	// ArtifactsSkipped counts artifacts whose path was masked at export and
+	// so cannot be located in the workspace.
+	ArtifactsSkipped int `json:"artifacts_skipped,omitempty"`
 }
 
 func VerifyJobpack(path string) (VerifyResult, error) {
@@ -80,6 +86,9 @@ func VerifyJobpack(path string) (VerifyResult, error) {
 	if err := validateSchemaFiles(archive.Files); err != nil {
 		return VerifyResult{}, err
 	}
+	if err := verifyAttachments(archive); err != nil {
+		return VerifyResult{}, err
+	}
 
 	return VerifyResult{
 		JobID:          archive.Manifest.JobID,
@@ -88,6 +97,62 @@ func VerifyJobpack(path string) (VerifyResult, error) {
 	}, nil
 }
 
+// VerifyJobpackWorkspace verifies the jobpack and then checks every captured
+// artifact digest against the file at the same path under workspace.
+func VerifyJobpackWorkspace(path, workspace string) (VerifyResult, error) {
+	result, err := VerifyJobpack(path)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	archive, err := LoadArchive(path)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	var manifest v1.ArtifactsManifest
+	if err := json.Unmarshal(archive.Files["artifacts_manifest.json"], &manifest); err != nil {
+		return VerifyResult{}, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "artifacts_manifest decode failed", map[string]any{"error": err.Error()})
+	}
+	if manifest.CaptureMode != "capture" {
+		return VerifyResult{}, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"artifacts were not captured; workspace verification requires capture mode",
+			map[string]any{"capture_mode": manifest.CaptureMode},
+		)
+	}
+
+	for _, artifact := range manifest.Artifacts {
+		if artifact.PathRedacted {
+			result.ArtifactsSkipped++
+			continue
+		}
+		// SHA256 is the on-disk digest even when the stored blob was
+		// Redacted, so those records are checked like any other.
+		target, err := fsx.ResolveWithinBase(workspace, artifact.Path)
+		if err != nil {
+			return VerifyResult{}, wrkrerrors.New(wrkrerrors.EUnsafeOperation, "artifact path escapes workspace", map[string]any{"path": artifact.Path})
+		}
+		// #nosec G304 -- target is resolved within the operator-supplied workspace.
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return VerifyResult{}, wrkrerrors.New(
+				wrkrerrors.EVerifyHashMismatch,
+				"artifact missing from workspace",
+				map[string]any{"path": artifact.Path, "expected": artifact.SHA256},
+			)
+		}
+		actual := sign.SHA256Hex(data)
+		if actual != artifact.SHA256 {
+			return VerifyResult{}, wrkrerrors.New(
+				wrkrerrors.EVerifyHashMismatch,
+				"artifact hash mismatch",
+				map[string]any{"path": artifact.Path, "expected": artifact.SHA256, "actual": actual},
+			)
+		}
+	}
+	result.ArtifactsVerified = len(manifest.Artifacts) - result.ArtifactsSkipped
+	return result, nil
+}
+
 func validateSchemaFiles(files map[string][]byte) error {
 	if raw, ok := files["job.json"]; ok {
 		if err := validate.ValidateBytes(validate.JobSchemaRel, raw); err != nil {
@@ -140,6 +205,29 @@ func validateSchemaFiles(files map[string][]byte) error {
 	return nil
 }
 
+// verifyAttachments checks that every checkpoint attachment is packed under
+// attachments/ with a manifest hash equal to its recorded digest.
+func verifyAttachments(archive *Archive) error {
+	checkpoints, err := DecodeCheckpoints(archive.Files)
+	if err != nil {
+		return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "checkpoints decode failed", map[string]any{"error": err.Error()})
+	}
+	hashes := fileHashes(archive.Manifest)
+	for _, cp := range checkpoints {
+		for _, attachment := range cp.Attachments {
+			path := AttachmentPath(attachment)
+			if hashes[path] != attachment.SHA256 {
+				return wrkrerrors.New(
+					wrkrerrors.EVerifyHashMismatch,
+					"checkpoint attachment missing or mismatched",
+					map[string]any{"checkpoint_id": cp.CheckpointID, "name": attachment.Name, "path": path},
+				)
+			}
+		}
+	}
+	return nil
+}
+
 func jsonlLines(raw []byte) ([][]byte, error) {
 	parts := bytes.Split(raw, []byte{'\n'})
 	out := make([][]byte, 0, 8)
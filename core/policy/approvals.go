@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Approvals configures who may approve decision checkpoints and how many
+// distinct approvers a checkpoint needs before the job can resume.
+type Approvals struct {
+	GroupsFile string              `yaml:"groups_file" json:"groups_file,omitempty"`
+	Groups     map[string][]string `yaml:"groups" json:"groups,omitempty"`
+	Rules      []ApprovalRule      `yaml:"rules" json:"rules"`
+}
+
+// ApprovalRule applies to decision checkpoints whose required_action.kind
+// matches one of Kinds. Approvers lists the allowed identities after group
+// expansion; an empty list allows anyone except a forbidden submitter.
+type ApprovalRule struct {
+	Name               string   `yaml:"name" json:"name"`
+	Kinds              []string `yaml:"kinds" json:"kinds"`
+	Quorum             int      `yaml:"quorum" json:"quorum"`
+	Approvers          []string `yaml:"approvers" json:"approvers"`
+	Groups             []string `yaml:"groups" json:"groups,omitempty"`
+	ForbidSelfApproval bool     `yaml:"forbid_self_approval" json:"forbid_self_approval"`
+}
+
+type groupsFile struct {
+	Groups map[string][]string `yaml:"groups"`
+}
+
+// ApprovalRules returns the rules with groups expanded into Approvers. It
+// fails when a rule is unnamed, matches no kinds, or names an unknown group.
+func (p *Policy) ApprovalRules() ([]ApprovalRule, error) {
+	if p == nil {
+		return nil, nil
+	}
+	out := make([]ApprovalRule, 0, len(p.Approvals.Rules))
+	seen := map[string]struct{}{}
+	for i, rule := range p.Approvals.Rules {
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval rule name is required", map[string]any{"index": i})
+		}
+		if _, ok := seen[rule.Name]; ok {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval rule name is duplicated", map[string]any{"rule": rule.Name})
+		}
+		seen[rule.Name] = struct{}{}
+		rule.Kinds = normalizedList(rule.Kinds)
+		if len(rule.Kinds) == 0 {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval rule kinds are required", map[string]any{"rule": rule.Name})
+		}
+		if rule.Quorum < 0 {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval rule quorum must be positive", map[string]any{"rule": rule.Name, "quorum": rule.Quorum})
+		}
+		if rule.Quorum == 0 {
+			rule.Quorum = 1
+		}
+		approvers := append([]string{}, rule.Approvers...)
+		rule.Groups = normalizedList(rule.Groups)
+		for _, group := range rule.Groups {
+			members, ok := p.Approvals.Groups[group]
+			if !ok {
+				return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval rule references unknown group", map[string]any{"rule": rule.Name, "group": group})
+			}
+			approvers = append(approvers, members...)
+		}
+		rule.Approvers = normalizedList(approvers)
+		sort.Strings(rule.Approvers)
+		if len(rule.Approvers) > 0 && len(rule.Approvers) < rule.Quorum {
+			return nil, wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"approval rule quorum exceeds its approvers",
+				map[string]any{"rule": rule.Name, "quorum": rule.Quorum, "approvers": len(rule.Approvers)},
+			)
+		}
+		out = append(out, rule)
+	}
+	return out, nil
+}
+
+// MatchApprovalRule returns the first rule covering a required_action kind.
+func MatchApprovalRule(rules []ApprovalRule, kind string) (ApprovalRule, bool) {
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		return ApprovalRule{}, false
+	}
+	for _, rule := range rules {
+		for _, pattern := range rule.Kinds {
+			if matchGlob(pattern, kind) {
+				return rule, true
+			}
+		}
+	}
+	return ApprovalRule{}, false
+}
+
+// loadGroups merges approvals.groups_file into the inline groups. Inline
+// groups win when both define the same name.
+func (p *Policy) loadGroups() error {
+	if strings.TrimSpace(p.Approvals.GroupsFile) == "" {
+		return nil
+	}
+	resolved, err := resolvePath(p.Approvals.GroupsFile)
+	if err != nil {
+		return wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"approvals.groups_file must stay within working directory",
+			map[string]any{"path": p.Approvals.GroupsFile, "error": err.Error()},
+		)
+	}
+	root, err := os.OpenRoot(filepath.Dir(resolved))
+	if err != nil {
+		return fmt.Errorf("open approver groups dir: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+	raw, err := root.ReadFile(filepath.Base(resolved))
+	if err != nil {
+		return fmt.Errorf("read approver groups: %w", err)
+	}
+	var file groupsFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "decode approver groups yaml failed", map[string]any{"error": err.Error()})
+	}
+	if p.Approvals.Groups == nil {
+		p.Approvals.Groups = map[string][]string{}
+	}
+	for name, members := range file.Groups {
+		if _, ok := p.Approvals.Groups[name]; !ok {
+			p.Approvals.Groups[name] = members
+		}
+	}
+	return nil
+}
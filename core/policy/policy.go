@@ -0,0 +1,276 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	schemaID      = "wrkr.policy"
+	schemaVersion = "v1"
+
+	ActionAllow    = "allow"
+	ActionDeny     = "deny"
+	ActionApproval = "require_approval"
+)
+
+// Policy is a command and path policy evaluated before each reference step
+// or wrapped command runs. Patterns are globs where * matches any run of
+// characters (including /) and ? matches one character.
+type Policy struct {
+	SchemaID        string          `yaml:"schema_id" json:"schema_id"`
+	SchemaVersion   string          `yaml:"schema_version" json:"schema_version"`
+	Commands        Commands        `yaml:"commands" json:"commands"`
+	ProtectedPaths  []string        `yaml:"protected_paths" json:"protected_paths"`
+	RequireApproval RequireApproval `yaml:"require_approval" json:"require_approval"`
+	Approvals       Approvals       `yaml:"approvals" json:"approvals"`
+	Path            string          `yaml:"-" json:"path"`
+	SHA256          string          `yaml:"-" json:"sha256"`
+}
+
+type Commands struct {
+	Allow []string `yaml:"allow" json:"allow"`
+	Deny  []string `yaml:"deny" json:"deny"`
+}
+
+type RequireApproval struct {
+	Steps    []string `yaml:"steps" json:"steps"`
+	Commands []string `yaml:"commands" json:"commands"`
+}
+
+// Subject is one unit of work checked against the policy.
+type Subject struct {
+	StepID  string
+	Command string
+	Paths   []string
+}
+
+type Decision struct {
+	Action string `json:"action"`
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (d Decision) Allowed() bool {
+	return d.Action == ActionAllow
+}
+
+// Load reads a policy file. The hash covers the raw file bytes so reviewers
+// can match a jobpack to the exact file that governed the run.
+func Load(path string) (*Policy, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"policy path must stay within working directory",
+			map[string]any{"path": path, "error": err.Error()},
+		)
+	}
+	root, err := os.OpenRoot(filepath.Dir(resolved))
+	if err != nil {
+		return nil, fmt.Errorf("open policy dir: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+	raw, err := root.ReadFile(filepath.Base(resolved))
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	p, err := Parse(raw, resolved)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.loadGroups(); err != nil {
+		return nil, err
+	}
+	if _, err := p.ApprovalRules(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func Parse(raw []byte, path string) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "decode policy yaml failed", map[string]any{"error": err.Error()})
+	}
+	if p.SchemaID != "" && p.SchemaID != schemaID {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"policy schema_id must be wrkr.policy",
+			map[string]any{"schema_id": p.SchemaID},
+		)
+	}
+	p.normalize()
+	sum := sha256.Sum256(raw)
+	p.SHA256 = hex.EncodeToString(sum[:])
+	p.Path = path
+	return &p, nil
+}
+
+// Ref identifies the policy in the event log and jobpack.
+func (p *Policy) Ref() *v1.PolicyRef {
+	if p == nil {
+		return nil
+	}
+	return &v1.PolicyRef{Path: p.Path, SHA256: p.SHA256}
+}
+
+// Evaluate checks a subject in a fixed order: denied commands, protected
+// paths, the command allowlist, then approval rules. A nil policy allows
+// everything.
+func (p *Policy) Evaluate(subject Subject) Decision {
+	if p == nil {
+		return Decision{Action: ActionAllow}
+	}
+	command := strings.TrimSpace(subject.Command)
+	if command != "" {
+		for _, pattern := range p.Commands.Deny {
+			if matchGlob(pattern, command) {
+				return Decision{Action: ActionDeny, Rule: "commands.deny:" + pattern, Reason: "command matches a denied pattern"}
+			}
+		}
+	}
+
+	paths := append([]string{}, subject.Paths...)
+	paths = append(paths, commandPaths(command)...)
+	for _, path := range paths {
+		if protected, ok := p.protects(path); ok {
+			return Decision{Action: ActionDeny, Rule: "protected_paths:" + protected, Reason: "step touches protected path " + cleanPath(path)}
+		}
+	}
+
+	if command != "" && len(p.Commands.Allow) > 0 {
+		allowed := false
+		for _, pattern := range p.Commands.Allow {
+			if matchGlob(pattern, command) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return Decision{Action: ActionDeny, Rule: "commands.allow", Reason: "command does not match any allowed pattern"}
+		}
+	}
+
+	for _, pattern := range p.RequireApproval.Steps {
+		if subject.StepID != "" && matchGlob(pattern, subject.StepID) {
+			return Decision{Action: ActionApproval, Rule: "require_approval.steps:" + pattern, Reason: "step requires approval"}
+		}
+	}
+	if command != "" {
+		for _, pattern := range p.RequireApproval.Commands {
+			if matchGlob(pattern, command) {
+				return Decision{Action: ActionApproval, Rule: "require_approval.commands:" + pattern, Reason: "command requires approval"}
+			}
+		}
+	}
+	return Decision{Action: ActionAllow}
+}
+
+// protects reports the protected entry covering path. An entry covers the
+// path itself, anything beneath it, and any path its glob matches.
+func (p *Policy) protects(path string) (string, bool) {
+	cleaned := cleanPath(path)
+	if cleaned == "" || cleaned == "." {
+		return "", false
+	}
+	for _, entry := range p.ProtectedPaths {
+		prefix := strings.TrimSuffix(cleanPath(entry), "/")
+		if cleaned == prefix || strings.HasPrefix(cleaned, prefix+"/") || matchGlob(entry, cleaned) {
+			return entry, true
+		}
+	}
+	return "", false
+}
+
+func (p *Policy) normalize() {
+	if strings.TrimSpace(p.SchemaID) == "" {
+		p.SchemaID = schemaID
+	}
+	if strings.TrimSpace(p.SchemaVersion) == "" {
+		p.SchemaVersion = schemaVersion
+	}
+	p.Commands.Allow = normalizedList(p.Commands.Allow)
+	p.Commands.Deny = normalizedList(p.Commands.Deny)
+	p.ProtectedPaths = normalizedList(p.ProtectedPaths)
+	p.RequireApproval.Steps = normalizedList(p.RequireApproval.Steps)
+	p.RequireApproval.Commands = normalizedList(p.RequireApproval.Commands)
+}
+
+// commandPaths extracts path-like tokens from a shell command so writes such
+// as "echo x > .env" are checked against protected paths.
+func commandPaths(command string) []string {
+	out := []string{}
+	for _, token := range strings.Fields(command) {
+		token = strings.TrimLeft(token, "<>|&;0123456789")
+		token = strings.Trim(token, `'"();`)
+		if token == "" || strings.HasPrefix(token, "-") || strings.Contains(token, "=") {
+			continue
+		}
+		out = append(out, token)
+	}
+	return out
+}
+
+func cleanPath(path string) string {
+	cleaned := filepath.ToSlash(filepath.Clean(strings.TrimSpace(path)))
+	return strings.TrimPrefix(cleaned, "./")
+}
+
+func matchGlob(pattern, value string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+func resolvePath(path string) (string, error) {
+	cleaned := filepath.Clean(strings.TrimSpace(path))
+	if filepath.IsAbs(cleaned) {
+		return fsx.NormalizeAbsolutePath(cleaned)
+	}
+	return fsx.ResolveWithinWorkingDir(cleaned)
+}
+
+func normalizedList(in []string) []string {
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, raw := range in {
+		v := strings.TrimSpace(raw)
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateOrder(t *testing.T) {
+	t.Parallel()
+
+	p, err := Parse([]byte(`
+commands:
+  allow: ["go *", "echo *", "make *"]
+  deny: ["*rm -rf*"]
+protected_paths: [".env", "secrets/", "*.pem"]
+require_approval:
+  steps: ["deploy*"]
+  commands: ["make release*"]
+`), "policy.yaml")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(p.SHA256) != 64 || p.SchemaID != "wrkr.policy" {
+		t.Fatalf("unexpected policy metadata: %+v", p)
+	}
+
+	cases := []struct {
+		name    string
+		subject Subject
+		action  string
+		rule    string
+	}{
+		{"allowed", Subject{StepID: "build", Command: "go test ./..."}, ActionAllow, ""},
+		{"denied pattern wins over allowlist", Subject{StepID: "build", Command: "go test && rm -rf /tmp/x"}, ActionDeny, "commands.deny:*rm -rf*"},
+		{"protected artifact", Subject{StepID: "build", Command: "go build", Paths: []string{"secrets/token"}}, ActionDeny, "protected_paths:secrets/"},
+		{"protected command path", Subject{StepID: "build", Command: "echo KEY=1 >./.env"}, ActionDeny, "protected_paths:.env"},
+		{"protected glob", Subject{StepID: "build", Command: "echo key.pem"}, ActionDeny, "protected_paths:*.pem"},
+		{"allowlist miss", Subject{StepID: "build", Command: "python x.py"}, ActionDeny, "commands.allow"},
+		{"step approval", Subject{StepID: "deploy-prod", Command: "go run ./deploy"}, ActionApproval, "require_approval.steps:deploy*"},
+		{"command approval", Subject{StepID: "ship", Command: "make release VERSION=1"}, ActionApproval, "require_approval.commands:make release*"},
+		{"no command", Subject{StepID: "review"}, ActionAllow, ""},
+	}
+	for _, tc := range cases {
+		got := p.Evaluate(tc.subject)
+		if got.Action != tc.action || got.Rule != tc.rule {
+			t.Fatalf("%s: expected %s/%s, got %+v", tc.name, tc.action, tc.rule, got)
+		}
+	}
+
+	var none *Policy
+	if !none.Evaluate(Subject{Command: "rm -rf /"}).Allowed() || none.Ref() != nil {
+		t.Fatal("expected nil policy to allow everything")
+	}
+}
+
+func TestLoadHashesRawBytes(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte("commands:\n  deny: [\"git push*\"]\n"), 0o600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	first, err := Load("policy.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte("commands:\n  deny: [\"git push*\"]  \n"), 0o600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	second, err := Load("policy.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if first.SHA256 == second.SHA256 || first.Ref().Path != filepath.Join(dir, "policy.yaml") {
+		t.Fatalf("expected byte-level hash and resolved path, got %+v %+v", first.Ref(), second.Ref())
+	}
+
+	if _, err := Load("../outside.yaml"); err == nil {
+		t.Fatal("expected path outside working directory to fail")
+	}
+	if _, err := Parse([]byte("schema_id: other\n"), "x"); err == nil {
+		t.Fatal("expected schema_id mismatch to fail")
+	}
+}
+
+func TestLoadApprovalRulesExpandsGroups(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "approvers.yaml"), []byte("groups:\n  sre: [carol, dave]\n  release: [ignored]\n"), 0o600); err != nil {
+		t.Fatalf("write groups: %v", err)
+	}
+	raw := `
+approvals:
+  groups_file: approvers.yaml
+  groups:
+    release: [alice, bob]
+  rules:
+    - name: production
+      kinds: ["deploy*", policy_approval]
+      quorum: 2
+      groups: [release, sre]
+      approvers: [erin]
+      forbid_self_approval: true
+`
+	if err := os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(raw), 0o600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	p, err := Load("policy.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	rules, err := p.ApprovalRules()
+	if err != nil {
+		t.Fatalf("ApprovalRules: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Quorum != 2 || !rules[0].ForbidSelfApproval {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+	want := []string{"alice", "bob", "carol", "dave", "erin"}
+	if strings.Join(rules[0].Approvers, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected approvers %v, got %v", want, rules[0].Approvers)
+	}
+	if rule, ok := MatchApprovalRule(rules, "deploy_prod"); !ok || rule.Name != "production" {
+		t.Fatalf("expected deploy_prod to match production, got %+v %v", rule, ok)
+	}
+	if _, ok := MatchApprovalRule(rules, "approval"); ok {
+		t.Fatal("expected plain approval kind to match no rule")
+	}
+
+	bad := map[string]string{
+		"unknown group":    "approvals:\n  rules:\n    - {name: r, kinds: [x], groups: [missing]}\n",
+		"missing kinds":    "approvals:\n  rules:\n    - {name: r}\n",
+		"missing name":     "approvals:\n  rules:\n    - {kinds: [x]}\n",
+		"quorum too large": "approvals:\n  rules:\n    - {name: r, kinds: [x], quorum: 3, approvers: [a, b]}\n",
+	}
+	for name, body := range bad {
+		if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte(body), 0o600); err != nil {
+			t.Fatalf("write bad policy: %v", err)
+		}
+		if _, err := Load("bad.yaml"); err == nil {
+			t.Fatalf("%s: expected load to fail", name)
+		}
+	}
+}
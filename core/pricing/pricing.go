@@ -0,0 +1,147 @@
+package pricing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	schemaID      = "wrkr.price_table"
+	schemaVersion = "v1"
+
+	defaultCurrency = "USD"
+)
+
+// Table maps model identifiers to token prices. Costs computed from the
+// same table and token counts are always the same number, so a reported cost
+// can be reproduced offline from the jobpack.
+type Table struct {
+	SchemaID      string           `yaml:"schema_id" json:"schema_id"`
+	SchemaVersion string           `yaml:"schema_version" json:"schema_version"`
+	Version       string           `yaml:"version" json:"version"`
+	Currency      string           `yaml:"currency" json:"currency"`
+	Models        map[string]Price `yaml:"models" json:"models"`
+	Path          string           `yaml:"-" json:"path"`
+	SHA256        string           `yaml:"-" json:"sha256"`
+}
+
+type Price struct {
+	InputPer1K  float64 `yaml:"input_per_1k_tokens" json:"input_per_1k_tokens"`
+	OutputPer1K float64 `yaml:"output_per_1k_tokens" json:"output_per_1k_tokens"`
+}
+
+// Load reads a price table file. The hash covers the raw file bytes.
+func Load(path string) (*Table, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"price table path must stay within working directory",
+			map[string]any{"path": path, "error": err.Error()},
+		)
+	}
+	root, err := os.OpenRoot(filepath.Dir(resolved))
+	if err != nil {
+		return nil, fmt.Errorf("open price table dir: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+	raw, err := root.ReadFile(filepath.Base(resolved))
+	if err != nil {
+		return nil, fmt.Errorf("read price table: %w", err)
+	}
+	return Parse(raw, resolved)
+}
+
+func Parse(raw []byte, path string) (*Table, error) {
+	var t Table
+	if err := yaml.Unmarshal(raw, &t); err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "decode price table yaml failed", map[string]any{"error": err.Error()})
+	}
+	if t.SchemaID == "" {
+		t.SchemaID = schemaID
+	}
+	if t.SchemaVersion == "" {
+		t.SchemaVersion = schemaVersion
+	}
+	if t.SchemaID != schemaID || t.SchemaVersion != schemaVersion {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"price table schema mismatch",
+			map[string]any{"schema_id": t.SchemaID, "schema_version": t.SchemaVersion},
+		)
+	}
+	t.Version = strings.TrimSpace(t.Version)
+	if t.Version == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "price table version is required", map[string]any{"path": path})
+	}
+	if strings.TrimSpace(t.Currency) == "" {
+		t.Currency = defaultCurrency
+	}
+	if len(t.Models) == 0 {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "price table has no models", map[string]any{"path": path})
+	}
+	for model, price := range t.Models {
+		if strings.TrimSpace(model) == "" || price.InputPer1K < 0 || price.OutputPer1K < 0 {
+			return nil, wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"price table entry is invalid",
+				map[string]any{"path": path, "model": model},
+			)
+		}
+	}
+	sum := sha256.Sum256(raw)
+	t.SHA256 = hex.EncodeToString(sum[:])
+	t.Path = path
+	return &t, nil
+}
+
+// Ref identifies the table in the event log and jobpack.
+func (t *Table) Ref() *v1.PriceTableRef {
+	if t == nil {
+		return nil
+	}
+	return &v1.PriceTableRef{Path: t.Path, SHA256: t.SHA256, Version: t.Version, Currency: t.Currency}
+}
+
+// Cost prices token usage for a model, rounded to a millionth of the
+// currency unit. Unknown models are an E_INVALID_INPUT_SCHEMA error.
+func (t *Table) Cost(model string, tokensIn, tokensOut int) (float64, error) {
+	price, ok := t.Models[strings.TrimSpace(model)]
+	if !ok {
+		return 0, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"model is not in the price table",
+			map[string]any{"model": model, "price_table_version": t.Version, "known_models": t.models()},
+		)
+	}
+	cost := (float64(tokensIn)*price.InputPer1K + float64(tokensOut)*price.OutputPer1K) / 1000
+	return math.Round(cost*1e6) / 1e6, nil
+}
+
+func (t *Table) models() []string {
+	out := make([]string, 0, len(t.Models))
+	for model := range t.Models {
+		out = append(out, model)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func resolvePath(path string) (string, error) {
+	cleaned := filepath.Clean(strings.TrimSpace(path))
+	if filepath.IsAbs(cleaned) {
+		return fsx.NormalizeAbsolutePath(cleaned)
+	}
+	return fsx.ResolveWithinWorkingDir(cleaned)
+}
@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sampleTable = `schema_id: wrkr.price_table
+schema_version: v1
+version: "2026-02-01"
+models:
+  gpt-test:
+    input_per_1k_tokens: 0.003
+    output_per_1k_tokens: 0.015
+`
+
+func TestCostIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	table, err := Parse([]byte(sampleTable), "prices.yaml")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if table.Currency != "USD" || len(table.SHA256) != 64 {
+		t.Fatalf("unexpected table metadata: %+v", table)
+	}
+	cost, err := table.Cost("gpt-test", 1234, 567)
+	if err != nil {
+		t.Fatalf("Cost: %v", err)
+	}
+	if cost != 0.012207 {
+		t.Fatalf("expected 0.012207, got %v", cost)
+	}
+	if _, err := table.Cost("other-model", 1, 1); err == nil {
+		t.Fatal("expected unknown model error")
+	}
+	ref := table.Ref()
+	if ref.Version != "2026-02-01" || ref.SHA256 != table.SHA256 || ref.Path != "prices.yaml" {
+		t.Fatalf("unexpected ref: %+v", ref)
+	}
+}
+
+func TestParseRejectsInvalidTables(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]string{
+		"missing version": "models: {m: {input_per_1k_tokens: 1}}\n",
+		"no models":       "version: v1\n",
+		"negative price":  "version: v1\nmodels: {m: {input_per_1k_tokens: -1}}\n",
+		"schema":          "schema_id: wrkr.policy\nversion: v1\nmodels: {m: {}}\n",
+		"yaml":            "models: [\n",
+	} {
+		if _, err := Parse([]byte(raw), "x"); err == nil {
+			t.Fatalf("%s: expected parse error", name)
+		}
+	}
+}
+
+func TestLoadResolvesWithinWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "prices.yaml"), []byte(sampleTable), 0o600); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	table, err := Load("prices.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if table.Path != filepath.Join(dir, "prices.yaml") {
+		t.Fatalf("unexpected path: %s", table.Path)
+	}
+	if _, err := Load("../prices.yaml"); err == nil {
+		t.Fatal("expected path outside working directory to fail")
+	}
+}
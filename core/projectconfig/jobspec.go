@@ -187,7 +187,7 @@ func defaultJobSpec(now time.Time, producerVersion string) v1.JobSpec {
 		},
 		CheckpointPolicy: v1.CheckpointPolicy{
 			MinIntervalSeconds: 10,
-			RequiredTypes:      []string{"plan", "progress", "decision-needed", "blocked", "completed"},
+			RequiredTypes:      []string{"plan", "progress", "completed"},
 		},
 		EnvironmentFingerprint: v1.EnvironmentFingerprint{
 			Rules: []string{"go_version", "os", "arch"},
@@ -0,0 +1,62 @@
+package projectconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSandboxProfile reads a sandbox profile from YAML or JSON. The file may
+// hold the profile itself or a jobspec-shaped document with a sandbox key.
+func LoadSandboxProfile(path string) (*v1.SandboxSpec, error) {
+	target := strings.TrimSpace(path)
+	if target == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "sandbox profile path is required", nil)
+	}
+	resolved, err := resolveJobSpecPath(target)
+	if err != nil {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"sandbox profile path must stay within working directory",
+			map[string]any{"path": target, "error": err.Error()},
+		)
+	}
+	root, err := os.OpenRoot(filepath.Dir(resolved))
+	if err != nil {
+		return nil, fmt.Errorf("open sandbox profile dir: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+	raw, err := root.ReadFile(filepath.Base(resolved))
+	if err != nil {
+		return nil, fmt.Errorf("read sandbox profile: %w", err)
+	}
+
+	var generic any
+	if err := yaml.Unmarshal(raw, &generic); err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "decode sandbox profile failed", map[string]any{"error": err.Error()})
+	}
+	normalized := normalizeYAMLValue(generic)
+	if doc, ok := normalized.(map[string]any); ok {
+		if nested, ok := doc["sandbox"].(map[string]any); ok {
+			normalized = nested
+		}
+	}
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("normalize sandbox profile: %w", err)
+	}
+	var spec v1.SandboxSpec
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "sandbox profile invalid", map[string]any{"error": err.Error()})
+	}
+	return &spec, nil
+}
@@ -0,0 +1,73 @@
+package projectconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadSandboxProfileAndJobSpecSandbox(t *testing.T) {
+	wd := t.TempDir()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(wd); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(orig)
+	})
+
+	if err := os.WriteFile("profile.yaml", []byte("cpu_seconds: 10\nenv_allowlist: [PATH]\nnetwork: none\n"), 0o600); err != nil {
+		t.Fatalf("write profile: %v", err)
+	}
+	spec, err := LoadSandboxProfile("profile.yaml")
+	if err != nil {
+		t.Fatalf("LoadSandboxProfile: %v", err)
+	}
+	if spec.CPUSeconds != 10 || spec.Network != "none" || len(spec.EnvAllowlist) != 1 {
+		t.Fatalf("unexpected profile: %+v", spec)
+	}
+	if err := os.WriteFile("bad.yaml", []byte("cpu_seconds: 10\nunknown: true\n"), 0o600); err != nil {
+		t.Fatalf("write bad profile: %v", err)
+	}
+	if _, err := LoadSandboxProfile("bad.yaml"); err == nil {
+		t.Fatal("expected unknown field error")
+	}
+
+	now := time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC)
+	if _, err := InitJobSpec("jobspec.yaml", false, now, "test"); err != nil {
+		t.Fatalf("InitJobSpec: %v", err)
+	}
+	raw, err := os.ReadFile("jobspec.yaml")
+	if err != nil {
+		t.Fatalf("read jobspec: %v", err)
+	}
+	withSandbox := string(raw) + "sandbox:\n  read_only: true\n  writable_paths: [out]\n  memory_mb: 512\n"
+	if err := os.WriteFile("jobspec.yaml", []byte(withSandbox), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+	loaded, err := LoadJobSpec("jobspec.yaml")
+	if err != nil {
+		t.Fatalf("LoadJobSpec: %v", err)
+	}
+	if loaded.Sandbox == nil || !loaded.Sandbox.ReadOnly || loaded.Sandbox.MemoryMB != 512 {
+		t.Fatalf("unexpected jobspec sandbox: %+v", loaded.Sandbox)
+	}
+	fromSpec, err := LoadSandboxProfile("jobspec.yaml")
+	if err != nil {
+		t.Fatalf("LoadSandboxProfile(jobspec): %v", err)
+	}
+	if strings.Join(fromSpec.WritablePaths, ",") != "out" {
+		t.Fatalf("unexpected nested profile: %+v", fromSpec)
+	}
+
+	if err := os.WriteFile("jobspec.yaml", []byte(string(raw)+"sandbox:\n  network: bridge\n"), 0o600); err != nil {
+		t.Fatalf("write jobspec: %v", err)
+	}
+	if _, err := LoadJobSpec("jobspec.yaml"); err == nil {
+		t.Fatal("expected schema rejection of unknown network mode")
+	}
+}
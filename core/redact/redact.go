@@ -0,0 +1,442 @@
+// Package redact masks secrets in strings and JSON payloads before they are
+// persisted to the job store or exported in a jobpack.
+package redact
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// Marker replaces every redacted secret.
+const Marker = "[REDACTED]"
+
+// minEnvValueLength keeps short env values such as "1" or "dev" from
+// blanking out unrelated text.
+const minEnvValueLength = 4
+
+// Config is the jobspec `redaction` block. Patterns are extra regular
+// expressions whose matches are masked; EnvVars name environment variables
+// whose current values are masked wherever they appear.
+type Config struct {
+	Patterns []string `json:"patterns,omitempty"`
+	EnvVars  []string `json:"env_vars,omitempty"`
+}
+
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	replace string
+}
+
+var builtins = []detector{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), Marker},
+	{"aws_access_key_id", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), Marker},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), Marker},
+	{"slack_token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), Marker},
+	{"api_secret_key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`), Marker},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), Marker},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`), Marker},
+	{"url_credentials", regexp.MustCompile(`(://[^/\s:@]+:)[^@\s/]+@`), "${1}" + Marker + "@"},
+	{"bearer_token", regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]+=*`), "${1}" + Marker},
+	{"secret_assignment", regexp.MustCompile(`(?i)((?:api[_-]?key|access[_-]?key|token|secret|password|passwd)["']?\s*[:=]\s*["']?)[^\s,;"'&]+`), "${1}" + Marker},
+}
+
+// sensitiveKeyRule names the map key rule in Builtins. String values under a
+// field name with a sensitive segment are masked whole, at any depth.
+const sensitiveKeyRule = "sensitive_key"
+
+// fieldName keeps data keys such as fingerprint rules (env:API_TOKEN), whose
+// digests must stay comparable, out of the key rule.
+var fieldName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// sensitiveSegments are whole key segments that mark a secret, so "auth"
+// matches auth_header but not author.
+var sensitiveSegments = map[string]struct{}{
+	"secret": {}, "secrets": {},
+	"token": {}, "tokens": {},
+	"apikey": {}, "apikeys": {},
+	"auth": {}, "authorization": {},
+	"credential": {}, "credentials": {},
+	"cookie": {}, "cookies": {},
+	"session": {}, "sessions": {},
+}
+
+// textFields are the payload keys that carry free text: summaries, commands,
+// tool arguments and output, errors and reasons.
+var textFields = map[string]struct{}{
+	"summary":            {},
+	"instructions":       {},
+	"description":        {},
+	"command":            {},
+	"output":             {},
+	"arguments":          {},
+	"structured_content": {},
+	"result_preview":     {},
+	"error":              {},
+	"message":            {},
+	"reason":             {},
+}
+
+// Builtins returns the names of the built-in detectors in evaluation order.
+func Builtins() []string {
+	out := make([]string, 0, len(builtins)+1)
+	for _, d := range builtins {
+		out = append(out, d.name)
+	}
+	return append(out, sensitiveKeyRule)
+}
+
+// Ruleset is a compiled redaction configuration. The built-in detectors are
+// always active.
+type Ruleset struct {
+	cfg      Config
+	patterns []*regexp.Regexp
+	keys     []string
+}
+
+// New compiles cfg into a Ruleset.
+func New(cfg Config) (*Ruleset, error) {
+	r := &Ruleset{}
+	for _, raw := range cfg.Patterns {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(raw)
+		if err != nil {
+			return nil, wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"redaction pattern is invalid",
+				map[string]any{"pattern": raw, "error": err.Error()},
+			)
+		}
+		r.patterns = append(r.patterns, pattern)
+		r.cfg.Patterns = append(r.cfg.Patterns, raw)
+	}
+	seen := map[string]struct{}{}
+	for _, name := range cfg.EnvVars {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		r.cfg.EnvVars = append(r.cfg.EnvVars, name)
+	}
+	sort.Strings(r.cfg.EnvVars)
+	return r, nil
+}
+
+// Default returns a Ruleset with only the built-in detectors.
+func Default() *Ruleset {
+	return &Ruleset{}
+}
+
+// WithKeys returns a copy of r that also masks values under the given map
+// keys, compared case-insensitively. The extra keys are not part of Config.
+func (r *Ruleset) WithKeys(keys []string) *Ruleset {
+	out := *r
+	out.keys = append([]string(nil), r.keys...)
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			out.keys = append(out.keys, key)
+		}
+	}
+	return &out
+}
+
+// Config returns the normalized configuration the Ruleset was built from.
+func (r *Ruleset) Config() Config {
+	return Config{
+		Patterns: append([]string(nil), r.cfg.Patterns...),
+		EnvVars:  append([]string(nil), r.cfg.EnvVars...),
+	}
+}
+
+// Ref describes the ruleset for reports. Count is the number of redactions
+// applied by the caller.
+func (r *Ruleset) Ref(count int) v1.RedactionRef {
+	ref := v1.RedactionRef{
+		Builtins:   Builtins(),
+		Patterns:   r.cfg.Patterns,
+		EnvVars:    r.cfg.EnvVars,
+		Redactions: count,
+	}
+	raw, _ := json.Marshal(map[string]any{
+		"builtins": ref.Builtins,
+		"patterns": ref.Patterns,
+		"env_vars": ref.EnvVars,
+	})
+	sum := sha256.Sum256(raw)
+	ref.SHA256 = hex.EncodeToString(sum[:])
+	return ref
+}
+
+// String masks secrets in text and reports how many were replaced.
+func (r *Ruleset) String(text string) (string, int) {
+	masked, count := r.Bytes([]byte(text))
+	return string(masked), count
+}
+
+// Bytes masks secrets in data, which need not be valid UTF-8, and reports
+// how many were replaced.
+func (r *Ruleset) Bytes(data []byte) ([]byte, int) {
+	count := 0
+	for _, value := range r.envValues() {
+		if n := bytes.Count(data, []byte(value)); n > 0 {
+			data = bytes.ReplaceAll(data, []byte(value), []byte(Marker))
+			count += n
+		}
+	}
+	for _, d := range builtins {
+		data, count = replace(d.pattern, data, d.replace, count)
+	}
+	for _, pattern := range r.patterns {
+		data, count = replace(pattern, data, Marker, count)
+	}
+	return data, count
+}
+
+// Value returns a copy of v with every string masked. Map keys are kept;
+// values under sensitive keys are masked whole.
+func (r *Ruleset) Value(v any) (any, int) {
+	switch typed := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		total := 0
+		for key, value := range typed {
+			var (
+				masked any
+				n      int
+			)
+			if r.sensitive(key) {
+				masked, n = maskStrings(value)
+			} else {
+				masked, n = r.Value(value)
+			}
+			out[key] = masked
+			total += n
+		}
+		return out, total
+	case []any:
+		out := make([]any, 0, len(typed))
+		total := 0
+		for _, item := range typed {
+			masked, n := r.Value(item)
+			out = append(out, masked)
+			total += n
+		}
+		return out, total
+	case string:
+		return r.String(typed)
+	default:
+		return v, 0
+	}
+}
+
+// sensitive reports whether values under key are secrets.
+func (r *Ruleset) sensitive(key string) bool {
+	if fieldName.MatchString(key) && sensitiveKey(key) {
+		return true
+	}
+	for _, extra := range r.keys {
+		if strings.EqualFold(extra, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveKey reports whether a segment of key, split at separators and
+// camelCase boundaries, names a secret.
+func sensitiveKey(key string) bool {
+	segments := keySegments(key)
+	for i, segment := range segments {
+		if _, ok := sensitiveSegments[segment]; ok || strings.HasPrefix(segment, "passw") {
+			return true
+		}
+		if segment == "key" && i > 0 && (segments[i-1] == "api" || segments[i-1] == "private") {
+			return true
+		}
+	}
+	return false
+}
+
+// keySegments lowercases key and splits it at "_", ".", "-" and camelCase
+// boundaries: "x-apiKey" and "APIKey" both give [... api key].
+func keySegments(key string) []string {
+	var (
+		segments []string
+		current  []rune
+	)
+	runes := []rune(key)
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, c := range runes {
+		if c == '_' || c == '.' || c == '-' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(c) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, c)
+	}
+	flush()
+	return segments
+}
+
+// maskStrings replaces every non-empty string in v with Marker. Numbers and
+// booleans are kept so typed records still decode.
+func maskStrings(v any) (any, int) {
+	switch typed := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		total := 0
+		for key, value := range typed {
+			masked, n := maskStrings(value)
+			out[key] = masked
+			total += n
+		}
+		return out, total
+	case []any:
+		out := make([]any, 0, len(typed))
+		total := 0
+		for _, item := range typed {
+			masked, n := maskStrings(item)
+			out = append(out, masked)
+			total += n
+		}
+		return out, total
+	case string:
+		if typed == "" || typed == Marker {
+			return typed, 0
+		}
+		return Marker, 1
+	default:
+		return v, 0
+	}
+}
+
+// Text returns a copy of v in which only the values under free-text keys are
+// masked, at any depth. Other fields, such as IDs, paths and config, are kept.
+func (r *Ruleset) Text(v any) (any, int) {
+	switch typed := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		total := 0
+		for key, value := range typed {
+			var (
+				masked any
+				n      int
+			)
+			if _, ok := textFields[key]; ok {
+				masked, n = r.Value(value)
+			} else {
+				masked, n = r.Text(value)
+			}
+			out[key] = masked
+			total += n
+		}
+		return out, total
+	case []any:
+		out := make([]any, 0, len(typed))
+		total := 0
+		for _, item := range typed {
+			masked, n := r.Text(item)
+			out = append(out, masked)
+			total += n
+		}
+		return out, total
+	default:
+		return v, 0
+	}
+}
+
+// JSON masks the string values of a JSON document. raw is returned unchanged
+// when nothing was redacted.
+func (r *Ruleset) JSON(raw []byte) ([]byte, int, error) {
+	return r.json(raw, r.Value)
+}
+
+// TextJSON is JSON restricted to the free-text fields masked by Text.
+func (r *Ruleset) TextJSON(raw []byte) ([]byte, int, error) {
+	return r.json(raw, r.Text)
+}
+
+func (r *Ruleset) json(raw []byte, mask func(any) (any, int)) ([]byte, int, error) {
+	if len(raw) == 0 {
+		return raw, 0, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, 0, fmt.Errorf("decode json for redaction: %w", err)
+	}
+	masked, count := mask(decoded)
+	if count == 0 {
+		return raw, 0, nil
+	}
+	out, err := json.Marshal(masked)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encode redacted json: %w", err)
+	}
+	return out, count, nil
+}
+
+// envValues returns the current values of the configured env vars, longest
+// first so overlapping values are masked whole.
+func (r *Ruleset) envValues() []string {
+	values := make([]string, 0, len(r.cfg.EnvVars))
+	for _, name := range r.cfg.EnvVars {
+		if value := os.Getenv(name); len(value) >= minEnvValueLength {
+			values = append(values, value)
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+// replace expands repl for each match, skipping matches that are already
+// masked so repeated passes report no new redactions.
+func replace(pattern *regexp.Regexp, data []byte, repl string, count int) ([]byte, int) {
+	matches := pattern.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		return data, count
+	}
+	var b bytes.Buffer
+	last := 0
+	for _, m := range matches {
+		expanded := pattern.Expand(nil, []byte(repl), data, m)
+		b.Write(data[last:m[0]])
+		b.Write(expanded)
+		if !bytes.Equal(expanded, data[m[0]:m[1]]) {
+			count++
+		}
+		last = m[1]
+	}
+	b.Write(data[last:])
+	return b.Bytes(), count
+}
@@ -70,6 +70,9 @@ func BuildGitHubSummaryFromJobpack(path string, opts SummaryOptions) (v1.GitHubS
 	finalSummary := latestCheckpointSummary(checkpoints)
 	artifactPointers := extractArtifactPointers(artifactsManifest)
 	markdown := renderMarkdown(job.JobID, job.Status, acceptResult, finalSummary, delta, artifactPointers)
+	if cost := renderCost(*job); cost != "" {
+		markdown += "\n\n" + cost
+	}
 	createdAt := summaryCreatedAt(archive.Manifest.CreatedAt, job.CreatedAt, now().UTC())
 
 	summary := v1.GitHubSummary{
@@ -314,6 +317,22 @@ func renderMarkdown(jobID, status string, acceptResult v1.AcceptanceResult, fina
 	return strings.TrimSpace(b.String())
 }
 
+// renderCost reports the estimated cost with the price table it came from,
+// so the number can be recomputed offline from the jobpack.
+func renderCost(job v1.JobRecord) string {
+	if job.PriceTable == nil {
+		return ""
+	}
+	cost, _ := job.Budgets["estimated_cost"].(float64)
+	return fmt.Sprintf(
+		"## Cost\n\n- Estimated: `%.6f %s` (price table `%s`, sha256 `%s`)",
+		cost,
+		job.PriceTable.Currency,
+		job.PriceTable.Version,
+		job.PriceTable.SHA256,
+	)
+}
+
 func canonicalJSON(v any) ([]byte, error) {
 	raw, err := json.Marshal(v)
 	if err != nil {
@@ -151,3 +151,38 @@ func setupReportJob(t *testing.T, jobID string, now time.Time) {
 		t.Fatalf("emit checkpoint 2: %v", err)
 	}
 }
+
+func TestSummaryReportsPricedCost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 2, 13, 22, 30, 0, 0, time.UTC)
+	setupReportJob(t, "job_report_cost", now)
+
+	s, err := store.New("")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := runner.New(s, runner.Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	ref := v1.PriceTableRef{Path: "/work/prices.yaml", SHA256: strings.Repeat("b", 64), Version: "2026-02-01", Currency: "USD"}
+	if _, err := r.RecordPriceTable("job_report_cost", ref); err != nil {
+		t.Fatalf("RecordPriceTable: %v", err)
+	}
+	if _, err := r.RecordUsage("job_report_cost", runner.Usage{Model: "gpt-test", TokensIn: 100, EstimatedCost: 0.0123}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+
+	exported, err := pack.ExportJobpack("job_report_cost", pack.ExportOptions{OutDir: t.TempDir(), ProducerVersion: "test", Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("export jobpack: %v", err)
+	}
+	summary, err := BuildGitHubSummaryFromJobpack(exported.Path, SummaryOptions{Now: func() time.Time { return now }, ProducerVersion: "test"})
+	if err != nil {
+		t.Fatalf("build summary: %v", err)
+	}
+	want := "- Estimated: `0.012300 USD` (price table `2026-02-01`, sha256 `" + ref.SHA256 + "`)"
+	if !strings.Contains(summary.Markdown, "## Cost") || !strings.Contains(summary.Markdown, want) {
+		t.Fatalf("expected priced cost section, got %s", summary.Markdown)
+	}
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/davidahmann/wrkr/core/approve"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+type submitterPayload struct {
+	SubmittedBy string `json:"submitted_by"`
+}
+
+type approvalRulesPayload struct {
+	Rules []policy.ApprovalRule `json:"rules"`
+}
+
+// RecordSubmitter records who submitted the job so approval rules can
+// forbid self-approval.
+func (r *Runner) RecordSubmitter(jobID, submittedBy string) (*State, error) {
+	submittedBy = strings.TrimSpace(submittedBy)
+	state, _, err := r.appendState(jobID, eventSubmitterRecorded, fixedPayload(submitterPayload{SubmittedBy: submittedBy}))
+	return state, err
+}
+
+// RecordApprovalRules records the resolved approval rules from the job
+// policy. Groups are already expanded so the event log shows the exact
+// allowlist that governed each decision.
+func (r *Runner) RecordApprovalRules(jobID string, rules []policy.ApprovalRule) (*State, error) {
+	state, _, err := r.appendState(jobID, eventApprovalRulesSet, fixedPayload(approvalRulesPayload{Rules: rules}))
+	return state, err
+}
+
+// approvalRule returns the rule governing a decision checkpoint, if any.
+func approvalRule(state *State, cp *v1.Checkpoint) (policy.ApprovalRule, bool) {
+	if state == nil || cp == nil || cp.RequiredAction == nil {
+		return policy.ApprovalRule{}, false
+	}
+	return policy.MatchApprovalRule(state.ApprovalRules, cp.RequiredAction.Kind)
+}
+
+// checkApprover enforces the rule allowlist and the self-approval ban.
+func checkApprover(state *State, rule policy.ApprovalRule, checkpointID, approvedBy string) error {
+	if approvedBy == approve.UnknownActor {
+		return wrkrerrors.New(
+			wrkrerrors.EPolicyDenied,
+			"approval rule requires an identified approver",
+			map[string]any{"checkpoint_id": checkpointID, "rule": rule.Name},
+		)
+	}
+	if rule.ForbidSelfApproval && state.SubmittedBy != "" && approvedBy == state.SubmittedBy {
+		return wrkrerrors.New(
+			wrkrerrors.EPolicyDenied,
+			"job submitter cannot approve their own job",
+			map[string]any{"checkpoint_id": checkpointID, "approved_by": approvedBy, "rule": rule.Name},
+		)
+	}
+	if len(rule.Approvers) > 0 && !slices.Contains(rule.Approvers, approvedBy) {
+		return wrkrerrors.New(
+			wrkrerrors.EPolicyDenied,
+			"approver is not allowed by approval rule",
+			map[string]any{"checkpoint_id": checkpointID, "approved_by": approvedBy, "rule": rule.Name},
+		)
+	}
+	return nil
+}
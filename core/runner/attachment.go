@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/sign"
+)
+
+const maxAttachments = 16
+
+// attachmentNamePattern accepts plain file names of up to 128 characters
+// that do not start with a dot.
+var attachmentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,127}$`)
+
+// MaxAttachmentBytes is the size limit of a single checkpoint attachment.
+const MaxAttachmentBytes = 1 << 20
+
+var attachmentMediaTypes = map[string]string{
+	".diff":  "text/x-diff",
+	".patch": "text/x-diff",
+	".json":  "application/json",
+	".log":   "text/plain",
+	".txt":   "text/plain",
+}
+
+// AttachmentInput is one file to store with a checkpoint. MediaType is
+// derived from the name and content when empty.
+type AttachmentInput struct {
+	Name      string
+	MediaType string
+	Data      []byte
+}
+
+// ReadAttachment returns the stored bytes of a checkpoint attachment,
+// failing if the blob no longer matches its recorded digest.
+func (r *Runner) ReadAttachment(jobID string, attachment v1.Attachment) ([]byte, error) {
+	data, err := r.store.ReadBlob(jobID, attachment.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if actual := sign.SHA256Hex(data); actual != attachment.SHA256 {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EVerifyHashMismatch,
+			"attachment hash mismatch",
+			map[string]any{"name": attachment.Name, "expected": attachment.SHA256, "actual": actual},
+		)
+	}
+	return data, nil
+}
+
+func validateAttachments(inputs []AttachmentInput) error {
+	if len(inputs) > maxAttachments {
+		return wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"too many checkpoint attachments",
+			map[string]any{"attachments": len(inputs), "max": maxAttachments},
+		)
+	}
+	seen := map[string]struct{}{}
+	for _, input := range inputs {
+		name := strings.TrimSpace(input.Name)
+		if !attachmentNamePattern.MatchString(name) {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "attachment name is invalid", map[string]any{"name": input.Name})
+		}
+		if _, ok := seen[name]; ok {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "attachment name is duplicated", map[string]any{"name": name})
+		}
+		seen[name] = struct{}{}
+		if len(input.Data) > MaxAttachmentBytes {
+			return wrkrerrors.New(
+				wrkrerrors.EInvalidInputSchema,
+				"attachment exceeds size limit",
+				map[string]any{"name": name, "size": len(input.Data), "max": MaxAttachmentBytes},
+			)
+		}
+	}
+	return nil
+}
+
+// storeAttachments masks secrets in attachments, text or binary, and stores
+// each one as a job blob.
+func (r *Runner) storeAttachments(jobID string, inputs []AttachmentInput) ([]v1.Attachment, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	rules, err := r.store.Redaction(jobID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]v1.Attachment, 0, len(inputs))
+	for _, input := range inputs {
+		name := strings.TrimSpace(input.Name)
+		data := input.Data
+		mediaType := strings.TrimSpace(input.MediaType)
+		if mediaType == "" {
+			mediaType = attachmentMediaType(name, data)
+		}
+		redacted := false
+		if masked, n := rules.Bytes(data); n > 0 {
+			data = masked
+			redacted = true
+		}
+		digest, err := r.store.PutBlob(jobID, data)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v1.Attachment{
+			Name:      name,
+			MediaType: mediaType,
+			SHA256:    digest,
+			Size:      int64(len(data)),
+			Redacted:  redacted,
+		})
+	}
+	return out, nil
+}
+
+func attachmentMediaType(name string, data []byte) string {
+	if mediaType, ok := attachmentMediaTypes[strings.ToLower(filepath.Ext(name))]; ok {
+		return mediaType
+	}
+	if utf8.Valid(data) {
+		return "text/plain"
+	}
+	return "application/octet-stream"
+}
+
+// IsTextAttachment reports whether an attachment can be rendered as text.
+func IsTextAttachment(attachment v1.Attachment) bool {
+	return strings.HasPrefix(attachment.MediaType, "text/") || attachment.MediaType == "application/json"
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// ReasonCheckpointSilence marks the synthetic checkpoint emitted when a
+// running job exceeds checkpoint_policy.min_interval_seconds without one.
+const ReasonCheckpointSilence = "checkpoint_interval_exceeded"
+
+// RecordCheckpointPolicy records the job spec checkpoint policy.
+func (r *Runner) RecordCheckpointPolicy(jobID string, policy v1.CheckpointPolicy) (*State, error) {
+	if policy.RequiredTypes == nil {
+		policy.RequiredTypes = []string{}
+	}
+	state, _, err := r.appendState(jobID, eventCheckpointPolicySet, fixedPayload(policy))
+	return state, err
+}
+
+// RecordAcceptance records the jobspec's expected artifacts and inline
+// acceptance block, the job's own defaults for `wrkr accept run`.
+func (r *Runner) RecordAcceptance(jobID string, spec v1.AcceptanceSpec) (*State, error) {
+	state, _, err := r.appendState(jobID, eventAcceptanceSpecSet, fixedPayload(spec))
+	return state, err
+}
+
+// CheckCheckpointInterval emits one warning checkpoint per silence period
+// when a running job has gone longer than the policy interval without a
+// checkpoint. It returns nil when the job is within its interval. The check
+// is repeated inside the append, so concurrent callers emit one warning.
+func (r *Runner) CheckCheckpointInterval(jobID string) (*v1.Checkpoint, error) {
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return nil, err
+	}
+	silent, due := r.checkpointSilence(state)
+	if !due {
+		return nil, nil
+	}
+	return r.emitCheckpoint(jobID, CheckpointInput{
+		Type: "progress",
+		Summary: fmt.Sprintf(
+			"no checkpoint for %ds (checkpoint_policy.min_interval_seconds=%d)",
+			int(silent.Seconds()), state.CheckpointPolicy.MinIntervalSeconds,
+		),
+		Status:      queue.StatusRunning,
+		ReasonCodes: []string{ReasonCheckpointSilence},
+	}, func(latest *State) bool {
+		_, due := r.checkpointSilence(latest)
+		return due
+	})
+}
+
+// checkpointSilence reports how long a running job has gone without a
+// checkpoint and whether that exceeds its policy interval.
+func (r *Runner) checkpointSilence(state *State) (time.Duration, bool) {
+	policy := state.CheckpointPolicy
+	if policy == nil || policy.MinIntervalSeconds <= 0 || state.Status != queue.StatusRunning || state.CheckpointSilent {
+		return 0, false
+	}
+	last := state.LastCheckpointAt
+	if last == nil {
+		last = state.StartedAt
+	}
+	if last == nil {
+		return 0, false
+	}
+	silent := r.now().UTC().Sub(last.UTC())
+	return silent, silent >= time.Duration(policy.MinIntervalSeconds)*time.Second
+}
+
+// noteCheckpoint tracks when the job last checkpointed. Silence warnings do
+// not reset the interval; they only suppress repeats until a real checkpoint.
+func noteCheckpoint(state *State, reasonCodes []string, at time.Time) {
+	if slices.Contains(reasonCodes, ReasonCheckpointSilence) {
+		state.CheckpointSilent = true
+		return
+	}
+	at = at.UTC()
+	state.LastCheckpointAt = &at
+	state.CheckpointSilent = false
+}
@@ -0,0 +1,278 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+var optionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// Rejection records a rejected decision-needed checkpoint. RouteStep names
+// the step the adapter continues from; without one the job is canceled.
+type Rejection struct {
+	CheckpointID string    `json:"checkpoint_id"`
+	Reason       string    `json:"reason"`
+	RejectedBy   string    `json:"rejected_by"`
+	RouteStep    string    `json:"route_step,omitempty"`
+	RejectedAt   time.Time `json:"rejected_at"`
+}
+
+// Decision is the resolution of a decision-needed checkpoint. Under an
+// approval rule the checkpoint is approved once Approvers reaches Quorum.
+type Decision struct {
+	Approved  bool
+	Option    string
+	Approvers []string
+	Quorum    int
+	Rule      string
+	Rejection *Rejection
+}
+
+// Resolved reports whether the checkpoint no longer blocks resume.
+func (d Decision) Resolved() bool {
+	return d.Approved || d.Rejection != nil
+}
+
+// DecideCheckpoint approves a decision-needed checkpoint by choosing one of
+// its options.
+func (r *Runner) DecideCheckpoint(jobID, checkpointID, option, reason, approvedBy string) (*v1.ApprovalRecord, error) {
+	cp, err := r.decisionCheckpoint(jobID, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	option = strings.TrimSpace(option)
+	var offered []v1.DecisionOption
+	if cp.RequiredAction != nil {
+		offered = cp.RequiredAction.Options
+	}
+	if !hasOption(offered, option) {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"option is not offered by checkpoint",
+			map[string]any{"checkpoint_id": checkpointID, "option": option, "options": optionNames(offered)},
+		)
+	}
+	return r.recordApproval(jobID, checkpointID, option, reason, approvedBy)
+}
+
+// RejectCheckpoint rejects a decision-needed checkpoint. Without a route step
+// the job is canceled; with one it stays blocked until resumed.
+func (r *Runner) RejectCheckpoint(jobID, checkpointID, reason, rejectedBy, routeStep string) (*Rejection, error) {
+	if _, err := r.decisionCheckpoint(jobID, checkpointID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "rejection reason is required", nil)
+	}
+	if strings.TrimSpace(rejectedBy) == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "rejected_by is required", nil)
+	}
+	decision, err := r.Decision(jobID, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if decision.Resolved() {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidStateTransition,
+			"checkpoint decision already recorded",
+			map[string]any{"job_id": jobID, "checkpoint_id": checkpointID},
+		)
+	}
+
+	rejection := Rejection{
+		CheckpointID: checkpointID,
+		Reason:       strings.TrimSpace(reason),
+		RejectedBy:   strings.TrimSpace(rejectedBy),
+		RouteStep:    strings.TrimSpace(routeStep),
+		RejectedAt:   r.now().UTC(),
+	}
+	if _, err := r.store.AppendEvent(jobID, eventDecisionRejected, rejection, r.now()); err != nil {
+		return nil, err
+	}
+	if rejection.RouteStep == "" {
+		if _, err := r.ChangeStatus(jobID, queue.StatusCanceled); err != nil {
+			return nil, err
+		}
+	}
+	return &rejection, nil
+}
+
+// Decision returns how a decision-needed checkpoint was resolved.
+func (r *Runner) Decision(jobID, checkpointID string) (Decision, error) {
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return Decision{}, err
+	}
+	cp, err := r.GetCheckpoint(jobID, checkpointID)
+	if err != nil {
+		return Decision{}, err
+	}
+	events, err := r.store.LoadEvents(jobID)
+	if err != nil {
+		return Decision{}, err
+	}
+	d := Decision{Quorum: 1}
+	if rule, ok := approvalRule(state, cp); ok {
+		d.Quorum = rule.Quorum
+		d.Rule = rule.Name
+	}
+	for _, event := range events {
+		switch event.Type {
+		case eventApprovalRecorded:
+			var rec v1.ApprovalRecord
+			if err := json.Unmarshal(event.Payload, &rec); err != nil {
+				return Decision{}, fmt.Errorf("decode approval payload: %w", err)
+			}
+			if rec.CheckpointID == checkpointID && !slices.Contains(d.Approvers, rec.ApprovedBy) {
+				d.Approvers = append(d.Approvers, rec.ApprovedBy)
+				d.Option = rec.Option
+			}
+		case eventDecisionRejected:
+			var rej Rejection
+			if err := json.Unmarshal(event.Payload, &rej); err != nil {
+				return Decision{}, fmt.Errorf("decode rejection payload: %w", err)
+			}
+			if rej.CheckpointID == checkpointID {
+				d.Rejection = &rej
+			}
+		}
+	}
+	d.Approved = len(d.Approvers) >= d.Quorum
+	return d, nil
+}
+
+func (r *Runner) recordApproval(jobID, checkpointID, option, reason, approvedBy string) (*v1.ApprovalRecord, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval reason is required", nil)
+	}
+	if strings.TrimSpace(approvedBy) == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approved_by is required", nil)
+	}
+	cp, err := r.GetCheckpoint(jobID, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if decisionExpired(cp, r.now()) {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidStateTransition,
+			"approval deadline has passed",
+			map[string]any{"job_id": jobID, "checkpoint_id": checkpointID, "deadline": cp.RequiredAction.Deadline.UTC()},
+		)
+	}
+	decision, err := r.Decision(jobID, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if decision.Rejection != nil || (len(decision.Approvers) > 0 && decision.Option != option) {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidStateTransition,
+			"checkpoint decision already recorded",
+			map[string]any{"job_id": jobID, "checkpoint_id": checkpointID},
+		)
+	}
+	approvedBy = strings.TrimSpace(approvedBy)
+	approvals := len(decision.Approvers)
+	if decision.Rule != "" {
+		state, err := r.Recover(jobID)
+		if err != nil {
+			return nil, err
+		}
+		rule, _ := approvalRule(state, cp)
+		if err := checkApprover(state, rule, checkpointID, approvedBy); err != nil {
+			return nil, err
+		}
+		if slices.Contains(decision.Approvers, approvedBy) {
+			return nil, wrkrerrors.New(
+				wrkrerrors.EInvalidStateTransition,
+				"approver already approved checkpoint",
+				map[string]any{"checkpoint_id": checkpointID, "approved_by": approvedBy, "rule": rule.Name},
+			)
+		}
+		approvals++
+	}
+
+	record := v1.ApprovalRecord{
+		Envelope: v1.Envelope{
+			SchemaID:        "wrkr.approval_record",
+			SchemaVersion:   "v1",
+			CreatedAt:       r.now().UTC(),
+			ProducerVersion: "dev",
+		},
+		JobID:        jobID,
+		CheckpointID: checkpointID,
+		Reason:       strings.TrimSpace(reason),
+		ApprovedBy:   approvedBy,
+		Option:       option,
+	}
+	if decision.Rule != "" {
+		record.Rule = decision.Rule
+		record.Quorum = decision.Quorum
+		record.Approvals = approvals
+		record.QuorumMet = approvals >= decision.Quorum
+	}
+
+	if _, _, err := r.appendState(jobID, eventApprovalRecorded, fixedPayload(record)); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *Runner) decisionCheckpoint(jobID, checkpointID string) (*v1.Checkpoint, error) {
+	cp, err := r.GetCheckpoint(jobID, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if cp.Type != "decision-needed" {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"only decision-needed checkpoints can be approved",
+			map[string]any{"checkpoint_id": checkpointID, "type": cp.Type},
+		)
+	}
+	return cp, nil
+}
+
+func validateOptions(cpType string, action *v1.RequiredAction) error {
+	if action == nil || len(action.Options) == 0 {
+		return nil
+	}
+	if cpType != "decision-needed" {
+		return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "only decision-needed checkpoints can offer options", map[string]any{"type": cpType})
+	}
+	seen := map[string]struct{}{}
+	for _, opt := range action.Options {
+		if !optionNamePattern.MatchString(opt.Name) {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "decision option name is invalid", map[string]any{"option": opt.Name})
+		}
+		if _, ok := seen[opt.Name]; ok {
+			return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "decision option name is duplicated", map[string]any{"option": opt.Name})
+		}
+		seen[opt.Name] = struct{}{}
+	}
+	return nil
+}
+
+func hasOption(options []v1.DecisionOption, name string) bool {
+	for _, opt := range options {
+		if opt.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func optionNames(options []v1.DecisionOption) []string {
+	out := make([]string, 0, len(options))
+	for _, opt := range options {
+		out = append(out, opt.Name)
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// Reason codes on checkpoints emitted when a decision passes its deadline.
+const (
+	ReasonApprovalExpired   = "approval_expired"
+	ReasonApprovalEscalated = "approval_escalated"
+)
+
+// ExpireDecision acts on the job's latest decision-needed checkpoint once its
+// required_action.deadline has passed without a decision. It cancels the job
+// when cancel_on_expiry is set; otherwise it emits an escalation checkpoint
+// that carries the same required action, without a deadline, for the
+// escalation target. It returns nil when nothing expired.
+func (r *Runner) ExpireDecision(jobID string) (*v1.Checkpoint, error) {
+	cp, _, err := r.PendingDecision(jobID)
+	if err != nil || cp == nil || !decisionExpired(cp, r.now()) {
+		return nil, err
+	}
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return nil, err
+	}
+	checkpointID := cp.CheckpointID
+
+	action := *cp.RequiredAction
+	expiredAt := action.Deadline.UTC().Format(time.RFC3339)
+	if action.CancelOnExpiry {
+		blocked, err := r.EmitCheckpoint(jobID, CheckpointInput{
+			Type:        "blocked",
+			Summary:     fmt.Sprintf("decision %s expired at %s; job canceled", checkpointID, expiredAt),
+			Status:      queue.StatusCanceled,
+			BudgetState: budgetUsageFromState(state, r.now()),
+			ReasonCodes: []string{ReasonApprovalExpired},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.ChangeStatus(jobID, queue.StatusCanceled); err != nil {
+			return nil, err
+		}
+		return blocked, nil
+	}
+
+	target := action.EscalateTo
+	if target == "" {
+		target = "unassigned"
+	}
+	action.Deadline = nil
+	action.CancelOnExpiry = false
+	action.Options = append([]v1.DecisionOption(nil), action.Options...)
+	return r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:           "decision-needed",
+		Summary:        fmt.Sprintf("decision %s expired at %s; escalated to %s", checkpointID, expiredAt, target),
+		Status:         queue.StatusBlockedDecision,
+		BudgetState:    budgetUsageFromState(state, r.now()),
+		RequiredAction: &action,
+		ReasonCodes:    []string{ReasonApprovalExpired, ReasonApprovalEscalated},
+	})
+}
+
+// PendingDecision returns the unresolved decision-needed checkpoint a
+// blocked_decision job is waiting on, or nil when there is none.
+func (r *Runner) PendingDecision(jobID string) (*v1.Checkpoint, *Decision, error) {
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.Status != queue.StatusBlockedDecision {
+		return nil, nil, nil
+	}
+	checkpointID, err := r.latestDecisionCheckpoint(jobID)
+	if err != nil || checkpointID == "" {
+		return nil, nil, err
+	}
+	decision, err := r.Decision(jobID, checkpointID)
+	if err != nil || decision.Resolved() {
+		return nil, nil, err
+	}
+	cp, err := r.GetCheckpoint(jobID, checkpointID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cp, &decision, nil
+}
+
+func validateExpiry(cpType string, action *v1.RequiredAction) error {
+	if action == nil || (action.Deadline == nil && action.EscalateTo == "" && !action.CancelOnExpiry) {
+		return nil
+	}
+	if cpType != "decision-needed" {
+		return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "only decision-needed checkpoints can set a deadline or escalation", map[string]any{"type": cpType})
+	}
+	if action.CancelOnExpiry && action.Deadline == nil {
+		return wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "cancel_on_expiry requires a deadline", nil)
+	}
+	return nil
+}
+
+func decisionExpired(cp *v1.Checkpoint, now time.Time) bool {
+	if cp == nil || cp.RequiredAction == nil || cp.RequiredAction.Deadline == nil {
+		return false
+	}
+	return !now.UTC().Before(cp.RequiredAction.Deadline.UTC())
+}
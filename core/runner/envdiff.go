@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/davidahmann/wrkr/core/envfp"
+)
+
+// ReasonEnvFingerprintAdvisory marks the progress checkpoint emitted on resume
+// when only advisory fingerprint rules changed.
+const ReasonEnvFingerprintAdvisory = "env_fingerprint_advisory"
+
+// maxEnvValueLength bounds each value quoted in a mismatch summary; the full
+// values are in the attached env_diff.json.
+const maxEnvValueLength = 80
+
+// EnvDiff compares a job's recorded environment fingerprint with the current
+// environment. Blocking is what Resume would act on.
+type EnvDiff struct {
+	JobID        string         `json:"job_id"`
+	ExpectedHash string         `json:"expected_hash"`
+	ActualHash   string         `json:"actual_hash"`
+	Match        bool           `json:"match"`
+	Blocking     bool           `json:"blocking"`
+	Changes      []envfp.Change `json:"changes"`
+}
+
+// EnvDiff captures the current fingerprint with the job's rules and lists the
+// rules that changed. It records nothing.
+func (r *Runner) EnvDiff(jobID string) (*EnvDiff, error) {
+	state, err := r.Recover(jobID)
+	if err != nil {
+		return nil, err
+	}
+	diff, _, err := r.envDiff(state)
+	return diff, err
+}
+
+func (r *Runner) envDiff(state *State) (*EnvDiff, envfp.Fingerprint, error) {
+	rules := state.EnvFingerprintRules
+	if len(rules) == 0 {
+		rules = envfp.DefaultRules()
+	}
+	current, err := envfp.CaptureWithAdvisory(rules, state.EnvFingerprintAdvisory, r.now())
+	if err != nil {
+		return nil, envfp.Fingerprint{}, err
+	}
+	recorded := envfp.Fingerprint{
+		Rules:    state.EnvFingerprintRules,
+		Values:   state.EnvFingerprintValues,
+		Digests:  state.EnvFingerprintDigests,
+		Advisory: state.EnvFingerprintAdvisory,
+		Hash:     state.EnvFingerprintHash,
+	}
+
+	changes := []envfp.Change{}
+	if recorded.Hash != "" && recorded.Hash != current.Hash {
+		changes = envfp.Diff(recorded, current)
+	}
+	// Current values have not been through the store, so mask them the way
+	// the recorded ones were.
+	masks, err := r.store.Redaction(state.JobID)
+	if err != nil {
+		return nil, envfp.Fingerprint{}, err
+	}
+	for i := range changes {
+		changes[i].Expected, _ = masks.String(changes[i].Expected)
+		changes[i].Actual, _ = masks.String(changes[i].Actual)
+	}
+
+	return &EnvDiff{
+		JobID:        state.JobID,
+		ExpectedHash: recorded.Hash,
+		ActualHash:   current.Hash,
+		Match:        recorded.Hash == "" || recorded.Hash == current.Hash,
+		Blocking:     envfp.Blocking(recorded, current, changes),
+		Changes:      changes,
+	}, current, nil
+}
+
+// envChangesSummary appends each changed rule with its old and new values to
+// prefix, bounded to the checkpoint summary limit.
+func envChangesSummary(prefix string, changes []envfp.Change) string {
+	parts := make([]string, 0, len(changes))
+	for _, change := range changes {
+		part := fmt.Sprintf("%s %q -> %q", change.Rule, boundEnvValue(change.Expected), boundEnvValue(change.Actual))
+		if change.Advisory {
+			part += " (advisory)"
+		}
+		parts = append(parts, part)
+	}
+	summary := prefix
+	if len(parts) > 0 {
+		summary += ": " + strings.Join(parts, "; ")
+	}
+	return truncateRunes(summary, maxSummaryLength)
+}
+
+func boundEnvValue(value string) string {
+	return truncateRunes(value, maxEnvValueLength)
+}
+
+// truncateRunes cuts s to at most limit bytes on a rune boundary, marking the
+// cut with "...".
+func truncateRunes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit - 3
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+func setEnvFingerprint(state *State, fp envfp.Fingerprint) {
+	state.EnvFingerprintHash = fp.Hash
+	state.EnvFingerprintRules = append([]string(nil), fp.Rules...)
+	state.EnvFingerprintValues = make(map[string]string, len(fp.Values))
+	for k, v := range fp.Values {
+		state.EnvFingerprintValues[k] = v
+	}
+	state.EnvFingerprintDigests = nil
+	if len(fp.Digests) > 0 {
+		state.EnvFingerprintDigests = make(map[string]string, len(fp.Digests))
+		for k, v := range fp.Digests {
+			state.EnvFingerprintDigests[k] = v
+		}
+	}
+	state.EnvFingerprintAdvisory = append([]string(nil), fp.Advisory...)
+}
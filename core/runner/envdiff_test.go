@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"errors"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/envfp"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+)
+
+func pausedEnvJob(t *testing.T, r *Runner, jobID string, values map[string]string, advisory []string) {
+	t.Helper()
+	if _, err := r.InitJobWithEnvFingerprint(jobID, []string{"os", "arch"}, advisory); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("running: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusPaused); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if _, err := r.store.AppendEvent(jobID, eventEnvFingerprintSet, map[string]any{
+		"rules":       []string{"arch", "os"},
+		"values":      values,
+		"advisory":    advisory,
+		"hash":        "deadbeef",
+		"captured_at": r.now().UTC(),
+	}, r.now()); err != nil {
+		t.Fatalf("inject fingerprint: %v", err)
+	}
+}
+
+func TestResumeMismatchListsChangedRules(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 14, 0, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	pausedEnvJob(t, r, "job_env_diff", map[string]string{"os": runtime.GOOS, "arch": "bogus-arch"}, nil)
+
+	diff, err := r.EnvDiff("job_env_diff")
+	if err != nil {
+		t.Fatalf("env diff: %v", err)
+	}
+	want := []envfp.Change{{Rule: "arch", Expected: "bogus-arch", Actual: runtime.GOARCH}}
+	if diff.Match || !diff.Blocking || !slices.Equal(diff.Changes, want) {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+
+	_, err = r.Resume("job_env_diff", ResumeInput{})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EEnvFingerprintMismatch {
+		t.Fatalf("expected E_ENV_FINGERPRINT_MISMATCH, got %v", err)
+	}
+	changes, ok := werr.Details["changes"].([]envfp.Change)
+	if !ok || !slices.Equal(changes, want) {
+		t.Fatalf("expected changes in error details, got %#v", werr.Details["changes"])
+	}
+
+	checkpoints, err := r.ListCheckpoints("job_env_diff")
+	if err != nil {
+		t.Fatalf("list checkpoints: %v", err)
+	}
+	blocked := checkpoints[len(checkpoints)-1]
+	if blocked.Type != "blocked" || !strings.Contains(blocked.Summary, `arch "bogus-arch" -> "`+runtime.GOARCH+`"`) {
+		t.Fatalf("expected blocked checkpoint naming the rule, got %+v", blocked)
+	}
+	if len(blocked.Attachments) != 1 || blocked.Attachments[0].Name != "env_diff.json" {
+		t.Fatalf("expected env_diff.json attachment, got %+v", blocked.Attachments)
+	}
+}
+
+func TestResumeReportsAdvisoryDriftWithoutBlocking(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 14, 0, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	pausedEnvJob(t, r, "job_env_advisory", map[string]string{"os": "bogus-os", "arch": runtime.GOARCH}, []string{"os"})
+
+	diff, err := r.EnvDiff("job_env_advisory")
+	if err != nil {
+		t.Fatalf("env diff: %v", err)
+	}
+	if diff.Match || diff.Blocking || len(diff.Changes) != 1 || !diff.Changes[0].Advisory {
+		t.Fatalf("expected advisory-only diff, got %+v", diff)
+	}
+
+	state, err := r.Resume("job_env_advisory", ResumeInput{})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if state.Status != queue.StatusRunning {
+		t.Fatalf("expected running, got %s", state.Status)
+	}
+	checkpoints, err := r.ListCheckpoints("job_env_advisory")
+	if err != nil {
+		t.Fatalf("list checkpoints: %v", err)
+	}
+	found := false
+	for _, cp := range checkpoints {
+		if slices.Contains(cp.ReasonCodes, ReasonEnvFingerprintAdvisory) {
+			found = strings.Contains(cp.Summary, "os") && cp.Type == "progress"
+		}
+	}
+	if !found {
+		t.Fatalf("expected advisory progress checkpoint, got %+v", checkpoints)
+	}
+
+	diff, err = r.EnvDiff("job_env_advisory")
+	if err != nil {
+		t.Fatalf("env diff after resume: %v", err)
+	}
+	if !diff.Match || len(diff.Changes) != 0 {
+		t.Fatalf("expected advisory values adopted, got %+v", diff)
+	}
+}
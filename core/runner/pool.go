@@ -0,0 +1,200 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/wrkr/core/budget"
+	"github.com/davidahmann/wrkr/core/budgetpool"
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+	"github.com/davidahmann/wrkr/core/store"
+)
+
+// RecordBudgetPool records the shared budget pool the job draws from.
+func (r *Runner) RecordBudgetPool(jobID, pool string) (*State, error) {
+	state, _, err := r.appendState(jobID, eventBudgetPoolJoined, fixedPayload(map[string]string{"pool": pool}))
+	return state, err
+}
+
+// poolIndex caches per-job usage for one pool window. Events files are
+// append-only, so each PoolUsage call reads only the lines appended since the
+// previous call instead of every job's full log. A new window starts a fresh
+// index.
+type poolIndex struct {
+	start time.Time
+	jobs  map[string]*poolJobUsage
+}
+
+// poolJobUsage is one job's pool membership and usage within the window, as
+// of offset in its events file. Wall time accrues only while the job is
+// running: ran holds finished running intervals clipped to the window,
+// and runningSince is set while an interval is open.
+type poolJobUsage struct {
+	offset       int64
+	pool         string
+	runningSince time.Time
+	ran          time.Duration
+	tokens       int
+	cost         float64
+}
+
+// PoolUsage aggregates usage of every job in the pool within the current
+// window: recorded tokens and cost by event time, and the time each job spent
+// running inside the window.
+func (r *Runner) PoolUsage(pool budgetpool.Pool) (budgetpool.Usage, error) {
+	now := r.now().UTC()
+	start, end := pool.WindowBounds(now)
+	usage := budgetpool.Usage{WindowStart: start, WindowEnd: end, Jobs: []string{}}
+
+	r.poolMu.Lock()
+	defer r.poolMu.Unlock()
+	idx := r.pools[pool.Name]
+	if idx == nil || !idx.start.Equal(start) {
+		idx = &poolIndex{start: start, jobs: map[string]*poolJobUsage{}}
+		r.pools[pool.Name] = idx
+	}
+
+	jobIDs, err := r.store.ListJobs()
+	if err != nil {
+		return budgetpool.Usage{}, err
+	}
+	listed := make(map[string]struct{}, len(jobIDs))
+	for _, jobID := range jobIDs {
+		listed[jobID] = struct{}{}
+		job := idx.jobs[jobID]
+		if job == nil {
+			job = &poolJobUsage{}
+			idx.jobs[jobID] = job
+		}
+		if err := r.scanPoolJob(jobID, job, start); err != nil {
+			return budgetpool.Usage{}, err
+		}
+		if job.pool != pool.Name {
+			continue
+		}
+		usage.Jobs = append(usage.Jobs, jobID)
+		usage.Tokens += job.tokens
+		usage.EstimatedCost += job.cost
+
+		ran := job.ran
+		if !job.runningSince.IsZero() {
+			ran += windowOverlap(job.runningSince, now, start)
+		}
+		usage.WallTimeSeconds += int(ran.Seconds())
+	}
+	for jobID := range idx.jobs {
+		if _, ok := listed[jobID]; !ok {
+			delete(idx.jobs, jobID)
+		}
+	}
+	return usage, nil
+}
+
+// scanPoolJob folds the events appended to jobID since job.offset into job.
+// Usage recorded before the window start is not counted.
+func (r *Runner) scanPoolJob(jobID string, job *poolJobUsage, start time.Time) error {
+	events, next, err := r.store.LoadEventsFrom(jobID, job.offset)
+	if errors.Is(err, store.ErrEventsRewound) {
+		*job = poolJobUsage{}
+		events, next, err = r.store.LoadEventsFrom(jobID, 0)
+	}
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		switch event.Type {
+		case eventBudgetPoolJoined:
+			var payload struct {
+				Pool string `json:"pool"`
+			}
+			if err := json.Unmarshal(event.Payload, &payload); err != nil {
+				return fmt.Errorf("decode budget pool payload: %w", err)
+			}
+			job.pool = payload.Pool
+		case eventStatusChanged:
+			var payload struct {
+				To queue.Status `json:"to"`
+			}
+			if err := json.Unmarshal(event.Payload, &payload); err != nil {
+				return fmt.Errorf("decode status_changed payload: %w", err)
+			}
+			switch {
+			case payload.To == queue.StatusRunning && job.runningSince.IsZero():
+				job.runningSince = event.CreatedAt
+			case payload.To != queue.StatusRunning && !job.runningSince.IsZero():
+				job.ran += windowOverlap(job.runningSince, event.CreatedAt, start)
+				job.runningSince = time.Time{}
+			}
+		case eventUsageRecorded:
+			if event.CreatedAt.Before(start) {
+				continue
+			}
+			var u Usage
+			if err := json.Unmarshal(event.Payload, &u); err != nil {
+				return fmt.Errorf("decode usage payload: %w", err)
+			}
+			job.tokens += u.TokensIn + u.TokensOut
+			job.cost += u.EstimatedCost
+		}
+	}
+	job.offset = next
+	return nil
+}
+
+// windowOverlap is the part of [from, to) at or after the window start.
+func windowOverlap(from, to, start time.Time) time.Duration {
+	if from.Before(start) {
+		from = start
+	}
+	if d := to.Sub(from); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// checkPool blocks the job when its budget pool has no headroom left.
+func (r *Runner) checkPool(state *State, name string) (*v1.Checkpoint, error) {
+	cfg, err := budgetpool.Load(r.store.Root())
+	if err != nil {
+		return nil, err
+	}
+	pool, err := cfg.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := r.PoolUsage(pool)
+	if err != nil {
+		return nil, err
+	}
+	headroom := budgetpool.Evaluate(pool, usage)
+	if !headroom.Exhausted {
+		return nil, nil
+	}
+
+	jobID := state.JobID
+	if state.Status != queue.StatusBlockedBudget {
+		if _, err := r.ChangeStatus(jobID, queue.StatusBlockedBudget); err != nil {
+			return nil, err
+		}
+	}
+	cp, err := r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:        "blocked",
+		Summary:     "budget pool exhausted: " + strings.Join(headroom.Violations, ", "),
+		Status:      queue.StatusBlockedBudget,
+		BudgetState: budgetUsageFromState(state, r.now()),
+		ReasonCodes: []string{budget.ReasonPoolExhausted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cp, wrkrerrors.New(
+		wrkrerrors.EBudgetExceeded,
+		"job stopped because its budget pool is exhausted",
+		map[string]any{"job_id": jobID, "pool": name, "violations": headroom.Violations, "window_end": usage.WindowEnd},
+	)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/davidahmann/wrkr/core/budget"
@@ -13,6 +14,7 @@ import (
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 	"github.com/davidahmann/wrkr/core/fsx"
 	"github.com/davidahmann/wrkr/core/lease"
+	"github.com/davidahmann/wrkr/core/policy"
 	"github.com/davidahmann/wrkr/core/queue"
 	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
@@ -30,26 +32,68 @@ const (
 	eventAdapterStep         = "adapter_step"
 	eventEnvFingerprintSet   = "env_fingerprint_set"
 	eventEnvOverrideRecorded = "env_override_recorded"
+	eventArtifactsCaptured   = "artifacts_captured"
+	eventUsageRecorded       = "usage_recorded"
+	eventSandboxApplied      = "sandbox_applied"
+	eventPolicyLoaded        = "policy_loaded"
+	eventBudgetWarning       = "budget_warning"
+	eventBudgetAmended       = "budget_amended"
+	eventBudgetPoolJoined    = "budget_pool_joined"
+	eventPriceTableLoaded    = "price_table_loaded"
+	eventCheckpointPolicySet = "checkpoint_policy_set"
+	eventDecisionRejected    = "decision_rejected"
+	eventSubmitterRecorded   = "submitter_recorded"
+	eventApprovalRulesSet    = "approval_rules_set"
+	eventAcceptanceSpecSet   = "acceptance_spec_set"
 	maxCASAttempts           = 64
 	maxSummaryLength         = 2000
 )
 
 type State struct {
-	JobID                string            `json:"job_id"`
-	Status               queue.Status      `json:"status"`
-	RetryCount           int               `json:"retry_count"`
-	StepCount            int               `json:"step_count"`
-	ToolCallCount        int               `json:"tool_call_count"`
-	IdempotencyKeys      map[string]bool   `json:"idempotency_keys"`
-	Lease                *lease.Record     `json:"lease,omitempty"`
-	LastAppliedSeq       int64             `json:"last_applied_seq"`
-	StartedAt            *time.Time        `json:"started_at,omitempty"`
-	LastReasonCodes      []string          `json:"last_reason_codes,omitempty"`
-	EnvFingerprintHash   string            `json:"env_fingerprint_hash,omitempty"`
-	EnvFingerprintRules  []string          `json:"env_fingerprint_rules,omitempty"`
-	EnvFingerprintValues map[string]string `json:"env_fingerprint_values,omitempty"`
+	JobID                  string                `json:"job_id"`
+	Status                 queue.Status          `json:"status"`
+	RetryCount             int                   `json:"retry_count"`
+	StepCount              int                   `json:"step_count"`
+	ToolCallCount          int                   `json:"tool_call_count"`
+	IdempotencyKeys        map[string]bool       `json:"idempotency_keys"`
+	IdempotencyPhases      map[string]string     `json:"idempotency_phases,omitempty"`
+	Lease                  *lease.Record         `json:"lease,omitempty"`
+	LastAppliedSeq         int64                 `json:"last_applied_seq"`
+	StartedAt              *time.Time            `json:"started_at,omitempty"`
+	LastReasonCodes        []string              `json:"last_reason_codes,omitempty"`
+	EnvFingerprintHash     string                `json:"env_fingerprint_hash,omitempty"`
+	EnvFingerprintRules    []string              `json:"env_fingerprint_rules,omitempty"`
+	EnvFingerprintValues   map[string]string     `json:"env_fingerprint_values,omitempty"`
+	EnvFingerprintDigests  map[string]string     `json:"env_fingerprint_digests,omitempty"`
+	EnvFingerprintAdvisory []string              `json:"env_fingerprint_advisory,omitempty"`
+	TokensIn               int                   `json:"tokens_in,omitempty"`
+	TokensOut              int                   `json:"tokens_out,omitempty"`
+	EstimatedCost          float64               `json:"estimated_cost,omitempty"`
+	Policy                 *v1.PolicyRef         `json:"policy,omitempty"`
+	BudgetWarnings         []string              `json:"budget_warnings,omitempty"`
+	BudgetAmendments       []v1.BudgetAmendment  `json:"budget_amendments,omitempty"`
+	BudgetPool             string                `json:"budget_pool,omitempty"`
+	PriceTable             *v1.PriceTableRef     `json:"price_table,omitempty"`
+	CheckpointPolicy       *v1.CheckpointPolicy  `json:"checkpoint_policy,omitempty"`
+	LastCheckpointAt       *time.Time            `json:"last_checkpoint_at,omitempty"`
+	CheckpointSilent       bool                  `json:"checkpoint_silent,omitempty"`
+	SubmittedBy            string                `json:"submitted_by,omitempty"`
+	ApprovalRules          []policy.ApprovalRule `json:"approval_rules,omitempty"`
+	Acceptance             *v1.AcceptanceSpec    `json:"acceptance,omitempty"`
 }
 
+// Idempotency phases recorded for a key. Only committed keys are reported in
+// State.IdempotencyKeys; a started key without a later phase marks a step that
+// may have applied side effects before a crash. A superseded key was committed
+// by a step that a rejection routed back to, so the step runs again.
+const (
+	IdempotencyStarted    = "started"
+	IdempotencyCommitted  = "committed"
+	IdempotencyFailed     = "failed"
+	IdempotencyReview     = "review"
+	IdempotencySuperseded = "superseded"
+)
+
 type Options struct {
 	Now      func() time.Time
 	LeaseTTL time.Duration
@@ -59,6 +103,9 @@ type Runner struct {
 	store    *store.LocalStore
 	now      func() time.Time
 	leaseTTL time.Duration
+
+	poolMu sync.Mutex
+	pools  map[string]*poolIndex
 }
 
 type CheckpointInput struct {
@@ -69,6 +116,22 @@ type CheckpointInput struct {
 	ArtifactsDelta v1.ArtifactsDelta
 	RequiredAction *v1.RequiredAction
 	ReasonCodes    []string
+	Attachments    []AttachmentInput
+}
+
+type ArtifactCapture struct {
+	StepID    string              `json:"step_id"`
+	Artifacts []v1.ArtifactRecord `json:"artifacts"`
+	Missing   []string            `json:"missing"`
+	Stored    bool                `json:"stored"`
+}
+
+// Usage is one model call's token and cost report, folded into State totals.
+type Usage struct {
+	Model         string  `json:"model,omitempty"`
+	TokensIn      int     `json:"tokens_in"`
+	TokensOut     int     `json:"tokens_out"`
+	EstimatedCost float64 `json:"estimated_cost"`
 }
 
 type ResumeInput struct {
@@ -94,7 +157,7 @@ func New(s *store.LocalStore, opts Options) (*Runner, error) {
 		leaseTTL = 30 * time.Second
 	}
 
-	return &Runner{store: s, now: now, leaseTTL: leaseTTL}, nil
+	return &Runner{store: s, now: now, leaseTTL: leaseTTL, pools: map[string]*poolIndex{}}, nil
 }
 
 func defaultState(jobID string) State {
@@ -110,37 +173,28 @@ func (r *Runner) InitJob(jobID string) (*State, error) {
 }
 
 func (r *Runner) InitJobWithEnvRules(jobID string, envRules []string) (*State, error) {
-	state := defaultState(jobID)
+	return r.InitJobWithEnvFingerprint(jobID, envRules, nil)
+}
+
+// InitJobWithEnvFingerprint initializes a job whose fingerprint also captures
+// advisory rules, which are reported on resume but never block it.
+func (r *Runner) InitJobWithEnvFingerprint(jobID string, envRules, advisory []string) (*State, error) {
 	startedAt := r.now().UTC()
-	event, err := r.store.AppendEvent(
+	if _, err := r.store.AppendEvent(
 		jobID,
 		eventJobInitialized,
-		map[string]any{"status": state.Status, "started_at": startedAt},
+		map[string]any{"status": queue.StatusQueued, "started_at": startedAt},
 		startedAt,
-	)
-	if err != nil {
+	); err != nil {
 		return nil, err
 	}
-	state.LastAppliedSeq = event.Seq
-	state.StartedAt = &startedAt
 
-	fp, err := envfp.Capture(envRules, startedAt)
-	if err != nil {
-		return nil, err
-	}
-	event, err = r.store.AppendEvent(jobID, eventEnvFingerprintSet, fp, r.now())
+	fp, err := envfp.CaptureWithAdvisory(envRules, advisory, startedAt)
 	if err != nil {
 		return nil, err
 	}
-	state.EnvFingerprintHash = fp.Hash
-	state.EnvFingerprintRules = fp.Rules
-	state.EnvFingerprintValues = fp.Values
-	state.LastAppliedSeq = event.Seq
-
-	if err := r.store.SaveSnapshot(jobID, state.LastAppliedSeq, state, r.now()); err != nil {
-		return nil, err
-	}
-	return &state, nil
+	state, _, err := r.appendState(jobID, eventEnvFingerprintSet, fixedPayload(fp))
+	return state, err
 }
 
 func (r *Runner) Recover(jobID string) (*State, error) {
@@ -182,6 +236,52 @@ func (r *Runner) Recover(jobID string) (*State, error) {
 	return &state, nil
 }
 
+// appendState appends one event with CAS against freshly recovered state,
+// retrying on contention, and saves the snapshot with the event applied.
+// payload builds the event from each attempt's state and may reject it.
+// Every writer that saves a snapshot goes through here, so a snapshot never
+// claims a sequence whose events it has not applied.
+func (r *Runner) appendState(jobID, eventType string, payload func(*State) (any, error)) (*State, store.Event, error) {
+	for attempt := 0; attempt < maxCASAttempts; attempt++ {
+		state, err := r.Recover(jobID)
+		if err != nil {
+			return nil, store.Event{}, err
+		}
+		body, err := payload(state)
+		if err != nil {
+			return nil, store.Event{}, err
+		}
+
+		event, err := r.store.AppendEventCAS(jobID, eventType, body, state.LastAppliedSeq, r.now())
+		if err != nil {
+			if errors.Is(err, store.ErrCASConflict) || errors.Is(err, fsx.ErrLockBusy) {
+				time.Sleep(1 * time.Millisecond)
+				continue
+			}
+			return nil, store.Event{}, err
+		}
+		if err := applyEvent(state, event); err != nil {
+			return nil, store.Event{}, err
+		}
+		state.LastAppliedSeq = event.Seq
+		if err := r.store.SaveSnapshot(jobID, state.LastAppliedSeq, state, r.now()); err != nil {
+			return nil, store.Event{}, err
+		}
+		return state, event, nil
+	}
+
+	return nil, store.Event{}, wrkrerrors.New(
+		wrkrerrors.EStoreCorrupt,
+		"event append contention exceeded retry budget",
+		map[string]any{"job_id": jobID, "event_type": eventType},
+	)
+}
+
+// fixedPayload is an appendState payload that does not depend on state.
+func fixedPayload(v any) func(*State) (any, error) {
+	return func(*State) (any, error) { return v, nil }
+}
+
 func (r *Runner) ChangeStatus(jobID string, to queue.Status) (*State, error) {
 	for attempt := 0; attempt < maxCASAttempts; attempt++ {
 		state, err := r.Recover(jobID)
@@ -223,51 +323,57 @@ func (r *Runner) ChangeStatus(jobID string, to queue.Status) (*State, error) {
 }
 
 func (r *Runner) UpdateCounters(jobID string, retryCount, stepCount, toolCallCount int) (*State, error) {
-	state, err := r.Recover(jobID)
-	if err != nil {
-		return nil, err
-	}
-
-	event, err := r.store.AppendEvent(
-		jobID,
-		eventCountersUpdated,
-		map[string]any{
-			"retry_count":     retryCount,
-			"step_count":      stepCount,
-			"tool_call_count": toolCallCount,
-		},
-		r.now(),
-	)
-	if err != nil {
-		return nil, err
-	}
+	state, _, err := r.appendState(jobID, eventCountersUpdated, fixedPayload(map[string]any{
+		"retry_count":     retryCount,
+		"step_count":      stepCount,
+		"tool_call_count": toolCallCount,
+	}))
+	return state, err
+}
 
-	state.RetryCount = retryCount
-	state.StepCount = stepCount
-	state.ToolCallCount = toolCallCount
-	state.LastAppliedSeq = event.Seq
-	if err := r.store.SaveSnapshot(jobID, state.LastAppliedSeq, state, r.now()); err != nil {
-		return nil, err
-	}
-	return state, nil
+// IncrementCounters adds to the job counters against the latest state, so
+// concurrent callers never overwrite each other's increments.
+func (r *Runner) IncrementCounters(jobID string, retryDelta, stepDelta, toolCallDelta int) (*State, error) {
+	state, _, err := r.appendState(jobID, eventCountersUpdated, func(state *State) (any, error) {
+		return map[string]any{
+			"retry_count":     state.RetryCount + retryDelta,
+			"step_count":      state.StepCount + stepDelta,
+			"tool_call_count": state.ToolCallCount + toolCallDelta,
+		}, nil
+	})
+	return state, err
 }
 
 func (r *Runner) RecordIdempotencyKey(jobID, key string) (*State, error) {
-	state, err := r.Recover(jobID)
-	if err != nil {
-		return nil, err
-	}
+	return r.RecordIdempotencyPhase(jobID, key, IdempotencyCommitted)
+}
 
-	event, err := r.store.AppendEvent(jobID, eventIdempotencyRecorded, map[string]any{"key": key}, r.now())
-	if err != nil {
-		return nil, err
+func (r *Runner) RecordIdempotencyPhase(jobID, key, phase string) (*State, error) {
+	switch phase {
+	case IdempotencyStarted, IdempotencyCommitted, IdempotencyFailed, IdempotencyReview, IdempotencySuperseded:
+	default:
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"invalid idempotency phase",
+			map[string]any{"key": key, "phase": phase},
+		)
 	}
-	state.IdempotencyKeys[key] = true
-	state.LastAppliedSeq = event.Seq
-	if err := r.store.SaveSnapshot(jobID, state.LastAppliedSeq, state, r.now()); err != nil {
-		return nil, err
+	state, _, err := r.appendState(jobID, eventIdempotencyRecorded, fixedPayload(map[string]any{"key": key, "phase": phase}))
+	return state, err
+}
+
+func (r *Runner) RecordUsage(jobID string, usage Usage) (*State, error) {
+	if usage.TokensIn < 0 || usage.TokensOut < 0 || usage.EstimatedCost < 0 {
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"usage values must be non-negative",
+			map[string]any{"job_id": jobID, "tokens_in": usage.TokensIn, "tokens_out": usage.TokensOut, "estimated_cost": usage.EstimatedCost},
+		)
 	}
-	return state, nil
+	// Usage reports arrive concurrently from external agents; the CAS append
+	// keeps totals from being folded into a stale snapshot.
+	state, _, err := r.appendState(jobID, eventUsageRecorded, fixedPayload(usage))
+	return state, err
 }
 
 func (r *Runner) AcquireLease(jobID, workerID, leaseID string) (*State, error) {
@@ -355,10 +461,10 @@ func (r *Runner) ReleaseLease(jobID, workerID, leaseID string) (*State, error) {
 				wrkrerrors.ELeaseConflict,
 				"release lease mismatch",
 				map[string]any{
-					"job_id":           jobID,
-					"worker_id":        workerID,
-					"lease_id":         leaseID,
-					"existing_worker":  state.Lease.WorkerID,
+					"job_id":            jobID,
+					"worker_id":         workerID,
+					"lease_id":          leaseID,
+					"existing_worker":   state.Lease.WorkerID,
 					"existing_lease_id": state.Lease.LeaseID,
 				},
 			)
@@ -398,8 +504,18 @@ func (r *Runner) ReleaseLease(jobID, workerID, leaseID string) (*State, error) {
 }
 
 func (r *Runner) EmitCheckpoint(jobID string, input CheckpointInput) (*v1.Checkpoint, error) {
-	state, err := r.Recover(jobID)
-	if err != nil {
+	return r.emitCheckpoint(jobID, input, nil)
+}
+
+// errCheckpointNotDue aborts an emitCheckpoint append whose due check no
+// longer holds against the latest state.
+var errCheckpointNotDue = errors.New("checkpoint not due")
+
+// emitCheckpoint appends a checkpoint through the CAS loop. A non-nil due is
+// re-evaluated on every attempt; when it fails nothing is appended and the
+// result is nil.
+func (r *Runner) emitCheckpoint(jobID string, input CheckpointInput, due func(*State) bool) (*v1.Checkpoint, error) {
+	if _, err := r.Recover(jobID); err != nil {
 		return nil, err
 	}
 
@@ -428,23 +544,23 @@ func (r *Runner) EmitCheckpoint(jobID string, input CheckpointInput) (*v1.Checkp
 			nil,
 		)
 	}
-
-	status := input.Status
-	if status == "" {
-		status = state.Status
+	if err := validateOptions(cpType, input.RequiredAction); err != nil {
+		return nil, err
+	}
+	if err := validateExpiry(cpType, input.RequiredAction); err != nil {
+		return nil, err
+	}
+	if err := validateAttachments(input.Attachments); err != nil {
+		return nil, err
 	}
-	if !queue.IsKnownStatus(status) {
+	if input.Status != "" && !queue.IsKnownStatus(input.Status) {
 		return nil, wrkrerrors.New(
 			wrkrerrors.EInvalidInputSchema,
 			"invalid checkpoint status",
-			map[string]any{"status": status},
+			map[string]any{"status": input.Status},
 		)
 	}
 
-	bs := input.BudgetState
-	if bs.WallTimeSeconds == 0 && bs.RetryCount == 0 && bs.StepCount == 0 && bs.ToolCallCount == 0 {
-		bs = budgetUsageFromState(state, r.now())
-	}
 	delta := input.ArtifactsDelta
 	if delta.Added == nil {
 		delta.Added = []string{}
@@ -459,34 +575,44 @@ func (r *Runner) EmitCheckpoint(jobID string, input CheckpointInput) (*v1.Checkp
 	if reasonCodes == nil {
 		reasonCodes = []string{}
 	}
-
-	payload := map[string]any{
-		"type":            cpType,
-		"summary":         summary,
-		"status":          string(status),
-		"budget_state":    bs,
-		"artifacts_delta": delta,
-		"required_action": input.RequiredAction,
-		"reason_codes":    reasonCodes,
-	}
-
-	event, err := r.store.AppendEvent(jobID, eventCheckpointEmitted, payload, r.now())
+	attachments, err := r.storeAttachments(jobID, input.Attachments)
 	if err != nil {
 		return nil, err
 	}
 
-	cp, err := checkpointFromEvent(jobID, event)
-	if err != nil {
-		return nil, err
+	_, event, err := r.appendState(jobID, eventCheckpointEmitted, func(state *State) (any, error) {
+		if due != nil && !due(state) {
+			return nil, errCheckpointNotDue
+		}
+		status := input.Status
+		if status == "" {
+			status = state.Status
+		}
+		bs := input.BudgetState
+		if bs == (v1.BudgetState{}) {
+			bs = budgetUsageFromState(state, r.now())
+		}
+		payload := map[string]any{
+			"type":            cpType,
+			"summary":         summary,
+			"status":          string(status),
+			"budget_state":    bs,
+			"artifacts_delta": delta,
+			"required_action": input.RequiredAction,
+			"reason_codes":    reasonCodes,
+		}
+		if len(attachments) > 0 {
+			payload["attachments"] = attachments
+		}
+		return payload, nil
+	})
+	if errors.Is(err, errCheckpointNotDue) {
+		return nil, nil
 	}
-
-	state.LastReasonCodes = append([]string(nil), cp.ReasonCodes...)
-	state.LastAppliedSeq = event.Seq
-	if err := r.store.SaveSnapshot(jobID, state.LastAppliedSeq, state, r.now()); err != nil {
+	if err != nil {
 		return nil, err
 	}
-
-	return cp, nil
+	return checkpointFromEvent(jobID, event)
 }
 
 func (r *Runner) ListCheckpoints(jobID string) ([]v1.Checkpoint, error) {
@@ -533,71 +659,127 @@ func (r *Runner) GetCheckpoint(jobID, checkpointID string) (*v1.Checkpoint, erro
 }
 
 func (r *Runner) ApproveCheckpoint(jobID, checkpointID, reason, approvedBy string) (*v1.ApprovalRecord, error) {
-	cp, err := r.GetCheckpoint(jobID, checkpointID)
+	cp, err := r.decisionCheckpoint(jobID, checkpointID)
 	if err != nil {
 		return nil, err
 	}
-	if cp.Type != "decision-needed" {
+	if cp.RequiredAction != nil && len(cp.RequiredAction.Options) > 0 {
 		return nil, wrkrerrors.New(
 			wrkrerrors.EInvalidInputSchema,
-			"only decision-needed checkpoints can be approved",
-			map[string]any{"checkpoint_id": checkpointID, "type": cp.Type},
+			"checkpoint offers options; choose one with decide",
+			map[string]any{"checkpoint_id": checkpointID, "options": optionNames(cp.RequiredAction.Options)},
 		)
 	}
+	return r.recordApproval(jobID, checkpointID, "", reason, approvedBy)
+}
 
-	if strings.TrimSpace(reason) == "" {
-		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approval reason is required", nil)
-	}
-	if strings.TrimSpace(approvedBy) == "" {
-		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approved_by is required", nil)
+func (r *Runner) ListApprovals(jobID string) ([]v1.ApprovalRecord, error) {
+	events, err := r.store.LoadEvents(jobID)
+	if err != nil {
+		return nil, err
 	}
 
-	record := v1.ApprovalRecord{
-		Envelope: v1.Envelope{
-			SchemaID:        "wrkr.approval_record",
-			SchemaVersion:   "v1",
-			CreatedAt:       r.now().UTC(),
-			ProducerVersion: "dev",
-		},
-		JobID:        jobID,
-		CheckpointID: checkpointID,
-		Reason:       strings.TrimSpace(reason),
-		ApprovedBy:   strings.TrimSpace(approvedBy),
+	out := make([]v1.ApprovalRecord, 0, 4)
+	for _, event := range events {
+		if event.Type != eventApprovalRecorded {
+			continue
+		}
+		var rec v1.ApprovalRecord
+		if err := json.Unmarshal(event.Payload, &rec); err != nil {
+			return nil, fmt.Errorf("decode approval payload: %w", err)
+		}
+		out = append(out, rec)
 	}
+	return out, nil
+}
 
-	event, err := r.store.AppendEvent(jobID, eventApprovalRecorded, record, r.now())
-	if err != nil {
-		return nil, err
+func (r *Runner) RecordArtifactCapture(jobID string, capture ArtifactCapture) error {
+	if capture.Artifacts == nil {
+		capture.Artifacts = []v1.ArtifactRecord{}
+	}
+	if capture.Missing == nil {
+		capture.Missing = []string{}
 	}
+	_, err := r.store.AppendEvent(jobID, eventArtifactsCaptured, capture, r.now())
+	return err
+}
 
-	state, err := r.Recover(jobID)
-	if err != nil {
-		return nil, err
+func (r *Runner) RecordSandbox(jobID string, record map[string]any) error {
+	_, err := r.store.AppendEvent(jobID, eventSandboxApplied, record, r.now())
+	return err
+}
+
+// RecordPolicy records the policy governing the job so replay and jobpack
+// export can report its hash.
+func (r *Runner) RecordPolicy(jobID string, ref v1.PolicyRef) (*State, error) {
+	state, _, err := r.appendState(jobID, eventPolicyLoaded, fixedPayload(ref))
+	return state, err
+}
+
+// RecordPriceTable records the price table used to compute estimated cost
+// so the jobpack can report its version and hash.
+func (r *Runner) RecordPriceTable(jobID string, ref v1.PriceTableRef) (*State, error) {
+	state, _, err := r.appendState(jobID, eventPriceTableLoaded, fixedPayload(ref))
+	return state, err
+}
+
+// AmendBudget records an approved raise of the job's budget limits. Warnings
+// already raised for the amended dimensions are cleared so they can fire
+// again against the new limits.
+func (r *Runner) AmendBudget(jobID string, amendment v1.BudgetAmendment) (*State, error) {
+	amendment.Reason = strings.TrimSpace(amendment.Reason)
+	amendment.ApprovedBy = strings.TrimSpace(amendment.ApprovedBy)
+	if amendment.Reason == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget amendment reason is required", nil)
 	}
-	state.LastAppliedSeq = event.Seq
-	if err := r.store.SaveSnapshot(jobID, state.LastAppliedSeq, state, r.now()); err != nil {
-		return nil, err
+	if amendment.ApprovedBy == "" {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "approved_by is required", nil)
+	}
+	if len(amendment.Changes) == 0 {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "budget amendment requires at least one raised limit", nil)
+	}
+	if amendment.AmendedAt.IsZero() {
+		amendment.AmendedAt = r.now().UTC()
 	}
 
-	return &record, nil
+	state, _, err := r.appendState(jobID, eventBudgetAmended, fixedPayload(amendment))
+	return state, err
 }
 
-func (r *Runner) ListApprovals(jobID string) ([]v1.ApprovalRecord, error) {
+func applyBudgetAmendment(state *State, amendment v1.BudgetAmendment) {
+	state.BudgetAmendments = append(state.BudgetAmendments, amendment)
+	if len(state.BudgetWarnings) == 0 {
+		return
+	}
+	amended := make(map[string]struct{}, len(amendment.Changes))
+	for _, change := range amendment.Changes {
+		amended[change.Dimension] = struct{}{}
+	}
+	kept := state.BudgetWarnings[:0]
+	for _, dim := range state.BudgetWarnings {
+		if _, ok := amended[dim]; !ok {
+			kept = append(kept, dim)
+		}
+	}
+	state.BudgetWarnings = kept
+}
+
+func (r *Runner) ListArtifactCaptures(jobID string) ([]ArtifactCapture, error) {
 	events, err := r.store.LoadEvents(jobID)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make([]v1.ApprovalRecord, 0, 4)
+	out := make([]ArtifactCapture, 0, 4)
 	for _, event := range events {
-		if event.Type != eventApprovalRecorded {
+		if event.Type != eventArtifactsCaptured {
 			continue
 		}
-		var rec v1.ApprovalRecord
-		if err := json.Unmarshal(event.Payload, &rec); err != nil {
-			return nil, fmt.Errorf("decode approval payload: %w", err)
+		var capture ArtifactCapture
+		if err := json.Unmarshal(event.Payload, &capture); err != nil {
+			return nil, fmt.Errorf("decode artifact capture payload: %w", err)
 		}
-		out = append(out, rec)
+		out = append(out, capture)
 	}
 	return out, nil
 }
@@ -608,15 +790,24 @@ func (r *Runner) CheckBudget(jobID string, limits budget.Limits) (*v1.Checkpoint
 		return nil, err
 	}
 
+	tokens := state.TokensIn + state.TokensOut
+	cost := state.EstimatedCost
 	usage := budget.Usage{
 		WallTimeSeconds: budgetUsageFromState(state, r.now()).WallTimeSeconds,
 		RetryCount:      state.RetryCount,
 		StepCount:       state.StepCount,
 		ToolCallCount:   state.ToolCallCount,
+		EstimatedCost:   &cost,
+		Tokens:          &tokens,
 	}
 	result := budget.Evaluate(limits, usage)
 	if !result.Exceeded {
-		return nil, nil
+		if limits.Pool != "" {
+			if cp, err := r.checkPool(state, limits.Pool); cp != nil || err != nil {
+				return cp, err
+			}
+		}
+		return r.warnBudget(state, limits, result.Warnings)
 	}
 
 	if state.Status != queue.StatusBlockedBudget {
@@ -643,17 +834,97 @@ func (r *Runner) CheckBudget(jobID string, limits budget.Limits) (*v1.Checkpoint
 	)
 }
 
+// BudgetStopStatus is the status an adapter reports when CheckBudget stops
+// it: blocked_decision for a warning awaiting approval, else blocked_budget.
+func BudgetStopStatus(err error) queue.Status {
+	var werr wrkrerrors.WrkrError
+	if errors.As(err, &werr) && werr.Code == wrkrerrors.ECheckpointApprovalRequired {
+		return queue.StatusBlockedDecision
+	}
+	return queue.StatusBlockedBudget
+}
+
+// errNoFreshWarnings aborts a budget_warning append once another caller has
+// recorded every crossed threshold.
+var errNoFreshWarnings = errors.New("no fresh budget warnings")
+
+// warnBudget emits one progress checkpoint for warning thresholds crossed
+// for the first time. With WarnRequiresDecision it also parks the job on a
+// decision-needed checkpoint asking whether to continue. Fresh thresholds are
+// re-checked against the latest state on every append attempt, so only the
+// caller that records them emits the checkpoints.
+func (r *Runner) warnBudget(state *State, limits budget.Limits, warnings []budget.Warning) (*v1.Checkpoint, error) {
+	jobID := state.JobID
+	var labels []string
+	state, _, err := r.appendState(jobID, eventBudgetWarning, func(current *State) (any, error) {
+		warned := make(map[string]struct{}, len(current.BudgetWarnings))
+		for _, dim := range current.BudgetWarnings {
+			warned[dim] = struct{}{}
+		}
+		fresh := []budget.Warning{}
+		labels = labels[:0]
+		for _, w := range warnings {
+			if _, ok := warned[w.Dimension]; ok {
+				continue
+			}
+			fresh = append(fresh, w)
+			labels = append(labels, w.String())
+		}
+		if len(fresh) == 0 {
+			return nil, errNoFreshWarnings
+		}
+		return map[string]any{"warnings": fresh}, nil
+	})
+	if errors.Is(err, errNoFreshWarnings) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summary := "budget warning: " + strings.Join(labels, ", ")
+	if _, err := r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:        "progress",
+		Summary:     summary,
+		Status:      state.Status,
+		ReasonCodes: []string{budget.ReasonWarning},
+	}); err != nil {
+		return nil, err
+	}
+	if !limits.WarnRequiresDecision || state.Status != queue.StatusRunning {
+		return nil, nil
+	}
+
+	cp, err := r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:    "decision-needed",
+		Summary: summary + "; continue?",
+		Status:  queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{
+			Kind:         "budget_continue",
+			Instructions: "approve to continue past the budget warning threshold, or cancel the job",
+		},
+		ReasonCodes: []string{budget.ReasonWarning},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusBlockedDecision); err != nil {
+		return nil, err
+	}
+	return cp, wrkrerrors.New(
+		wrkrerrors.ECheckpointApprovalRequired,
+		"budget warning threshold crossed; approval required to continue",
+		map[string]any{"job_id": jobID, "warnings": labels, "checkpoint_id": cp.CheckpointID},
+	)
+}
+
 func (r *Runner) Resume(jobID string, input ResumeInput) (*State, error) {
 	state, err := r.Recover(jobID)
 	if err != nil {
 		return nil, err
 	}
 
-	rules := state.EnvFingerprintRules
-	if len(rules) == 0 {
-		rules = envfp.DefaultRules()
-	}
-	currentFP, err := envfp.Capture(rules, r.now())
+	diff, currentFP, err := r.envDiff(state)
 	if err != nil {
 		return nil, err
 	}
@@ -662,34 +933,41 @@ func (r *Runner) Resume(jobID string, input ResumeInput) (*State, error) {
 		if _, err := r.store.AppendEvent(jobID, eventEnvFingerprintSet, currentFP, r.now()); err != nil {
 			return nil, err
 		}
-		state.EnvFingerprintHash = currentFP.Hash
-		state.EnvFingerprintRules = currentFP.Rules
-		state.EnvFingerprintValues = currentFP.Values
+		setEnvFingerprint(state, currentFP)
 	}
 
-	if state.EnvFingerprintHash != "" && state.EnvFingerprintHash != currentFP.Hash {
-		if !input.OverrideEnvMismatch {
-			if state.Status != queue.StatusBlockedError {
-				if _, err := r.ChangeStatus(jobID, queue.StatusBlockedError); err != nil {
-					return nil, err
-				}
-			}
-			if _, err := r.EmitCheckpoint(jobID, CheckpointInput{
-				Type:        "blocked",
-				Summary:     "environment fingerprint mismatch; resume blocked",
-				Status:      queue.StatusBlockedError,
-				BudgetState: budgetUsageFromState(state, r.now()),
-				ReasonCodes: []string{string(wrkrerrors.EEnvFingerprintMismatch)},
-			}); err != nil {
+	switch {
+	case diff.Blocking && !input.OverrideEnvMismatch:
+		if state.Status != queue.StatusBlockedError {
+			if _, err := r.ChangeStatus(jobID, queue.StatusBlockedError); err != nil {
 				return nil, err
 			}
-			return nil, wrkrerrors.New(
-				wrkrerrors.EEnvFingerprintMismatch,
-				"environment fingerprint mismatch",
-				map[string]any{"job_id": jobID, "expected_hash": state.EnvFingerprintHash, "actual_hash": currentFP.Hash},
-			)
 		}
-
+		report, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshal env diff: %w", err)
+		}
+		if _, err := r.EmitCheckpoint(jobID, CheckpointInput{
+			Type:        "blocked",
+			Summary:     envChangesSummary("environment fingerprint mismatch; resume blocked", diff.Changes),
+			Status:      queue.StatusBlockedError,
+			BudgetState: budgetUsageFromState(state, r.now()),
+			ReasonCodes: []string{string(wrkrerrors.EEnvFingerprintMismatch)},
+			Attachments: []AttachmentInput{{Name: "env_diff.json", MediaType: "application/json", Data: append(report, '\n')}},
+		}); err != nil {
+			return nil, err
+		}
+		return nil, wrkrerrors.New(
+			wrkrerrors.EEnvFingerprintMismatch,
+			"environment fingerprint mismatch",
+			map[string]any{
+				"job_id":        jobID,
+				"expected_hash": diff.ExpectedHash,
+				"actual_hash":   diff.ActualHash,
+				"changes":       diff.Changes,
+			},
+		)
+	case diff.Blocking:
 		overridePayload := map[string]any{
 			"expected_hash": state.EnvFingerprintHash,
 			"actual_hash":   currentFP.Hash,
@@ -697,11 +975,28 @@ func (r *Runner) Resume(jobID string, input ResumeInput) (*State, error) {
 			"approved_by":   strings.TrimSpace(input.ApprovedBy),
 			"rules":         currentFP.Rules,
 			"values":        currentFP.Values,
+			"digests":       currentFP.Digests,
+			"advisory":      currentFP.Advisory,
+			"changes":       diff.Changes,
 			"captured_at":   currentFP.CapturedAt.UTC(),
 		}
 		if _, err := r.store.AppendEvent(jobID, eventEnvOverrideRecorded, overridePayload, r.now()); err != nil {
 			return nil, err
 		}
+	case !diff.Match:
+		// Only advisory rules changed: report the drift and adopt the new
+		// values so the same change is not reported again.
+		if _, err := r.EmitCheckpoint(jobID, CheckpointInput{
+			Type:        "progress",
+			Summary:     envChangesSummary("environment fingerprint advisory drift", diff.Changes),
+			BudgetState: budgetUsageFromState(state, r.now()),
+			ReasonCodes: []string{ReasonEnvFingerprintAdvisory},
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := r.store.AppendEvent(jobID, eventEnvFingerprintSet, currentFP, r.now()); err != nil {
+			return nil, err
+		}
 	}
 
 	latestDecisionID, err := r.latestDecisionCheckpoint(jobID)
@@ -709,15 +1004,20 @@ func (r *Runner) Resume(jobID string, input ResumeInput) (*State, error) {
 		return nil, err
 	}
 	if latestDecisionID != "" {
-		approved, err := r.hasApproval(jobID, latestDecisionID)
+		decision, err := r.Decision(jobID, latestDecisionID)
 		if err != nil {
 			return nil, err
 		}
-		if !approved {
+		if !decision.Resolved() {
 			return nil, wrkrerrors.New(
 				wrkrerrors.ECheckpointApprovalRequired,
 				"approval required before resume",
-				map[string]any{"job_id": jobID, "checkpoint_id": latestDecisionID},
+				map[string]any{
+					"job_id":        jobID,
+					"checkpoint_id": latestDecisionID,
+					"approvals":     len(decision.Approvers),
+					"quorum":        decision.Quorum,
+				},
 			)
 		}
 	}
@@ -776,6 +1076,7 @@ func checkpointFromEvent(jobID string, event store.Event) (*v1.Checkpoint, error
 		ArtifactsDelta v1.ArtifactsDelta  `json:"artifacts_delta"`
 		RequiredAction *v1.RequiredAction `json:"required_action,omitempty"`
 		ReasonCodes    []string           `json:"reason_codes"`
+		Attachments    []v1.Attachment    `json:"attachments,omitempty"`
 	}
 	if err := json.Unmarshal(event.Payload, &payload); err != nil {
 		return nil, fmt.Errorf("decode checkpoint payload: %w", err)
@@ -797,6 +1098,7 @@ func checkpointFromEvent(jobID string, event store.Event) (*v1.Checkpoint, error
 		ArtifactsDelta: payload.ArtifactsDelta,
 		RequiredAction: payload.RequiredAction,
 		ReasonCodes:    payload.ReasonCodes,
+		Attachments:    payload.Attachments,
 	}, nil
 }
 
@@ -813,6 +1115,9 @@ func budgetUsageFromState(state *State, now time.Time) v1.BudgetState {
 		RetryCount:      state.RetryCount,
 		StepCount:       state.StepCount,
 		ToolCallCount:   state.ToolCallCount,
+		TokensIn:        state.TokensIn,
+		TokensOut:       state.TokensOut,
+		EstimatedCost:   state.EstimatedCost,
 	}
 }
 
@@ -829,17 +1134,25 @@ func (r *Runner) latestDecisionCheckpoint(jobID string) (string, error) {
 	return "", nil
 }
 
-func (r *Runner) hasApproval(jobID, checkpointID string) (bool, error) {
-	approvals, err := r.ListApprovals(jobID)
-	if err != nil {
-		return false, err
+func applyIdempotencyPhase(state *State, key, phase string) {
+	if state.IdempotencyKeys == nil {
+		state.IdempotencyKeys = map[string]bool{}
 	}
-	for _, rec := range approvals {
-		if rec.CheckpointID == checkpointID {
-			return true, nil
-		}
+	if state.IdempotencyPhases == nil {
+		state.IdempotencyPhases = map[string]string{}
+	}
+	state.IdempotencyPhases[key] = phase
+	if phase == IdempotencyCommitted {
+		state.IdempotencyKeys[key] = true
+	} else {
+		delete(state.IdempotencyKeys, key)
 	}
-	return false, nil
+}
+
+func applyUsage(state *State, usage Usage) {
+	state.TokensIn += usage.TokensIn
+	state.TokensOut += usage.TokensOut
+	state.EstimatedCost += usage.EstimatedCost
 }
 
 func applyEvent(state *State, event store.Event) error {
@@ -883,15 +1196,16 @@ func applyEvent(state *State, event store.Event) error {
 		return nil
 	case eventIdempotencyRecorded:
 		var payload struct {
-			Key string `json:"key"`
+			Key   string `json:"key"`
+			Phase string `json:"phase"`
 		}
 		if err := json.Unmarshal(event.Payload, &payload); err != nil {
 			return fmt.Errorf("decode idempotency payload: %w", err)
 		}
-		if state.IdempotencyKeys == nil {
-			state.IdempotencyKeys = map[string]bool{}
+		if payload.Phase == "" {
+			payload.Phase = IdempotencyCommitted
 		}
-		state.IdempotencyKeys[payload.Key] = true
+		applyIdempotencyPhase(state, payload.Key, payload.Phase)
 		return nil
 	case eventLeaseSet:
 		var rec lease.Record
@@ -911,39 +1225,118 @@ func applyEvent(state *State, event store.Event) error {
 			return fmt.Errorf("decode checkpoint payload: %w", err)
 		}
 		state.LastReasonCodes = append([]string(nil), payload.ReasonCodes...)
+		noteCheckpoint(state, payload.ReasonCodes, event.CreatedAt)
 		return nil
 	case eventEnvFingerprintSet:
 		var fp envfp.Fingerprint
 		if err := json.Unmarshal(event.Payload, &fp); err != nil {
 			return fmt.Errorf("decode env fingerprint payload: %w", err)
 		}
-		state.EnvFingerprintHash = fp.Hash
-		state.EnvFingerprintRules = append([]string(nil), fp.Rules...)
-		state.EnvFingerprintValues = make(map[string]string, len(fp.Values))
-		for k, v := range fp.Values {
-			state.EnvFingerprintValues[k] = v
-		}
+		setEnvFingerprint(state, fp)
 		return nil
 	case eventEnvOverrideRecorded:
 		var payload struct {
 			ActualHash string            `json:"actual_hash"`
 			Rules      []string          `json:"rules"`
 			Values     map[string]string `json:"values"`
+			Digests    map[string]string `json:"digests"`
+			Advisory   []string          `json:"advisory"`
 		}
 		if err := json.Unmarshal(event.Payload, &payload); err != nil {
 			return fmt.Errorf("decode env override payload: %w", err)
 		}
-		state.EnvFingerprintHash = payload.ActualHash
-		state.EnvFingerprintRules = append([]string(nil), payload.Rules...)
-		state.EnvFingerprintValues = make(map[string]string, len(payload.Values))
-		for k, v := range payload.Values {
-			state.EnvFingerprintValues[k] = v
-		}
+		setEnvFingerprint(state, envfp.Fingerprint{
+			Rules:    payload.Rules,
+			Values:   payload.Values,
+			Digests:  payload.Digests,
+			Advisory: payload.Advisory,
+			Hash:     payload.ActualHash,
+		})
 		return nil
-	case eventApprovalRecorded:
+	case eventApprovalRecorded, eventDecisionRejected:
 		return nil
 	case eventAdapterStep:
 		return nil
+	case eventArtifactsCaptured:
+		return nil
+	case eventSandboxApplied:
+		return nil
+	case eventBudgetWarning:
+		var payload struct {
+			Warnings []budget.Warning `json:"warnings"`
+		}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("decode budget warning payload: %w", err)
+		}
+		for _, w := range payload.Warnings {
+			state.BudgetWarnings = append(state.BudgetWarnings, w.Dimension)
+		}
+		return nil
+	case eventPriceTableLoaded:
+		var ref v1.PriceTableRef
+		if err := json.Unmarshal(event.Payload, &ref); err != nil {
+			return fmt.Errorf("decode price table payload: %w", err)
+		}
+		state.PriceTable = &ref
+		return nil
+	case eventCheckpointPolicySet:
+		var cpPolicy v1.CheckpointPolicy
+		if err := json.Unmarshal(event.Payload, &cpPolicy); err != nil {
+			return fmt.Errorf("decode checkpoint policy payload: %w", err)
+		}
+		state.CheckpointPolicy = &cpPolicy
+		return nil
+	case eventAcceptanceSpecSet:
+		var spec v1.AcceptanceSpec
+		if err := json.Unmarshal(event.Payload, &spec); err != nil {
+			return fmt.Errorf("decode acceptance spec payload: %w", err)
+		}
+		state.Acceptance = &spec
+		return nil
+	case eventSubmitterRecorded:
+		var payload submitterPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("decode submitter payload: %w", err)
+		}
+		state.SubmittedBy = payload.SubmittedBy
+		return nil
+	case eventApprovalRulesSet:
+		var payload approvalRulesPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("decode approval rules payload: %w", err)
+		}
+		state.ApprovalRules = payload.Rules
+		return nil
+	case eventBudgetPoolJoined:
+		var payload struct {
+			Pool string `json:"pool"`
+		}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("decode budget pool payload: %w", err)
+		}
+		state.BudgetPool = payload.Pool
+		return nil
+	case eventBudgetAmended:
+		var amendment v1.BudgetAmendment
+		if err := json.Unmarshal(event.Payload, &amendment); err != nil {
+			return fmt.Errorf("decode budget amendment payload: %w", err)
+		}
+		applyBudgetAmendment(state, amendment)
+		return nil
+	case eventPolicyLoaded:
+		var ref v1.PolicyRef
+		if err := json.Unmarshal(event.Payload, &ref); err != nil {
+			return fmt.Errorf("decode policy payload: %w", err)
+		}
+		state.Policy = &ref
+		return nil
+	case eventUsageRecorded:
+		var usage Usage
+		if err := json.Unmarshal(event.Payload, &usage); err != nil {
+			return fmt.Errorf("decode usage payload: %w", err)
+		}
+		applyUsage(state, usage)
+		return nil
 	default:
 		return wrkrerrors.New(
 			wrkrerrors.EStoreCorrupt,
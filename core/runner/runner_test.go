@@ -2,12 +2,19 @@ package runner
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/davidahmann/wrkr/core/budget"
+	"github.com/davidahmann/wrkr/core/budgetpool"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/policy"
 	"github.com/davidahmann/wrkr/core/queue"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	"github.com/davidahmann/wrkr/core/store"
 )
 
@@ -68,6 +75,48 @@ func TestRecoverPreservesCountersAndIdempotencyAcrossRestart(t *testing.T) {
 	}
 }
 
+func TestRecoverKeepsAcceptanceConfigUnmasked(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 10, 0, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	if _, err := r.InitJob("job_accept_cfg"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	command := "GITHUB_TOKEN=$CI_TOKEN go test ./..."
+	if _, err := r.RecordAcceptance("job_accept_cfg", v1.AcceptanceSpec{
+		Config: map[string]any{"test_command": command, "author": "ci-bot"},
+	}); err != nil {
+		t.Fatalf("record acceptance: %v", err)
+	}
+	if _, err := r.EmitCheckpoint("job_accept_cfg", CheckpointInput{Type: "progress", Summary: "ran deploy --token=abc123"}); err != nil {
+		t.Fatalf("emit checkpoint: %v", err)
+	}
+
+	s2, err := store.New(r.store.Root())
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r2, err := New(s2, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("runner.New: %v", err)
+	}
+	state, err := r2.Recover("job_accept_cfg")
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if state.Acceptance == nil || state.Acceptance.Config["test_command"] != command || state.Acceptance.Config["author"] != "ci-bot" {
+		t.Fatalf("expected acceptance config to round-trip, got %+v", state.Acceptance)
+	}
+	checkpoints, err := r2.ListCheckpoints("job_accept_cfg")
+	if err != nil {
+		t.Fatalf("list checkpoints: %v", err)
+	}
+	if len(checkpoints) != 1 || strings.Contains(checkpoints[0].Summary, "abc123") {
+		t.Fatalf("expected checkpoint summary masked, got %+v", checkpoints)
+	}
+}
+
 func TestInvalidStatusTransitionReturnsStableReasonCode(t *testing.T) {
 	t.Parallel()
 
@@ -273,3 +322,724 @@ func TestConcurrentLeaseAcquireReturnsSingleWinner(t *testing.T) {
 		t.Fatalf("expected one success and one conflict, got success=%d conflicts=%d", successes, conflicts)
 	}
 }
+
+func TestRecordIdempotencyPhases(t *testing.T) {
+	t.Parallel()
+
+	s, err := store.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	now := time.Date(2026, 2, 14, 2, 5, 0, 0, time.UTC)
+	r, err := New(s, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := r.InitJob("job_phases"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.RecordIdempotencyPhase("job_phases", "k", IdempotencyStarted); err != nil {
+		t.Fatalf("record started: %v", err)
+	}
+	state, err := r.Recover("job_phases")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.IdempotencyKeys["k"] || state.IdempotencyPhases["k"] != IdempotencyStarted {
+		t.Fatalf("expected started-only key, got %+v %+v", state.IdempotencyKeys, state.IdempotencyPhases)
+	}
+	if _, err := r.RecordIdempotencyPhase("job_phases", "k", IdempotencyCommitted); err != nil {
+		t.Fatalf("record committed: %v", err)
+	}
+	state, err = r.Recover("job_phases")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if !state.IdempotencyKeys["k"] || state.IdempotencyPhases["k"] != IdempotencyCommitted {
+		t.Fatalf("expected committed key, got %+v %+v", state.IdempotencyKeys, state.IdempotencyPhases)
+	}
+	if _, err := r.RecordIdempotencyPhase("job_phases", "k", "done"); err == nil {
+		t.Fatal("expected invalid phase error")
+	}
+}
+
+func TestConcurrentWritersKeepEachOthersEvents(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 2, 10, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	if _, err := r.InitJob("job_concurrent"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+
+	const members = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, members)
+	for i := 0; i < members; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := "member_" + string(rune('a'+i))
+			if _, err := r.RecordIdempotencyPhase("job_concurrent", key, IdempotencyStarted); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := r.UpdateCounters("job_concurrent", 0, i+1, i+1); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := r.RecordIdempotencyPhase("job_concurrent", key, IdempotencyCommitted); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent write: %v", err)
+	}
+
+	state, err := r.Recover("job_concurrent")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	for i := 0; i < members; i++ {
+		key := "member_" + string(rune('a'+i))
+		if !state.IdempotencyKeys[key] || state.IdempotencyPhases[key] != IdempotencyCommitted {
+			t.Fatalf("expected %s committed, got %+v", key, state.IdempotencyPhases)
+		}
+	}
+	if state.StepCount == 0 || state.StartedAt == nil {
+		t.Fatalf("expected counters and start time kept, got %+v", state)
+	}
+}
+
+func TestRecordUsageEnforcesTokenAndCostBudgets(t *testing.T) {
+	t.Parallel()
+
+	s, err := store.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	now := time.Date(2026, 2, 14, 2, 10, 0, 0, time.UTC)
+	r, err := New(s, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := r.InitJob("job_usage"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus("job_usage", queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus running: %v", err)
+	}
+	if _, err := r.RecordUsage("job_usage", Usage{Model: "m", TokensIn: 40, TokensOut: 10, EstimatedCost: 0.25}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+	if _, err := r.RecordUsage("job_usage", Usage{Model: "m", TokensIn: -1}); err == nil {
+		t.Fatal("expected negative usage error")
+	}
+	state, err := r.Recover("job_usage")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.TokensIn != 40 || state.TokensOut != 10 || state.EstimatedCost != 0.25 {
+		t.Fatalf("unexpected usage totals: %+v", state)
+	}
+
+	maxTokens := 100
+	if cp, err := r.CheckBudget("job_usage", budget.Limits{MaxTokens: &maxTokens}); err != nil || cp != nil {
+		t.Fatalf("expected tokens within budget, got cp=%v err=%v", cp, err)
+	}
+	maxCost := 0.2
+	cp, err := r.CheckBudget("job_usage", budget.Limits{MaxEstimatedCost: &maxCost})
+	if err == nil || cp == nil {
+		t.Fatalf("expected cost budget exceeded, got cp=%v err=%v", cp, err)
+	}
+}
+
+func TestConcurrentRecordUsageKeepsTotals(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 2, 20, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	if _, err := r.InitJob("job_usage_concurrent"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+
+	const reports = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*reports)
+	for i := 0; i < reports; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := r.RecordUsage("job_usage_concurrent", Usage{TokensIn: 10, TokensOut: 5, EstimatedCost: 0.25}); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := r.IncrementCounters("job_usage_concurrent", 0, 1, 0); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent write: %v", err)
+	}
+
+	state, err := r.Recover("job_usage_concurrent")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.TokensIn != 10*reports || state.TokensOut != 5*reports || state.EstimatedCost != 0.25*reports || state.StepCount != reports {
+		t.Fatalf("expected every usage report and step counted, got tokens=%d/%d cost=%v steps=%d", state.TokensIn, state.TokensOut, state.EstimatedCost, state.StepCount)
+	}
+}
+
+func TestCheckBudgetEmitsWarningOnce(t *testing.T) {
+	t.Parallel()
+
+	s, err := store.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	now := time.Date(2026, 2, 14, 2, 20, 0, 0, time.UTC)
+	r, err := New(s, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	maxTokens := 100
+	limits := budget.Limits{MaxTokens: &maxTokens, WarnAtPercent: map[string]int{budget.DimensionTokens: 80}}
+
+	for _, jobID := range []string{"job_warn", "job_warn_decide"} {
+		if _, err := r.InitJob(jobID); err != nil {
+			t.Fatalf("InitJob: %v", err)
+		}
+		if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+			t.Fatalf("ChangeStatus running: %v", err)
+		}
+		if _, err := r.RecordUsage(jobID, Usage{Model: "m", TokensIn: 85}); err != nil {
+			t.Fatalf("RecordUsage: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if cp, err := r.CheckBudget("job_warn", limits); err != nil || cp != nil {
+			t.Fatalf("expected soft warning only, got cp=%v err=%v", cp, err)
+		}
+	}
+	checkpoints, err := r.ListCheckpoints("job_warn")
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	warnings := 0
+	for _, cp := range checkpoints {
+		if len(cp.ReasonCodes) == 1 && cp.ReasonCodes[0] == budget.ReasonWarning {
+			warnings++
+			if cp.Type != "progress" || cp.Summary != "budget warning: tokens>=80%" {
+				t.Fatalf("unexpected warning checkpoint: %+v", cp)
+			}
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("expected one warning checkpoint, got %d", warnings)
+	}
+
+	limits.WarnRequiresDecision = true
+	cp, err := r.CheckBudget("job_warn_decide", limits)
+	if err == nil || cp == nil || cp.Type != "decision-needed" {
+		t.Fatalf("expected decision-needed warning, got cp=%v err=%v", cp, err)
+	}
+	if BudgetStopStatus(err) != queue.StatusBlockedDecision {
+		t.Fatalf("expected blocked_decision stop status, got %s", BudgetStopStatus(err))
+	}
+	state, err := r.Recover("job_warn_decide")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusBlockedDecision || len(state.BudgetWarnings) != 1 {
+		t.Fatalf("unexpected state after decision warning: %+v", state)
+	}
+}
+
+func TestCheckBudgetConcurrentCallersWarnOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 2, 25, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	jobID := "job_warn_race"
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus running: %v", err)
+	}
+	if _, err := r.RecordUsage(jobID, Usage{Model: "m", TokensIn: 85}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+	maxTokens := 100
+	limits := budget.Limits{MaxTokens: &maxTokens, WarnAtPercent: map[string]int{budget.DimensionTokens: 80}, WarnRequiresDecision: true}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = r.CheckBudget(jobID, limits)
+		}()
+	}
+	wg.Wait()
+
+	checkpoints, err := r.ListCheckpoints(jobID)
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	byType := map[string]int{}
+	for _, cp := range checkpoints {
+		byType[cp.Type]++
+	}
+	if byType["progress"] != 1 || byType["decision-needed"] != 1 {
+		t.Fatalf("expected one warning and one decision checkpoint, got %v", byType)
+	}
+	state, err := r.Recover(jobID)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(state.BudgetWarnings) != 1 || state.Status != queue.StatusBlockedDecision {
+		t.Fatalf("unexpected state after concurrent warnings: %+v", state)
+	}
+}
+
+func TestCheckBudgetBlocksOnExhaustedPool(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, budgetpool.ConfigFile), []byte(`pools:
+  - name: team-a
+    window: day
+    max_tokens: 100
+`), 0o600); err != nil {
+		t.Fatalf("write pools: %v", err)
+	}
+	s, err := store.New(root)
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	now := time.Date(2026, 2, 13, 23, 0, 0, 0, time.UTC)
+	r, err := New(s, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, jobID := range []string{"job_pool_a", "job_pool_b", "job_outside"} {
+		if _, err := r.InitJob(jobID); err != nil {
+			t.Fatalf("InitJob: %v", err)
+		}
+		if jobID != "job_outside" {
+			if _, err := r.RecordBudgetPool(jobID, "team-a"); err != nil {
+				t.Fatalf("RecordBudgetPool: %v", err)
+			}
+		}
+		if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+			t.Fatalf("ChangeStatus running: %v", err)
+		}
+	}
+	// Usage from the previous day does not count against today's window.
+	if _, err := r.RecordUsage("job_pool_a", Usage{TokensIn: 500}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := r.RecordUsage("job_pool_a", Usage{TokensIn: 40}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+	if _, err := r.RecordUsage("job_outside", Usage{TokensIn: 500}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+
+	limits := budget.Limits{Pool: "team-a"}
+	if cp, err := r.CheckBudget("job_pool_b", limits); err != nil || cp != nil {
+		t.Fatalf("expected pool headroom, got cp=%v err=%v", cp, err)
+	}
+	pool := budgetpool.Pool{Name: "team-a", Window: "day"}
+	usage, err := r.PoolUsage(pool)
+	if err != nil {
+		t.Fatalf("PoolUsage: %v", err)
+	}
+	if usage.Tokens != 40 || len(usage.Jobs) != 2 || usage.WallTimeSeconds != 2*3600 {
+		t.Fatalf("unexpected pool usage: %+v", usage)
+	}
+
+	if _, err := r.RecordUsage("job_pool_a", Usage{TokensOut: 60}); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+	cp, err := r.CheckBudget("job_pool_b", limits)
+	if err == nil || cp == nil {
+		t.Fatalf("expected exhausted pool to block, got cp=%v err=%v", cp, err)
+	}
+	if len(cp.ReasonCodes) != 1 || cp.ReasonCodes[0] != budget.ReasonPoolExhausted {
+		t.Fatalf("expected pool reason code, got %+v", cp.ReasonCodes)
+	}
+	state, err := r.Recover("job_pool_b")
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusBlockedBudget || state.BudgetPool != "team-a" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	if _, err := r.CheckBudget("job_pool_b", budget.Limits{Pool: "missing"}); err == nil {
+		t.Fatal("expected unknown pool error")
+	}
+
+	// The next window starts from a fresh index.
+	now = now.Add(24 * time.Hour)
+	usage, err = r.PoolUsage(pool)
+	if err != nil {
+		t.Fatalf("PoolUsage next window: %v", err)
+	}
+	if usage.Tokens != 0 || len(usage.Jobs) != 2 {
+		t.Fatalf("expected empty usage in the next window, got %+v", usage)
+	}
+}
+
+func TestPoolWallTimeCountsOnlyRunningTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 22, 0, 0, 0, time.UTC)
+	s, err := store.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	r, err := New(s, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := r.InitJob("job_pool_blocked"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.RecordBudgetPool("job_pool_blocked", "team-a"); err != nil {
+		t.Fatalf("RecordBudgetPool: %v", err)
+	}
+	step := func(d time.Duration, to queue.Status) {
+		t.Helper()
+		now = now.Add(d)
+		if _, err := r.ChangeStatus("job_pool_blocked", to); err != nil {
+			t.Fatalf("ChangeStatus %s: %v", to, err)
+		}
+	}
+	// Ran 30m before midnight, blocked across it, then ran 20m and paused.
+	step(time.Hour, queue.StatusRunning)
+	step(30*time.Minute, queue.StatusBlockedDecision)
+	step(time.Hour, queue.StatusRunning)
+	step(20*time.Minute, queue.StatusPaused)
+	now = now.Add(3 * time.Hour)
+
+	usage, err := r.PoolUsage(budgetpool.Pool{Name: "team-a", Window: "day"})
+	if err != nil {
+		t.Fatalf("PoolUsage: %v", err)
+	}
+	if usage.WallTimeSeconds != 20*60 {
+		t.Fatalf("expected only running time inside the window, got %+v", usage)
+	}
+}
+
+func TestCheckCheckpointIntervalWarnsOncePerSilence(t *testing.T) {
+	t.Parallel()
+
+	s, err := store.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	now := time.Date(2026, 2, 14, 2, 30, 0, 0, time.UTC)
+	r, err := New(s, Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	jobID := "job_silent"
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus running: %v", err)
+	}
+	if _, err := r.RecordCheckpointPolicy(jobID, v1.CheckpointPolicy{MinIntervalSeconds: 30}); err != nil {
+		t.Fatalf("RecordCheckpointPolicy: %v", err)
+	}
+
+	now = now.Add(20 * time.Second)
+	if cp, err := r.CheckCheckpointInterval(jobID); err != nil || cp != nil {
+		t.Fatalf("expected no warning within interval, got cp=%v err=%v", cp, err)
+	}
+
+	now = now.Add(20 * time.Second)
+	cp, err := r.CheckCheckpointInterval(jobID)
+	if err != nil {
+		t.Fatalf("CheckCheckpointInterval: %v", err)
+	}
+	if cp == nil || cp.Type != "progress" || len(cp.ReasonCodes) != 1 || cp.ReasonCodes[0] != ReasonCheckpointSilence {
+		t.Fatalf("expected silence warning, got %+v", cp)
+	}
+	if cp.Summary != "no checkpoint for 40s (checkpoint_policy.min_interval_seconds=30)" {
+		t.Fatalf("unexpected summary: %s", cp.Summary)
+	}
+
+	now = now.Add(time.Minute)
+	if cp, err := r.CheckCheckpointInterval(jobID); err != nil || cp != nil {
+		t.Fatalf("expected a single warning per silence, got cp=%v err=%v", cp, err)
+	}
+
+	if _, err := r.EmitCheckpoint(jobID, CheckpointInput{Type: "progress", Summary: "still working"}); err != nil {
+		t.Fatalf("EmitCheckpoint: %v", err)
+	}
+	state, err := r.Recover(jobID)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.CheckpointSilent || state.LastCheckpointAt == nil || !state.LastCheckpointAt.Equal(now) {
+		t.Fatalf("expected real checkpoint to reset silence, got %+v", state)
+	}
+
+	now = now.Add(31 * time.Second)
+	if cp, err := r.CheckCheckpointInterval(jobID); err != nil || cp == nil {
+		t.Fatalf("expected a new warning after renewed silence, got cp=%v err=%v", cp, err)
+	}
+}
+
+func TestCheckCheckpointIntervalConcurrentCallersWarnOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 2, 30, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	jobID := "job_silent_race"
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus running: %v", err)
+	}
+	if _, err := r.RecordCheckpointPolicy(jobID, v1.CheckpointPolicy{MinIntervalSeconds: 1}); err != nil {
+		t.Fatalf("RecordCheckpointPolicy: %v", err)
+	}
+	r.now = func() time.Time { return now.Add(time.Minute) }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.CheckCheckpointInterval(jobID); err != nil {
+				t.Errorf("CheckCheckpointInterval: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkpoints, err := r.ListCheckpoints(jobID)
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	if len(checkpoints) != 1 {
+		t.Fatalf("expected one silence warning, got %d", len(checkpoints))
+	}
+}
+
+func TestRejectCheckpointCancelsWithoutRoute(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 4, 30, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	jobID := "job_reject"
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus running: %v", err)
+	}
+	if _, err := r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:           "progress",
+		Summary:        "options on progress",
+		RequiredAction: &v1.RequiredAction{Options: []v1.DecisionOption{{Name: "a"}}},
+	}); err == nil {
+		t.Fatal("expected options on a non-decision checkpoint to fail")
+	}
+	cp, err := r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:           "decision-needed",
+		Summary:        "ship it?",
+		Status:         queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{Kind: "approval", Options: []v1.DecisionOption{{Name: "ship"}, {Name: "hold"}}},
+	})
+	if err != nil {
+		t.Fatalf("EmitCheckpoint: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusBlockedDecision); err != nil {
+		t.Fatalf("ChangeStatus blocked: %v", err)
+	}
+
+	if _, err := r.RejectCheckpoint(jobID, cp.CheckpointID, "", "lead", ""); err == nil {
+		t.Fatal("expected missing rejection reason to fail")
+	}
+	rejection, err := r.RejectCheckpoint(jobID, cp.CheckpointID, "not now", "lead", "")
+	if err != nil {
+		t.Fatalf("RejectCheckpoint: %v", err)
+	}
+	if rejection.RejectedBy != "lead" || rejection.RouteStep != "" {
+		t.Fatalf("unexpected rejection: %+v", rejection)
+	}
+	state, err := r.Recover(jobID)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if state.Status != queue.StatusCanceled {
+		t.Fatalf("expected canceled after unrouted rejection, got %s", state.Status)
+	}
+
+	_, err = r.DecideCheckpoint(jobID, cp.CheckpointID, "ship", "changed my mind", "lead")
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EInvalidStateTransition {
+		t.Fatalf("expected E_INVALID_STATE_TRANSITION deciding a rejected checkpoint, got %v", err)
+	}
+	decision, err := r.Decision(jobID, cp.CheckpointID)
+	if err != nil {
+		t.Fatalf("Decision: %v", err)
+	}
+	if decision.Approved || decision.Rejection == nil || !decision.Resolved() {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+}
+
+func TestApprovalQuorumRequiresDistinctAllowedApprovers(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 5, 0, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	jobID := "job_quorum"
+	if _, err := r.InitJob(jobID); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	if _, err := r.RecordSubmitter(jobID, "alice"); err != nil {
+		t.Fatalf("RecordSubmitter: %v", err)
+	}
+	if _, err := r.RecordApprovalRules(jobID, []policy.ApprovalRule{{
+		Name:               "production",
+		Kinds:              []string{"deploy*"},
+		Quorum:             2,
+		Approvers:          []string{"alice", "bob", "carol"},
+		ForbidSelfApproval: true,
+	}}); err != nil {
+		t.Fatalf("RecordApprovalRules: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusRunning); err != nil {
+		t.Fatalf("ChangeStatus running: %v", err)
+	}
+	cp, err := r.EmitCheckpoint(jobID, CheckpointInput{
+		Type:           "decision-needed",
+		Summary:        "deploy to production?",
+		Status:         queue.StatusBlockedDecision,
+		RequiredAction: &v1.RequiredAction{Kind: "deploy_prod", Instructions: "approve rollout"},
+	})
+	if err != nil {
+		t.Fatalf("EmitCheckpoint: %v", err)
+	}
+	if _, err := r.ChangeStatus(jobID, queue.StatusBlockedDecision); err != nil {
+		t.Fatalf("ChangeStatus blocked: %v", err)
+	}
+
+	denied := map[string]string{"submitter": "alice", "not allowlisted": "mallory", "unidentified": "unknown"}
+	for name, approver := range denied {
+		_, err := r.ApproveCheckpoint(jobID, cp.CheckpointID, "ok", approver)
+		var werr wrkrerrors.WrkrError
+		if !errors.As(err, &werr) || werr.Code != wrkrerrors.EPolicyDenied {
+			t.Fatalf("%s: expected E_POLICY_DENIED, got %v", name, err)
+		}
+	}
+
+	first, err := r.ApproveCheckpoint(jobID, cp.CheckpointID, "looks good", "bob")
+	if err != nil {
+		t.Fatalf("ApproveCheckpoint bob: %v", err)
+	}
+	if first.Rule != "production" || first.Approvals != 1 || first.Quorum != 2 || first.QuorumMet {
+		t.Fatalf("unexpected first approval: %+v", first)
+	}
+	_, err = r.Resume(jobID, ResumeInput{})
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.ECheckpointApprovalRequired {
+		t.Fatalf("expected E_CHECKPOINT_APPROVAL_REQUIRED before quorum, got %v", err)
+	}
+	if _, err := r.ApproveCheckpoint(jobID, cp.CheckpointID, "again", "bob"); err == nil {
+		t.Fatal("expected duplicate approver to fail")
+	}
+
+	second, err := r.ApproveCheckpoint(jobID, cp.CheckpointID, "confirmed", "carol")
+	if err != nil {
+		t.Fatalf("ApproveCheckpoint carol: %v", err)
+	}
+	if second.Approvals != 2 || !second.QuorumMet {
+		t.Fatalf("expected quorum met on second approval: %+v", second)
+	}
+	if _, err := r.Resume(jobID, ResumeInput{}); err != nil {
+		t.Fatalf("Resume after quorum: %v", err)
+	}
+	records, err := r.ListApprovals(jobID)
+	if err != nil {
+		t.Fatalf("ListApprovals: %v", err)
+	}
+	if len(records) != 2 || records[0].ApprovedBy != "bob" || records[1].ApprovedBy != "carol" {
+		t.Fatalf("unexpected approval records: %+v", records)
+	}
+}
+
+func TestEmitCheckpointStoresAttachments(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 3, 0, 0, 0, time.UTC)
+	r := testRunner(t, now)
+	if _, err := r.InitJob("job_attach"); err != nil {
+		t.Fatalf("InitJob: %v", err)
+	}
+	diff := []byte("--- a/deploy.sh\n+++ b/deploy.sh\n+export TOKEN=abc123\n")
+	cp, err := r.EmitCheckpoint("job_attach", CheckpointInput{
+		Type:    "progress",
+		Summary: "changed deploy script",
+		Attachments: []AttachmentInput{
+			{Name: "change.diff", Data: diff},
+			{Name: "blob.bin", Data: []byte{0xff, 0x00}},
+			{Name: "dump.bin", Data: []byte("\xff\x00token=s3cr3tvalue")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("EmitCheckpoint: %v", err)
+	}
+	if len(cp.Attachments) != 3 || cp.Attachments[0].MediaType != "text/x-diff" || !cp.Attachments[0].Redacted ||
+		cp.Attachments[1].MediaType != "application/octet-stream" || cp.Attachments[1].Redacted ||
+		cp.Attachments[2].MediaType != "application/octet-stream" || !cp.Attachments[2].Redacted {
+		t.Fatalf("unexpected attachments: %+v", cp.Attachments)
+	}
+
+	loaded, err := r.GetCheckpoint("job_attach", cp.CheckpointID)
+	if err != nil {
+		t.Fatalf("GetCheckpoint: %v", err)
+	}
+	data, err := r.ReadAttachment("job_attach", loaded.Attachments[0])
+	if err != nil {
+		t.Fatalf("ReadAttachment: %v", err)
+	}
+	if string(data) != "--- a/deploy.sh\n+++ b/deploy.sh\n+export TOKEN=[REDACTED]\n" || int64(len(data)) != loaded.Attachments[0].Size {
+		t.Fatalf("unexpected attachment content %q", data)
+	}
+
+	for _, bad := range [][]AttachmentInput{
+		{{Name: "a.log", Data: []byte("x")}, {Name: "a.log", Data: []byte("y")}},
+		{{Name: "../escape", Data: []byte("x")}},
+		{{Name: ".env", Data: []byte("x")}},
+		{{Name: strings.Repeat("a", 129), Data: []byte("x")}},
+		{{Name: "big.log", Data: make([]byte, MaxAttachmentBytes+1)}},
+	} {
+		_, err := r.EmitCheckpoint("job_attach", CheckpointInput{Type: "progress", Summary: "bad", Attachments: bad})
+		var werr wrkrerrors.WrkrError
+		if !errors.As(err, &werr) || werr.Code != wrkrerrors.EInvalidInputSchema {
+			t.Fatalf("expected invalid attachment error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package sandbox
+
+import (
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/queue"
+	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+// Apply prepares the job's sandbox profile and records it as a
+// sandbox_applied event. A profile the host cannot enforce blocks the job
+// rather than letting commands run unsandboxed.
+func Apply(r *runner.Runner, jobID string, spec *v1.SandboxSpec, workspace string) (*Sandbox, error) {
+	if !Enabled(spec) {
+		return nil, nil
+	}
+	sb, err := Prepare(*spec, workspace)
+	if err != nil {
+		_, _ = r.EmitCheckpoint(jobID, runner.CheckpointInput{
+			Type:        "blocked",
+			Summary:     "sandbox profile cannot be enforced",
+			Status:      queue.StatusBlockedError,
+			ReasonCodes: []string{string(wrkrerrors.EUnsafeOperation)},
+		})
+		_, _ = r.ChangeStatus(jobID, queue.StatusBlockedError)
+		return nil, err
+	}
+	if err := r.RecordSandbox(jobID, sb.Record()); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
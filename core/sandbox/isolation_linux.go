@@ -0,0 +1,62 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func applyIsolation(cmd *exec.Cmd, sb *Sandbox) error {
+	var flags uintptr
+	if sb.spec.ReadOnly {
+		flags |= syscall.CLONE_NEWNS
+	}
+	if sb.spec.Network == NetworkNone {
+		flags |= syscall.CLONE_NEWNET
+	}
+	if flags == 0 {
+		return nil
+	}
+	attr := &syscall.SysProcAttr{Cloneflags: flags}
+	if os.Geteuid() != 0 {
+		// Unprivileged callers get mount and network namespaces through a
+		// user namespace that maps them to root inside it.
+		attr.Cloneflags |= syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Geteuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getegid(), Size: 1}}
+		attr.GidMappingsEnableSetgroups = false
+	}
+	cmd.SysProcAttr = attr
+	return nil
+}
+
+// checkHost rejects rlimits above the hard limit an unprivileged process
+// cannot raise.
+func checkHost(sb *Sandbox) error {
+	if os.Geteuid() == 0 {
+		return nil
+	}
+	limits := []struct {
+		resource int
+		want     uint64
+		name     string
+	}{
+		{syscall.RLIMIT_CPU, uint64(sb.spec.CPUSeconds), ControlCPU},
+		{syscall.RLIMIT_AS, uint64(sb.spec.MemoryMB) * 1024 * 1024, ControlMemory},
+		{syscall.RLIMIT_NOFILE, uint64(sb.spec.MaxOpenFiles), ControlOpenFiles},
+	}
+	for _, limit := range limits {
+		if limit.want == 0 {
+			continue
+		}
+		var current syscall.Rlimit
+		if err := syscall.Getrlimit(limit.resource, &current); err != nil {
+			return fmt.Errorf("%s: %w", limit.name, err)
+		}
+		if current.Max != ^uint64(0) && limit.want > current.Max {
+			return fmt.Errorf("%s: requested %d exceeds hard limit %d", limit.name, limit.want, current.Max)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+func applyIsolation(cmd *exec.Cmd, sb *Sandbox) error {
+	if sb.spec.ReadOnly || sb.spec.Network == NetworkNone {
+		return fmt.Errorf("read_only and network none require linux namespaces (host is %s)", runtime.GOOS)
+	}
+	return nil
+}
+
+func checkHost(sb *Sandbox) error {
+	if sb.spec.ReadOnly || sb.spec.Network == NetworkNone {
+		return fmt.Errorf("read_only and network none require linux namespaces (host is %s)", runtime.GOOS)
+	}
+	return nil
+}
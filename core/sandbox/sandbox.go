@@ -0,0 +1,257 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	"github.com/davidahmann/wrkr/core/fsx"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+const (
+	ControlCPU          = "rlimit_cpu"
+	ControlMemory       = "rlimit_memory"
+	ControlOpenFiles    = "rlimit_nofile"
+	ControlEnvAllowlist = "env_allowlist"
+	ControlReadOnly     = "read_only_fs"
+	ControlNetworkNone  = "network_none"
+
+	NetworkHost = "host"
+	NetworkNone = "none"
+
+	// setupFailedExit is returned by the sandbox shell when a control could
+	// not be applied before the real command starts.
+	setupFailedExit = 125
+)
+
+// Sandbox wraps adapter commands so they run under a JobSpec sandbox profile.
+// A nil *Sandbox runs commands unchanged.
+type Sandbox struct {
+	spec      v1.SandboxSpec
+	writable  []string
+	mountPath string
+	controls  []string
+}
+
+func Enabled(spec *v1.SandboxSpec) bool {
+	if spec == nil {
+		return false
+	}
+	return spec.CPUSeconds > 0 ||
+		spec.MemoryMB > 0 ||
+		spec.MaxOpenFiles > 0 ||
+		spec.EnvAllowlist != nil ||
+		spec.ReadOnly ||
+		spec.Network == NetworkNone
+}
+
+// Prepare validates the profile and proves the host can enforce every
+// requested control by running a no-op command through it. Any control the
+// host cannot apply fails closed with E_UNSAFE_OPERATION.
+func Prepare(spec v1.SandboxSpec, workspace string) (*Sandbox, error) {
+	if spec.CPUSeconds < 0 || spec.MemoryMB < 0 || spec.MaxOpenFiles < 0 {
+		return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "sandbox limits must be positive", nil)
+	}
+	switch spec.Network {
+	case "", NetworkHost, NetworkNone:
+	default:
+		return nil, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"sandbox network must be host or none",
+			map[string]any{"network": spec.Network},
+		)
+	}
+
+	sb := &Sandbox{spec: spec}
+	for _, raw := range spec.WritablePaths {
+		path := raw
+		if !filepath.IsAbs(path) && strings.TrimSpace(workspace) != "" {
+			path = filepath.Join(workspace, path)
+		}
+		normalized, err := fsx.NormalizeAbsolutePath(path)
+		if err != nil {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "invalid sandbox writable path", map[string]any{"path": raw, "error": err.Error()})
+		}
+		if _, err := os.Stat(normalized); err != nil {
+			return nil, wrkrerrors.New(wrkrerrors.EInvalidInputSchema, "sandbox writable path must exist", map[string]any{"path": raw})
+		}
+		sb.writable = append(sb.writable, normalized)
+	}
+	sort.Strings(sb.writable)
+
+	if spec.CPUSeconds > 0 {
+		sb.controls = append(sb.controls, ControlCPU)
+	}
+	if spec.MemoryMB > 0 {
+		sb.controls = append(sb.controls, ControlMemory)
+	}
+	if spec.MaxOpenFiles > 0 {
+		sb.controls = append(sb.controls, ControlOpenFiles)
+	}
+	if spec.EnvAllowlist != nil {
+		sb.controls = append(sb.controls, ControlEnvAllowlist)
+	}
+	if spec.ReadOnly {
+		sb.controls = append(sb.controls, ControlReadOnly)
+		mountPath, err := exec.LookPath("mount")
+		if err != nil {
+			return nil, unsupported(sb, "mount utility not found", err)
+		}
+		sb.mountPath = mountPath
+	}
+	if spec.Network == NetworkNone {
+		sb.controls = append(sb.controls, ControlNetworkNone)
+	}
+	if err := checkHost(sb); err != nil {
+		return nil, unsupported(sb, "host cannot enforce sandbox profile", err)
+	}
+
+	probe, err := sb.Command("true")
+	if err != nil {
+		return nil, unsupported(sb, "host cannot enforce sandbox profile", err)
+	}
+	var stderr bytes.Buffer
+	probe.Stderr = &stderr
+	if err := probe.Run(); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" {
+			detail = err.Error()
+		}
+		return nil, unsupported(sb, "sandbox probe failed", fmt.Errorf("%s", detail))
+	}
+	return sb, nil
+}
+
+func unsupported(sb *Sandbox, message string, cause error) error {
+	return wrkrerrors.New(
+		wrkrerrors.EUnsafeOperation,
+		message,
+		map[string]any{"controls": sb.controls, "error": cause.Error()},
+	)
+}
+
+// Controls lists the enforced controls in a stable order.
+func (sb *Sandbox) Controls() []string {
+	if sb == nil {
+		return nil
+	}
+	return append([]string(nil), sb.controls...)
+}
+
+// Record is the applied profile as written to the event log.
+func (sb *Sandbox) Record() map[string]any {
+	if sb == nil {
+		return nil
+	}
+	network := sb.spec.Network
+	if network == "" {
+		network = NetworkHost
+	}
+	envAllowlist := append([]string{}, sb.spec.EnvAllowlist...)
+	sort.Strings(envAllowlist)
+	return map[string]any{
+		"controls":       sb.Controls(),
+		"cpu_seconds":    sb.spec.CPUSeconds,
+		"memory_mb":      sb.spec.MemoryMB,
+		"max_open_files": sb.spec.MaxOpenFiles,
+		"env_allowlist":  envAllowlist,
+		"read_only":      sb.spec.ReadOnly,
+		"writable_paths": append([]string{}, sb.writable...),
+		"network":        network,
+	}
+}
+
+// Command builds the command for name/args. With a sandbox the command runs
+// behind a small sh prelude that applies mounts and rlimits, then execs it.
+func (sb *Sandbox) Command(name string, args ...string) (*exec.Cmd, error) {
+	return sb.CommandContext(context.Background(), name, args...)
+}
+
+// CommandContext is Command with the process killed when ctx is done.
+func (sb *Sandbox) CommandContext(ctx context.Context, name string, args ...string) (*exec.Cmd, error) {
+	if sb == nil {
+		// #nosec G204 -- adapters execute commands declared in the jobspec.
+		return exec.CommandContext(ctx, name, args...), nil
+	}
+	if !strings.Contains(name, "/") {
+		if resolved, err := exec.LookPath(name); err == nil {
+			name = resolved
+		}
+	}
+	argv := append([]string{"-c", sb.prelude() + `exec "$@"`, "wrkr-sandbox", name}, args...)
+	// #nosec G204 -- adapters execute commands declared in the jobspec.
+	cmd := exec.CommandContext(ctx, "/bin/sh", argv...)
+	cmd.Env = sb.Environ(nil)
+	if err := applyIsolation(cmd, sb); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// Environ returns the environment for sandboxed commands: the allowlisted
+// parent variables plus extra, or the full parent environment plus extra
+// when no allowlist is set.
+func (sb *Sandbox) Environ(extra []string) []string {
+	parent := os.Environ()
+	if sb == nil || sb.spec.EnvAllowlist == nil {
+		return append(parent, extra...)
+	}
+	allowed := make(map[string]struct{}, len(sb.spec.EnvAllowlist))
+	for _, name := range sb.spec.EnvAllowlist {
+		allowed[name] = struct{}{}
+	}
+	out := make([]string, 0, len(allowed)+len(extra))
+	for _, kv := range parent {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := allowed[name]; ok {
+			out = append(out, kv)
+		}
+	}
+	return append(out, extra...)
+}
+
+func (sb *Sandbox) prelude() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "wrkr_fail() { echo \"wrkr-sandbox: $*\" >&2; exit %d; }\n", setupFailedExit)
+	if sb.spec.ReadOnly {
+		m := shellQuote(sb.mountPath)
+		fmt.Fprintf(&b, "%s --make-rprivate / || wrkr_fail make-rprivate\n", m)
+		for _, path := range sb.writable {
+			fmt.Fprintf(&b, "%s --bind %s %s || wrkr_fail bind %s\n", m, shellQuote(path), shellQuote(path), shellQuote(path))
+		}
+		skip := "/proc|/proc/*|/sys|/sys/*|/dev|/dev/*"
+		for _, path := range sb.writable {
+			skip += "|" + shellQuote(path) + "|" + shellQuote(path) + "/*"
+		}
+		b.WriteString("wrkr_mounts=$(while read -r _src mp _fs opts _rest; do echo \"$mp $opts\"; done < /proc/self/mounts) || wrkr_fail read mounts\n")
+		b.WriteString("echo \"$wrkr_mounts\" | while read -r mp opts; do\n")
+		b.WriteString("  [ -n \"$mp\" ] || continue\n")
+		b.WriteString("  mp=$(printf '%b' \"$mp\")\n")
+		fmt.Fprintf(&b, "  case \"$mp\" in %s) continue ;; esac\n", skip)
+		b.WriteString("  flags=remount,bind,ro\n")
+		b.WriteString("  for f in nosuid nodev noexec; do case \",$opts,\" in *,$f,*) flags=\"$flags,$f\" ;; esac; done\n")
+		fmt.Fprintf(&b, "  %s -o \"$flags\" \"$mp\" || exit %d\n", m, setupFailedExit)
+		fmt.Fprintf(&b, "done || wrkr_fail remount read-only\n")
+	}
+	if sb.spec.CPUSeconds > 0 {
+		fmt.Fprintf(&b, "ulimit -t %d || wrkr_fail cpu rlimit\n", sb.spec.CPUSeconds)
+	}
+	if sb.spec.MemoryMB > 0 {
+		fmt.Fprintf(&b, "ulimit -v %d || wrkr_fail memory rlimit\n", sb.spec.MemoryMB*1024)
+	}
+	if sb.spec.MaxOpenFiles > 0 {
+		fmt.Fprintf(&b, "ulimit -n %d || wrkr_fail nofile rlimit\n", sb.spec.MaxOpenFiles)
+	}
+	return b.String()
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,110 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
+)
+
+func TestNilSandboxRunsCommandUnchanged(t *testing.T) {
+	t.Parallel()
+
+	var sb *Sandbox
+	cmd, err := sb.Command("sh", "-c", "exit 0")
+	if err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+	if cmd.SysProcAttr != nil || cmd.Env != nil {
+		t.Fatalf("expected plain command, got %+v", cmd)
+	}
+	if sb.Record() != nil || Enabled(nil) || Enabled(&v1.SandboxSpec{Network: NetworkHost}) {
+		t.Fatal("expected disabled sandbox")
+	}
+}
+
+func TestEnvAllowlistAndRlimits(t *testing.T) {
+	t.Setenv("WRKR_SANDBOX_KEEP", "kept")
+	t.Setenv("WRKR_SANDBOX_DROP", "dropped")
+
+	sb, err := Prepare(v1.SandboxSpec{
+		CPUSeconds:   30,
+		MaxOpenFiles: 64,
+		EnvAllowlist: []string{"WRKR_SANDBOX_KEEP"},
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	cmd, err := sb.Command("sh", "-c", `echo "$WRKR_SANDBOX_KEEP:${WRKR_SANDBOX_DROP:-unset}:$(ulimit -n):$(ulimit -t)"`)
+	if err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run sandboxed command: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "kept:unset:64:30" {
+		t.Fatalf("unexpected sandboxed output %q", got)
+	}
+	record := sb.Record()
+	controls, _ := record["controls"].([]string)
+	if strings.Join(controls, ",") != "rlimit_cpu,rlimit_nofile,env_allowlist" || record["network"] != NetworkHost {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
+
+func TestReadOnlyAndNetworkIsolation(t *testing.T) {
+	writable := t.TempDir()
+	outside := t.TempDir()
+	sb, err := Prepare(v1.SandboxSpec{
+		ReadOnly:      true,
+		WritablePaths: []string{writable},
+		Network:       NetworkNone,
+	}, writable)
+	if err != nil {
+		var werr wrkrerrors.WrkrError
+		if errors.As(err, &werr) && werr.Code == wrkrerrors.EUnsafeOperation {
+			t.Skipf("host cannot enforce namespaces: %v", err)
+		}
+		t.Fatalf("Prepare: %v", err)
+	}
+
+	script := "echo ok > " + filepath.Join(writable, "in.txt") +
+		" && ! echo no > " + filepath.Join(outside, "out.txt") + " 2>/dev/null" +
+		" && [ \"$(grep -c ':' /proc/net/dev)\" = 1 ]"
+	cmd, err := sb.Command("sh", "-c", script)
+	if err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sandboxed command failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(writable, "in.txt")); err != nil {
+		t.Fatalf("expected write inside writable path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "out.txt")); err == nil {
+		t.Fatal("expected write outside writable paths to fail")
+	}
+}
+
+func TestPrepareFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Prepare(v1.SandboxSpec{Network: "bridge"}, ""); err == nil {
+		t.Fatal("expected invalid network error")
+	}
+	if _, err := Prepare(v1.SandboxSpec{ReadOnly: true, WritablePaths: []string{"missing-dir"}}, t.TempDir()); err == nil {
+		t.Fatal("expected missing writable path error")
+	}
+
+	sb := &Sandbox{spec: v1.SandboxSpec{CPUSeconds: 1}, controls: []string{ControlCPU}}
+	err := unsupported(sb, "host cannot enforce sandbox profile", errors.New("no"))
+	var werr wrkrerrors.WrkrError
+	if !errors.As(err, &werr) || werr.Code != wrkrerrors.EUnsafeOperation {
+		t.Fatalf("expected unsafe operation error, got %v", err)
+	}
+}
@@ -16,12 +16,22 @@ type AdapterConfig struct {
 }
 
 type BudgetSpec struct {
-	MaxWallTimeSeconds int      `json:"max_wall_time_seconds"`
-	MaxRetries         int      `json:"max_retries"`
-	MaxStepCount       int      `json:"max_step_count"`
-	MaxToolCalls       int      `json:"max_tool_calls"`
-	MaxEstimatedCost   *float64 `json:"max_estimated_cost,omitempty"`
-	MaxTokens          *int     `json:"max_tokens,omitempty"`
+	MaxWallTimeSeconds int             `json:"max_wall_time_seconds"`
+	MaxRetries         int             `json:"max_retries"`
+	MaxStepCount       int             `json:"max_step_count"`
+	MaxToolCalls       int             `json:"max_tool_calls"`
+	MaxEstimatedCost   *float64        `json:"max_estimated_cost,omitempty"`
+	MaxTokens          *int            `json:"max_tokens,omitempty"`
+	Warnings           *BudgetWarnings `json:"warnings,omitempty"`
+	Pool               string          `json:"pool,omitempty"`
+}
+
+// BudgetWarnings sets soft thresholds as a percentage of each limit.
+// Dimensions overrides Percent for individual budget dimensions.
+type BudgetWarnings struct {
+	Percent         int            `json:"percent,omitempty"`
+	Dimensions      map[string]int `json:"dimensions,omitempty"`
+	RequireDecision bool           `json:"require_decision,omitempty"`
 }
 
 type CheckpointPolicy struct {
@@ -30,7 +40,8 @@ type CheckpointPolicy struct {
 }
 
 type EnvironmentFingerprint struct {
-	Rules []string `json:"rules"`
+	Rules    []string `json:"rules"`
+	Advisory []string `json:"advisory,omitempty"`
 }
 
 type JobSpec struct {
@@ -44,13 +55,44 @@ type JobSpec struct {
 	CheckpointPolicy       CheckpointPolicy       `json:"checkpoint_policy"`
 	Acceptance             map[string]any         `json:"acceptance,omitempty"`
 	EnvironmentFingerprint EnvironmentFingerprint `json:"environment_fingerprint,omitempty"`
+	Sandbox                *SandboxSpec           `json:"sandbox,omitempty"`
+	Policy                 string                 `json:"policy,omitempty"`
+	PriceTable             string                 `json:"price_table,omitempty"`
+	Redaction              *RedactionSpec         `json:"redaction,omitempty"`
+}
+
+// AcceptanceSpec is the acceptance part of a jobspec, recorded at submit:
+// expected artifacts plus the inline acceptance block.
+type AcceptanceSpec struct {
+	ExpectedArtifacts []string       `json:"expected_artifacts,omitempty"`
+	Config            map[string]any `json:"config,omitempty"`
+}
+
+// RedactionSpec adds job-specific secret patterns and env-var names to the
+// built-in redaction detectors.
+type RedactionSpec struct {
+	Patterns []string `json:"patterns,omitempty"`
+	EnvVars  []string `json:"env_vars,omitempty"`
+}
+
+type SandboxSpec struct {
+	CPUSeconds    int      `json:"cpu_seconds,omitempty"`
+	MemoryMB      int      `json:"memory_mb,omitempty"`
+	MaxOpenFiles  int      `json:"max_open_files,omitempty"`
+	EnvAllowlist  []string `json:"env_allowlist,omitempty"`
+	ReadOnly      bool     `json:"read_only,omitempty"`
+	WritablePaths []string `json:"writable_paths,omitempty"`
+	Network       string   `json:"network,omitempty"`
 }
 
 type BudgetState struct {
-	WallTimeSeconds int `json:"wall_time_seconds"`
-	RetryCount      int `json:"retry_count"`
-	StepCount       int `json:"step_count"`
-	ToolCallCount   int `json:"tool_call_count"`
+	WallTimeSeconds int     `json:"wall_time_seconds"`
+	RetryCount      int     `json:"retry_count"`
+	StepCount       int     `json:"step_count"`
+	ToolCallCount   int     `json:"tool_call_count"`
+	TokensIn        int     `json:"tokens_in,omitempty"`
+	TokensOut       int     `json:"tokens_out,omitempty"`
+	EstimatedCost   float64 `json:"estimated_cost,omitempty"`
 }
 
 type ArtifactsDelta struct {
@@ -59,9 +101,23 @@ type ArtifactsDelta struct {
 	Removed []string `json:"removed"`
 }
 
+// RequiredAction describes what a blocked or decision checkpoint needs.
+// Past Deadline an unresolved decision expires: it is escalated to
+// EscalateTo, or the job is canceled when CancelOnExpiry is set.
 type RequiredAction struct {
-	Kind         string `json:"kind,omitempty"`
-	Instructions string `json:"instructions,omitempty"`
+	Kind           string           `json:"kind,omitempty"`
+	Instructions   string           `json:"instructions,omitempty"`
+	Options        []DecisionOption `json:"options,omitempty"`
+	Deadline       *time.Time       `json:"deadline,omitempty"`
+	EscalateTo     string           `json:"escalate_to,omitempty"`
+	CancelOnExpiry bool             `json:"cancel_on_expiry,omitempty"`
+}
+
+// DecisionOption is one named alternative offered by a decision-needed
+// checkpoint.
+type DecisionOption struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
 }
 
 type Checkpoint struct {
@@ -75,6 +131,18 @@ type Checkpoint struct {
 	ArtifactsDelta ArtifactsDelta  `json:"artifacts_delta"`
 	RequiredAction *RequiredAction `json:"required_action,omitempty"`
 	ReasonCodes    []string        `json:"reason_codes"`
+	Attachments    []Attachment    `json:"attachments,omitempty"`
+}
+
+// Attachment is a file stored content-addressed with a checkpoint, such as
+// the diff or test log that justified it. Redacted marks text whose secrets
+// were masked before storage.
+type Attachment struct {
+	Name      string `json:"name"`
+	MediaType string `json:"media_type"`
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+	Redacted  bool   `json:"redacted,omitempty"`
 }
 
 type ApprovalRecord struct {
@@ -83,6 +151,11 @@ type ApprovalRecord struct {
 	CheckpointID string `json:"checkpoint_id"`
 	Reason       string `json:"reason"`
 	ApprovedBy   string `json:"approved_by"`
+	Option       string `json:"option,omitempty"`
+	Rule         string `json:"rule,omitempty"`
+	Quorum       int    `json:"quorum,omitempty"`
+	Approvals    int    `json:"approvals,omitempty"`
+	QuorumMet    bool   `json:"quorum_met,omitempty"`
 }
 
 type ManifestFile struct {
@@ -95,14 +168,56 @@ type JobpackManifest struct {
 	JobID          string         `json:"job_id"`
 	ManifestSHA256 string         `json:"manifest_sha256"`
 	Files          []ManifestFile `json:"files"`
+	Redaction      *RedactionRef  `json:"redaction,omitempty"`
+}
+
+// RedactionRef reports the secret-redaction ruleset applied to a jobpack.
+// Redactions counts the secrets masked at export time.
+type RedactionRef struct {
+	Builtins   []string `json:"builtins"`
+	Patterns   []string `json:"patterns,omitempty"`
+	EnvVars    []string `json:"env_vars,omitempty"`
+	SHA256     string   `json:"sha256"`
+	Redactions int      `json:"redactions"`
 }
 
 type JobRecord struct {
 	Envelope
-	JobID   string         `json:"job_id"`
-	Name    string         `json:"name"`
-	Status  string         `json:"status"`
-	Budgets map[string]any `json:"budgets"`
+	JobID            string            `json:"job_id"`
+	Name             string            `json:"name"`
+	Status           string            `json:"status"`
+	Budgets          map[string]any    `json:"budgets"`
+	Policy           *PolicyRef        `json:"policy,omitempty"`
+	BudgetAmendments []BudgetAmendment `json:"budget_amendments,omitempty"`
+	PriceTable       *PriceTableRef    `json:"price_table,omitempty"`
+}
+
+// PriceTableRef identifies the price table used to compute estimated cost.
+type PriceTableRef struct {
+	Path     string `json:"path"`
+	SHA256   string `json:"sha256"`
+	Version  string `json:"version"`
+	Currency string `json:"currency"`
+}
+
+// BudgetAmendment records an approved raise of a job's budget limits.
+type BudgetAmendment struct {
+	AmendedAt  time.Time           `json:"amended_at"`
+	Reason     string              `json:"reason"`
+	ApprovedBy string              `json:"approved_by"`
+	Changes    []BudgetLimitChange `json:"changes"`
+}
+
+type BudgetLimitChange struct {
+	Dimension string  `json:"dimension"`
+	From      float64 `json:"from"`
+	To        float64 `json:"to"`
+}
+
+// PolicyRef identifies the policy file that governed a run.
+type PolicyRef struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
 }
 
 type EventRecord struct {
@@ -114,10 +229,15 @@ type EventRecord struct {
 	Payload  map[string]any `json:"payload"`
 }
 
+// ArtifactRecord is one captured artifact. SHA256 is the digest of the file
+// on disk; BlobSHA256 names the stored blob, which differs when its contents
+// were Redacted. PathRedacted marks a path masked at export.
 type ArtifactRecord struct {
-	Path     string `json:"path"`
-	SHA256   string `json:"sha256"`
-	Redacted bool   `json:"redacted,omitempty"`
+	Path         string `json:"path"`
+	SHA256       string `json:"sha256"`
+	BlobSHA256   string `json:"blob_sha256,omitempty"`
+	Redacted     bool   `json:"redacted,omitempty"`
+	PathRedacted bool   `json:"path_redacted,omitempty"`
 }
 
 type ArtifactsManifest struct {
@@ -125,6 +245,7 @@ type ArtifactsManifest struct {
 	JobID       string           `json:"job_id"`
 	CaptureMode string           `json:"capture_mode"`
 	Artifacts   []ArtifactRecord `json:"artifacts"`
+	Missing     []string         `json:"missing,omitempty"`
 }
 
 type AcceptanceFailure struct {
@@ -133,12 +254,41 @@ type AcceptanceFailure struct {
 	Artifact string `json:"artifact,omitempty"`
 }
 
+// AcceptanceCheck records one check's outcome, passed or not.
+type AcceptanceCheck struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type,omitempty"`
+	Passed     bool     `json:"passed"`
+	Message    string   `json:"message"`
+	ReasonCode string   `json:"reason_code,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+	Logs       []string `json:"logs,omitempty"`
+	Waived     bool     `json:"waived,omitempty"`
+}
+
+// AcceptanceWaiver records a waiver loaded for the run and whether it had
+// expired.
+type AcceptanceWaiver struct {
+	Check    string    `json:"check"`
+	Artifact string    `json:"artifact,omitempty"`
+	Reason   string    `json:"reason"`
+	Approver string    `json:"approver"`
+	Expires  time.Time `json:"expires"`
+	Expired  bool      `json:"expired"`
+}
+
 type AcceptanceResult struct {
 	Envelope
 	JobID        string              `json:"job_id"`
 	ChecksRun    int                 `json:"checks_run"`
 	ChecksPassed int                 `json:"checks_passed"`
+	Config       map[string]any      `json:"config,omitempty"`
+	Sources      []string            `json:"config_sources,omitempty"`
+	Checks       []AcceptanceCheck   `json:"checks,omitempty"`
 	Failures     []AcceptanceFailure `json:"failures"`
+	Waived       []AcceptanceFailure `json:"waived,omitempty"`
+	WaiversPath  string              `json:"waivers_path,omitempty"`
+	Waivers      []AcceptanceWaiver  `json:"waivers,omitempty"`
 	ReasonCodes  []string            `json:"reason_codes"`
 }
 
@@ -161,13 +311,15 @@ type StatusResponse struct {
 
 type WorkItemPayload struct {
 	Envelope
-	JobID            string   `json:"job_id"`
-	CheckpointID     string   `json:"checkpoint_id"`
-	CheckpointType   string   `json:"checkpoint_type"`
-	RequiredAction   string   `json:"required_action"`
-	ReasonCodes      []string `json:"reason_codes"`
-	ArtifactPointers []string `json:"artifact_pointers,omitempty"`
-	NextCommands     []string `json:"next_commands"`
+	JobID            string     `json:"job_id"`
+	CheckpointID     string     `json:"checkpoint_id"`
+	CheckpointType   string     `json:"checkpoint_type"`
+	RequiredAction   string     `json:"required_action"`
+	Assignee         string     `json:"assignee,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	ReasonCodes      []string   `json:"reason_codes"`
+	ArtifactPointers []string   `json:"artifact_pointers,omitempty"`
+	NextCommands     []string   `json:"next_commands"`
 }
 
 type GitHubSummaryAcceptance struct {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/davidahmann/wrkr/core/accept"
 	"github.com/davidahmann/wrkr/core/approve"
+	"github.com/davidahmann/wrkr/core/budget"
 	"github.com/davidahmann/wrkr/core/dispatch"
 	wrkrerrors "github.com/davidahmann/wrkr/core/errors"
 	"github.com/davidahmann/wrkr/core/fsx"
@@ -20,6 +21,7 @@ import (
 	"github.com/davidahmann/wrkr/core/pack"
 	ghreport "github.com/davidahmann/wrkr/core/report"
 	"github.com/davidahmann/wrkr/core/runner"
+	v1 "github.com/davidahmann/wrkr/core/schema/v1"
 	statusview "github.com/davidahmann/wrkr/core/status"
 	"github.com/davidahmann/wrkr/core/store"
 )
@@ -122,6 +124,12 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleCheckpoints(w, r)
 	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/jobs/") && strings.HasSuffix(r.URL.Path, ":approve"):
 		s.handleApprove(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/jobs/") && strings.HasSuffix(r.URL.Path, ":reject"):
+		s.handleReject(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/jobs/") && strings.HasSuffix(r.URL.Path, ":usage"):
+		s.handleUsage(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/jobs/") && strings.HasSuffix(r.URL.Path, ":raise-budget"):
+		s.handleRaiseBudget(w, r)
 	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/jobs/") && strings.HasSuffix(r.URL.Path, ":export"):
 		s.handleExport(w, r)
 	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/jobs/") && strings.HasSuffix(r.URL.Path, ":verify"):
@@ -152,6 +160,7 @@ func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		JobSpecPath string `json:"jobspec_path"`
 		JobID       string `json:"job_id"`
+		SubmittedBy string `json:"submitted_by"`
 	}
 	if err := decodeJSON(r.Body, &req); err != nil {
 		s.writeError(w, r, err, http.StatusBadRequest)
@@ -209,9 +218,10 @@ func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, err := dispatch.Submit(specPath, dispatch.SubmitOptions{
-		Now:       s.cfg.Now,
-		JobID:     jobID,
-		FromServe: true,
+		Now:         s.cfg.Now,
+		JobID:       jobID,
+		SubmittedBy: approve.ResolveSubmittedBy(req.SubmittedBy),
+		FromServe:   true,
 	})
 	if err != nil {
 		s.writeError(w, r, err, http.StatusBadRequest)
@@ -248,6 +258,7 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, r, err, http.StatusNotFound)
 		return
 	}
+	_, _ = rn.CheckCheckpointInterval(jobID)
 	state, err := rn.Recover(jobID)
 	if err != nil {
 		s.writeError(w, r, err, http.StatusInternalServerError)
@@ -351,6 +362,7 @@ func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
 		CheckpointID string `json:"checkpoint_id"`
 		Reason       string `json:"reason"`
 		ApprovedBy   string `json:"approved_by"`
+		Option       string `json:"option"`
 	}
 	if err := decodeJSON(r.Body, &req); err != nil {
 		s.writeError(w, r, err, http.StatusBadRequest)
@@ -387,7 +399,12 @@ func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, r, err, http.StatusNotFound)
 		return
 	}
-	rec, err := rn.ApproveCheckpoint(jobID, checkpointID, req.Reason, approve.ResolveApprovedBy(req.ApprovedBy))
+	var rec *v1.ApprovalRecord
+	if strings.TrimSpace(req.Option) != "" {
+		rec, err = rn.DecideCheckpoint(jobID, checkpointID, req.Option, req.Reason, approve.ResolveApprovedBy(req.ApprovedBy))
+	} else {
+		rec, err = rn.ApproveCheckpoint(jobID, checkpointID, req.Reason, approve.ResolveApprovedBy(req.ApprovedBy))
+	}
 	if err != nil {
 		s.writeError(w, r, err, http.StatusBadRequest)
 		return
@@ -395,6 +412,185 @@ func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, rec)
 }
 
+func (s *Server) handleReject(w http.ResponseWriter, r *http.Request) {
+	jobID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/jobs/"), ":reject")
+	if strings.Contains(jobID, "..") {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EUnsafeOperation,
+			"unsafe path component",
+			map[string]any{"field": "job_id", "value": jobID},
+		), http.StatusBadRequest)
+		return
+	}
+	if !pathComponentPattern.MatchString(jobID) {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"invalid job_id format",
+			map[string]any{"field": "job_id", "value": jobID},
+		), http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		CheckpointID string `json:"checkpoint_id"`
+		Reason       string `json:"reason"`
+		RejectedBy   string `json:"rejected_by"`
+	}
+	if err := decodeJSON(r.Body, &req); err != nil {
+		s.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	checkpointID := strings.TrimSpace(req.CheckpointID)
+	if strings.Contains(checkpointID, "..") {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EUnsafeOperation,
+			"unsafe path component",
+			map[string]any{"field": "checkpoint_id", "value": checkpointID},
+		), http.StatusBadRequest)
+		return
+	}
+	if !pathComponentPattern.MatchString(checkpointID) {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"invalid checkpoint_id format",
+			map[string]any{"field": "checkpoint_id", "value": checkpointID},
+		), http.StatusBadRequest)
+		return
+	}
+
+	_, st, err := openRunner(s.cfg.Now)
+	if err != nil {
+		s.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if err := ensureJobExists(st, jobID); err != nil {
+		s.writeError(w, r, err, http.StatusNotFound)
+		return
+	}
+	result, err := dispatch.Reject(jobID, dispatch.RejectOptions{
+		Now:          s.cfg.Now,
+		CheckpointID: checkpointID,
+		Reason:       req.Reason,
+		RejectedBy:   approve.ResolveApprovedBy(req.RejectedBy),
+	})
+	if err != nil {
+		s.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	jobID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/jobs/"), ":usage")
+	if strings.Contains(jobID, "..") {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EUnsafeOperation,
+			"unsafe path component",
+			map[string]any{"field": "job_id", "value": jobID},
+		), http.StatusBadRequest)
+		return
+	}
+	if !pathComponentPattern.MatchString(jobID) {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"invalid job_id format",
+			map[string]any{"field": "job_id", "value": jobID},
+		), http.StatusBadRequest)
+		return
+	}
+	var req runner.Usage
+	if err := decodeJSON(r.Body, &req); err != nil {
+		s.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	_, st, err := openRunner(s.cfg.Now)
+	if err != nil {
+		s.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if err := ensureJobExists(st, jobID); err != nil {
+		s.writeError(w, r, err, http.StatusNotFound)
+		return
+	}
+	result, err := dispatch.RecordUsage(jobID, req, dispatch.UsageOptions{Now: s.cfg.Now})
+	if err != nil {
+		status := http.StatusBadRequest
+		if result.JobID != "" {
+			// Usage was recorded but tripped a budget limit.
+			status = http.StatusConflict
+		}
+		s.writeError(w, r, err, status)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleRaiseBudget(w http.ResponseWriter, r *http.Request) {
+	jobID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/jobs/"), ":raise-budget")
+	if strings.Contains(jobID, "..") {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EUnsafeOperation,
+			"unsafe path component",
+			map[string]any{"field": "job_id", "value": jobID},
+		), http.StatusBadRequest)
+		return
+	}
+	if !pathComponentPattern.MatchString(jobID) {
+		s.writeError(w, r, wrkrerrors.New(
+			wrkrerrors.EInvalidInputSchema,
+			"invalid job_id format",
+			map[string]any{"field": "job_id", "value": jobID},
+		), http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		MaxWallTimeSeconds int      `json:"max_wall_time_seconds"`
+		MaxRetries         int      `json:"max_retries"`
+		MaxStepCount       int      `json:"max_step_count"`
+		MaxToolCalls       int      `json:"max_tool_calls"`
+		MaxTokens          *int     `json:"max_tokens"`
+		MaxEstimatedCost   *float64 `json:"max_estimated_cost"`
+		Reason             string   `json:"reason"`
+		ApprovedBy         string   `json:"approved_by"`
+	}
+	if err := decodeJSON(r.Body, &req); err != nil {
+		s.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := approve.ValidateReason(req.Reason); err != nil {
+		s.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	_, st, err := openRunner(s.cfg.Now)
+	if err != nil {
+		s.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if err := ensureJobExists(st, jobID); err != nil {
+		s.writeError(w, r, err, http.StatusNotFound)
+		return
+	}
+	result, err := dispatch.RaiseBudget(jobID, dispatch.RaiseBudgetOptions{
+		Now: s.cfg.Now,
+		Limits: budget.Limits{
+			MaxWallTimeSeconds: req.MaxWallTimeSeconds,
+			MaxRetries:         req.MaxRetries,
+			MaxStepCount:       req.MaxStepCount,
+			MaxToolCalls:       req.MaxToolCalls,
+			MaxTokens:          req.MaxTokens,
+			MaxEstimatedCost:   req.MaxEstimatedCost,
+		},
+		Reason:     req.Reason,
+		ApprovedBy: approve.ResolveApprovedBy(req.ApprovedBy),
+	})
+	if err != nil {
+		s.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
 func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
 	jobID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/jobs/"), ":export")
 	if strings.Contains(jobID, "..") {
@@ -432,6 +432,112 @@ func TestServeApproveAndCheckpointNotFoundPaths(t *testing.T) {
 	}
 }
 
+func TestServeRecordsUsage(t *testing.T) {
+	_, cleanup := setupServeWorkspace(t)
+	t.Cleanup(cleanup)
+
+	now := time.Date(2026, 2, 14, 5, 25, 0, 0, time.UTC)
+	srv := New(Config{
+		Now:             func() time.Time { return now },
+		ProducerVersion: "test",
+		MaxBodyBytes:    1 << 20,
+	})
+	jobID := submitTestJob(t, srv, now, "job_serve_usage")
+
+	for i := 0; i < 2; i++ {
+		rec := makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":usage", `{"model":"gpt-test","tokens_in":10,"tokens_out":5,"estimated_cost":0.25}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("usage failed: %d %s", rec.Code, rec.Body.String())
+		}
+		if i == 1 {
+			payload := decodeJSONBody(t, rec.Body)
+			if payload["tokens_in"] != float64(20) || payload["tokens_out"] != float64(10) || payload["estimated_cost"] != 0.5 {
+				t.Fatalf("unexpected usage totals: %+v", payload)
+			}
+		}
+	}
+
+	rec := makeRequest(t, srv, http.MethodPost, "/v1/jobs/job_missing:usage", `{"tokens_in":1}`)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing job, got %d", rec.Code)
+	}
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":usage", `{"tokens":1}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown field, got %d", rec.Code)
+	}
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":usage", `{"tokens_in":-1}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for negative usage, got %d", rec.Code)
+	}
+}
+
+func TestServeRaisesBudget(t *testing.T) {
+	_, cleanup := setupServeWorkspace(t)
+	t.Cleanup(cleanup)
+
+	now := time.Date(2026, 2, 14, 5, 30, 0, 0, time.UTC)
+	srv := New(Config{
+		Now:             func() time.Time { return now },
+		ProducerVersion: "test",
+		MaxBodyBytes:    1 << 20,
+	})
+	jobID := submitTestJob(t, srv, now, "job_serve_raise")
+
+	rec := makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":raise-budget", `{"max_step_count":40,"reason":"larger refactor","approved_by":"lead"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("raise-budget failed: %d %s", rec.Code, rec.Body.String())
+	}
+	payload := decodeJSONBody(t, rec.Body)
+	amendment, _ := payload["amendment"].(map[string]any)
+	if amendment["approved_by"] != "lead" || amendment["reason"] != "larger refactor" {
+		t.Fatalf("unexpected amendment: %+v", payload)
+	}
+
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":raise-budget", `{"max_step_count":10,"reason":"lower"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 when lowering a limit, got %d", rec.Code)
+	}
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":raise-budget", `{"max_step_count":80}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without reason, got %d", rec.Code)
+	}
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/job_missing:raise-budget", `{"max_step_count":80,"reason":"r"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing job, got %d", rec.Code)
+	}
+}
+
+func TestServeRejectsCheckpoint(t *testing.T) {
+	_, cleanup := setupServeWorkspace(t)
+	t.Cleanup(cleanup)
+
+	now := time.Date(2026, 2, 14, 5, 40, 0, 0, time.UTC)
+	srv := New(Config{
+		Now:             func() time.Time { return now },
+		ProducerVersion: "test",
+		MaxBodyBytes:    1 << 20,
+	})
+	jobID := submitTestJob(t, srv, now, "job_serve_reject")
+	checkpointID := decisionCheckpointID(t, srv, jobID)
+
+	rec := makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":approve", `{"checkpoint_id":"`+checkpointID+`","reason":"r","option":"fast"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for option not offered, got %d", rec.Code)
+	}
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":reject", `{"checkpoint_id":"`+checkpointID+`","reason":"wrong approach","rejected_by":"lead"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reject failed: %d %s", rec.Code, rec.Body.String())
+	}
+	payload := decodeJSONBody(t, rec.Body)
+	if payload["status"] != "canceled" {
+		t.Fatalf("expected canceled job, got %+v", payload)
+	}
+	rec = makeRequest(t, srv, http.MethodPost, "/v1/jobs/"+jobID+":reject", `{"checkpoint_id":"../x","reason":"r"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsafe checkpoint id, got %d", rec.Code)
+	}
+}
+
 func TestListenAndServeConfigValidationAndListenFailure(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/davidahmann/wrkr/core/fsx"
+)
+
+var blobDigestPattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// PutBlob stores data content-addressed under the job's blobs/ directory and
+// returns its SHA-256 digest. Existing blobs are not rewritten.
+func (s *LocalStore) PutBlob(jobID string, data []byte) (string, error) {
+	if err := s.EnsureJob(jobID); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	path, err := s.safeJobPath(jobID, filepath.Join("blobs", digest))
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return digest, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("create blob dir: %w", err)
+	}
+	if err := fsx.AtomicWriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("write blob: %w", err)
+	}
+	return digest, nil
+}
+
+func (s *LocalStore) ReadBlob(jobID, digest string) ([]byte, error) {
+	if !blobDigestPattern.MatchString(digest) {
+		return nil, fmt.Errorf("invalid blob digest %q", digest)
+	}
+	if err := validateJobID(jobID); err != nil {
+		return nil, err
+	}
+	jobRoot, err := os.OpenRoot(s.JobDir(jobID))
+	if err != nil {
+		return nil, fmt.Errorf("open job root: %w", err)
+	}
+	defer func() { _ = jobRoot.Close() }()
+	raw, err := jobRoot.ReadFile(filepath.Join("blobs", digest))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("blob not found: %s", digest)
+		}
+		return nil, fmt.Errorf("read blob: %w", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/davidahmann/wrkr/core/fsx"
+	"github.com/davidahmann/wrkr/core/redact"
+)
+
+const redactionFile = "redaction.json"
+
+// SetRedaction stores the job's redaction config. Events appended afterwards
+// are masked with it in addition to the built-in detectors.
+func (s *LocalStore) SetRedaction(jobID string, cfg redact.Config) error {
+	rules, err := redact.New(cfg)
+	if err != nil {
+		return err
+	}
+	if err := s.EnsureJob(jobID); err != nil {
+		return err
+	}
+	path, err := s.safeJobPath(jobID, redactionFile)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(rules.Config(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal redaction config: %w", err)
+	}
+	return fsx.AtomicWriteFile(path, raw, 0o600)
+}
+
+// Redaction returns the job's redaction ruleset, falling back to the
+// built-in detectors when none was stored.
+func (s *LocalStore) Redaction(jobID string) (*redact.Ruleset, error) {
+	if err := validateJobID(jobID); err != nil {
+		return nil, err
+	}
+	jobRoot, err := os.OpenRoot(s.JobDir(jobID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return redact.Default(), nil
+		}
+		return nil, fmt.Errorf("open job root: %w", err)
+	}
+	defer func() { _ = jobRoot.Close() }()
+	raw, err := jobRoot.ReadFile(redactionFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return redact.Default(), nil
+		}
+		return nil, fmt.Errorf("read redaction config: %w", err)
+	}
+	var cfg redact.Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("decode redaction config: %w", err)
+	}
+	return redact.New(cfg)
+}
@@ -37,6 +37,25 @@ type LocalStore struct {
 var jobIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
 var ErrCASConflict = errors.New("event append conflict")
 
+// ErrEventsRewound reports that the events file is shorter than the offset a
+// caller resumed from, e.g. after it was rewritten; rescan from offset 0.
+var ErrEventsRewound = errors.New("events file shorter than offset")
+
+// verbatimEvents are replayed by Recover as live configuration, so their
+// payloads are stored unmasked; a masked test_command would no longer run.
+var verbatimEvents = map[string]struct{}{
+	"acceptance_spec_set":   {},
+	"approval_rules_set":    {},
+	"checkpoint_policy_set": {},
+	"env_fingerprint_set":   {},
+	"env_override_recorded": {},
+	"policy_loaded":         {},
+	"price_table_loaded":    {},
+	"budget_amended":        {},
+	"budget_pool_joined":    {},
+	"submitter_recorded":    {},
+}
+
 const appendLockStaleAfter = 2 * time.Minute
 const appendLockRetryAttempts = 128
 
@@ -120,6 +139,22 @@ func (s *LocalStore) JobExists(jobID string) (bool, error) {
 	return info.IsDir(), nil
 }
 
+// ListJobs returns the IDs of all jobs in the store, sorted.
+func (s *LocalStore) ListJobs() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, "jobs"))
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && jobIDPattern.MatchString(entry.Name()) {
+			out = append(out, entry.Name())
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
 func (s *LocalStore) AppendEvent(jobID, eventType string, payload any, now time.Time) (Event, error) {
 	return s.appendEvent(jobID, eventType, payload, now, nil)
 }
@@ -196,6 +231,15 @@ func (s *LocalStore) appendEventLocked(jobID, eventType string, payload any, now
 		if err != nil {
 			return Event{}, fmt.Errorf("marshal event payload: %w", err)
 		}
+		if _, ok := verbatimEvents[eventType]; !ok {
+			rules, err := s.Redaction(jobID)
+			if err != nil {
+				return Event{}, err
+			}
+			if buf, _, err = rules.TextJSON(buf); err != nil {
+				return Event{}, err
+			}
+		}
 		raw = buf
 	}
 
@@ -242,34 +286,63 @@ func (s *LocalStore) appendEventLocked(jobID, eventType string, payload any, now
 }
 
 func (s *LocalStore) LoadEvents(jobID string) ([]Event, error) {
-	if err := validateJobID(jobID); err != nil {
+	events, _, err := s.LoadEventsFrom(jobID, 0)
+	if err != nil {
 		return nil, err
 	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	return events, nil
+}
+
+// LoadEventsFrom reads the complete event lines starting at byte offset and
+// returns them in file order with the offset just past the last one, so
+// callers can resume from it after later appends.
+func (s *LocalStore) LoadEventsFrom(jobID string, offset int64) ([]Event, int64, error) {
+	if err := validateJobID(jobID); err != nil {
+		return nil, offset, err
+	}
 	path, err := s.safeJobPath(jobID, "events.jsonl")
 	if err != nil {
-		return nil, err
+		return nil, offset, err
 	}
 	jobDir := s.JobDir(jobID)
 	eventsRel, err := filepath.Rel(jobDir, path)
 	if err != nil || eventsRel == ".." || strings.HasPrefix(eventsRel, ".."+string(os.PathSeparator)) {
-		return nil, fmt.Errorf("events file escapes job directory")
+		return nil, offset, fmt.Errorf("events file escapes job directory")
 	}
 	jobRoot, err := os.OpenRoot(jobDir)
 	if err != nil {
-		return nil, fmt.Errorf("open job root: %w", err)
+		return nil, offset, fmt.Errorf("open job root: %w", err)
 	}
 	defer func() { _ = jobRoot.Close() }()
 	f, err := jobRoot.Open("events.jsonl")
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil
+			if offset > 0 {
+				return nil, offset, ErrEventsRewound
+			}
+			return nil, 0, nil
 		}
-		return nil, fmt.Errorf("open events file: %w", err)
+		return nil, offset, fmt.Errorf("open events file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
+	if offset > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, offset, fmt.Errorf("stat events file: %w", err)
+		}
+		if info.Size() < offset {
+			return nil, offset, ErrEventsRewound
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, offset, fmt.Errorf("seek events file: %w", err)
+		}
+	}
+
 	reader := bufio.NewReader(f)
 	events := make([]Event, 0, 32)
+	next := offset
 
 	for {
 		line, err := reader.ReadBytes('\n')
@@ -281,6 +354,7 @@ func (s *LocalStore) LoadEvents(jobID string) ([]Event, error) {
 			// Ignore trailing partial line (e.g. process crash during append).
 			break
 		}
+		next += int64(len(line))
 
 		line = bytes.TrimSpace(line)
 		if len(line) == 0 {
@@ -292,7 +366,7 @@ func (s *LocalStore) LoadEvents(jobID string) ([]Event, error) {
 
 		var event Event
 		if uErr := json.Unmarshal(line, &event); uErr != nil {
-			return nil, fmt.Errorf("decode event line: %w", uErr)
+			return nil, offset, fmt.Errorf("decode event line: %w", uErr)
 		}
 		events = append(events, event)
 
@@ -301,8 +375,7 @@ func (s *LocalStore) LoadEvents(jobID string) ([]Event, error) {
 		}
 	}
 
-	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
-	return events, nil
+	return events, next, nil
 }
 
 func (s *LocalStore) SaveSnapshot(jobID string, lastSeq int64, state any, now time.Time) error {
@@ -6,8 +6,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/davidahmann/wrkr/core/redact"
 )
 
 func newTestStore(t *testing.T) *LocalStore {
@@ -77,6 +80,34 @@ func TestCrashPartialLineDoesNotCorruptCommittedEvents(t *testing.T) {
 	}
 }
 
+func TestLoadEventsFromResumesAtOffset(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	now := time.Date(2026, 2, 13, 10, 0, 0, 0, time.UTC)
+
+	if _, err := s.AppendEvent("job_offset", "a", nil, now); err != nil {
+		t.Fatalf("append event 1: %v", err)
+	}
+	events, offset, err := s.LoadEventsFrom("job_offset", 0)
+	if err != nil || len(events) != 1 || offset == 0 {
+		t.Fatalf("expected first event and offset, got %+v %d %v", events, offset, err)
+	}
+	if _, err := s.AppendEvent("job_offset", "b", nil, now.Add(time.Second)); err != nil {
+		t.Fatalf("append event 2: %v", err)
+	}
+	events, next, err := s.LoadEventsFrom("job_offset", offset)
+	if err != nil || len(events) != 1 || events[0].Type != "b" || next <= offset {
+		t.Fatalf("expected only the appended event, got %+v %d %v", events, next, err)
+	}
+	if events, _, err := s.LoadEventsFrom("job_offset", next); err != nil || len(events) != 0 {
+		t.Fatalf("expected no new events, got %+v %v", events, err)
+	}
+	if _, _, err := s.LoadEventsFrom("job_offset", next+100); !errors.Is(err, ErrEventsRewound) {
+		t.Fatalf("expected ErrEventsRewound past the end, got %v", err)
+	}
+}
+
 func TestSnapshotRoundTrip(t *testing.T) {
 	t.Parallel()
 
@@ -147,3 +178,73 @@ func TestAppendEventReclaimsStaleLock(t *testing.T) {
 		t.Fatalf("append with stale lock should succeed, got %v", err)
 	}
 }
+
+func TestPutAndReadBlob(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	digest, err := s.PutBlob("job_blob", []byte("hello"))
+	if err != nil {
+		t.Fatalf("put blob: %v", err)
+	}
+	again, err := s.PutBlob("job_blob", []byte("hello"))
+	if err != nil || again != digest {
+		t.Fatalf("expected idempotent blob digest, got %s err=%v", again, err)
+	}
+	raw, err := s.ReadBlob("job_blob", digest)
+	if err != nil || string(raw) != "hello" {
+		t.Fatalf("read blob: %q err=%v", raw, err)
+	}
+	if _, err := s.ReadBlob("job_blob", "../snapshot.json"); err == nil {
+		t.Fatal("expected invalid digest error")
+	}
+	if _, err := s.ReadBlob("job_blob", strings.Repeat("0", 64)); err == nil {
+		t.Fatal("expected missing blob error")
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	for _, jobID := range []string{"job_b", "job_a"} {
+		if err := s.EnsureJob(jobID); err != nil {
+			t.Fatalf("EnsureJob: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(s.Root(), "jobs", "stray.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("write stray file: %v", err)
+	}
+	jobs, err := s.ListJobs()
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if strings.Join(jobs, ",") != "job_a,job_b" {
+		t.Fatalf("unexpected jobs: %v", jobs)
+	}
+}
+
+func TestAppendEventRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	now := time.Date(2026, 2, 13, 10, 0, 0, 0, time.UTC)
+	if err := s.SetRedaction("job_redact", redact.Config{Patterns: []string{`internal-[0-9]+`}}); err != nil {
+		t.Fatalf("SetRedaction: %v", err)
+	}
+	payload := map[string]any{"command": "deploy --token=abc123 --ticket internal-42", "step": 1}
+	if _, err := s.AppendEvent("job_redact", "adapter_step", payload, now); err != nil {
+		t.Fatalf("append event: %v", err)
+	}
+	events, err := s.LoadEvents("job_redact")
+	if err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	got := string(events[0].Payload)
+	if strings.Contains(got, "abc123") || strings.Contains(got, "internal-42") || !strings.Contains(got, redact.Marker) {
+		t.Fatalf("expected secrets to be redacted, got %s", got)
+	}
+	if err := s.SetRedaction("job_redact", redact.Config{Patterns: []string{"("}}); err == nil {
+		t.Fatal("expected invalid redaction pattern error")
+	}
+}
@@ -0,0 +1,17 @@
+// Package textx holds small string helpers shared by the adapters.
+package textx
+
+import "unicode/utf8"
+
+// Truncate returns at most limit bytes of value, backing off to a rune start
+// so the result never ends in a split UTF-8 sequence.
+func Truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
@@ -0,0 +1,20 @@
+package textx
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	value := strings.Repeat("é", 4) // 8 bytes
+	for limit := 0; limit <= len(value); limit++ {
+		got := Truncate(value, limit)
+		if len(got) > limit || !utf8.ValidString(got) {
+			t.Fatalf("Truncate(%d) = %q", limit, got)
+		}
+	}
+	if got := Truncate(value, 5); got != "éé" {
+		t.Fatalf("expected cut before the split rune, got %q", got)
+	}
+}
@@ -11,7 +11,12 @@ required_docs=(
   "docs/contracts/checkpoint_protocol.md"
   "docs/contracts/lease_heartbeat.md"
   "docs/contracts/environment_fingerprint.md"
+  "docs/contracts/sandbox_profile.md"
+  "docs/contracts/policy.md"
+  "docs/contracts/budget_pools.md"
+  "docs/contracts/price_table.md"
   "docs/contracts/jobpack_verify.md"
+  "docs/contracts/redaction.md"
   "docs/contracts/acceptance_contract.md"
   "docs/contracts/failure_taxonomy.md"
   "docs/contracts/ticket_footer_conformance.md"